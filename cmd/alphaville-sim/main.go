@@ -0,0 +1,150 @@
+// Command alphaville-sim runs the simulation without a window, for CI and batch jobs.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DanTulovsky/alphaville/populate"
+	"github.com/DanTulovsky/alphaville/record"
+	"github.com/DanTulovsky/alphaville/world"
+)
+
+var (
+	ticks    = flag.Int("ticks", 1000, "number of ticks to run the simulation for")
+	scenario = flag.String("scenario", "", "JSON scenario file to populate the world from, the built in default if empty")
+	seed     = flag.Int64("seed", 0, "random seed for the world, 0 picks one from the clock")
+	recordTo = flag.String("record", "", "record the run to this file")
+	replay   = flag.String("replay", "", "replay the recording in this file and report where it diverges")
+	load     = flag.String("load", "", "start from the world saved in this file instead of a new one")
+	save     = flag.String("save", "", "save the world to this file at the end of the run (JSON if it ends in .json, binary otherwise)")
+	workers  = flag.Int("workers", 0, "number of goroutines updating objects, GOMAXPROCS if 0; results do not depend on it")
+)
+
+func main() {
+	flag.Parse()
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	if *replay != "" {
+		replayFile(*replay)
+		return
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	s := populate.DefaultScenario()
+	if *scenario != "" {
+		var err error
+		if s, err = populate.ReadScenarioFile(*scenario); err != nil {
+			log.Fatalf("cannot read scenario: %v", err)
+		}
+	}
+
+	var w *world.World
+	if *load != "" {
+		w = loadWorld(*load)
+	} else {
+		// a nil console sends world output to stdout
+		w = s.NewWorld(*seed, world.NewDebugConfig(), nil)
+	}
+	log.Printf("seed: %v", w.Seed())
+	if *workers > 0 {
+		w.SetWorkers(*workers)
+	}
+
+	var rec *record.Recorder
+	if *recordTo != "" {
+		if *load != "" {
+			log.Fatalf("cannot record a loaded world, recordings start from a scenario")
+		}
+		f, err := os.Create(*recordTo)
+		if err != nil {
+			log.Fatalf("cannot create recording: %v", err)
+		}
+		defer f.Close()
+
+		if rec, err = record.NewRecorder(f, w, s); err != nil {
+			log.Fatalf("cannot record: %v", err)
+		}
+	}
+
+	if *load == "" {
+		if err := s.Populate(w); err != nil {
+			log.Fatalf("cannot populate the world: %v", err)
+		}
+	}
+
+	start := time.Now()
+	for i := 0; i < *ticks; i++ {
+		s.Step(w)
+	}
+	elapsed := time.Since(start)
+
+	log.Printf("ran %v ticks in %v (%.0f ticks/s)", *ticks, elapsed, float64(*ticks)/elapsed.Seconds())
+	fmt.Printf("%v\n", w.Stats)
+	if *save != "" {
+		saveWorld(w, *save)
+	}
+	w.End()
+
+	if rec != nil {
+		if err := rec.Close(); err != nil {
+			log.Fatalf("error recording: %v", err)
+		}
+	}
+}
+
+// loadWorld loads the world saved in file
+func loadWorld(file string) *world.World {
+	f, err := os.Open(file)
+	if err != nil {
+		log.Fatalf("cannot open saved world: %v", err)
+	}
+	defer f.Close()
+
+	w, err := world.LoadWorld(f)
+	if err != nil {
+		log.Fatalf("cannot load world: %v", err)
+	}
+	return w
+}
+
+// saveWorld saves w to file
+func saveWorld(w *world.World, file string) {
+	f, err := os.Create(file)
+	if err != nil {
+		log.Fatalf("cannot create save file: %v", err)
+	}
+	defer f.Close()
+
+	if strings.HasSuffix(file, ".json") {
+		err = w.Save(f)
+	} else {
+		err = w.SaveBinary(f)
+	}
+	if err != nil {
+		log.Fatalf("cannot save world: %v", err)
+	}
+	log.Printf("world saved to %v", file)
+}
+
+// replayFile replays the recording in file, exits with an error if it diverges
+func replayFile(file string) {
+	f, err := os.Open(file)
+	if err != nil {
+		log.Fatalf("cannot open recording: %v", err)
+	}
+	defer f.Close()
+
+	if err := record.Replay(f); err != nil {
+		log.Printf("%v", err)
+		os.Exit(1)
+	}
+	log.Printf("replay of %v matches the recording", file)
+}
@@ -1,6 +1,6 @@
 module github.com/DanTulovsky/alphaville
 
-go 1.17
+go 1.18
 
 require (
 	github.com/askft/go-behave v0.0.0-20200603190557-1fef2510d574
@@ -7,7 +7,6 @@ import (
 	"sync"
 
 	"github.com/faiface/pixel/imdraw"
-	"github.com/faiface/pixel/pixelgl"
 
 	"github.com/faiface/pixel"
 	"github.com/google/uuid"
@@ -228,8 +227,8 @@ func EdgesIntersect(l1, l2 Edge) bool {
 // PathFinder is a function that returns the path between start and dest
 type PathFinder func(*Graph, pixel.Vec, pixel.Vec) ([]*Node, int, error)
 
-// DrawPath draws the path
-func DrawPath(win *pixelgl.Window, path []*Node, c color.Color) {
+// DrawPath draws the path on t
+func DrawPath(t pixel.Target, path []*Node, c color.Color) {
 
 	imd := imdraw.New(nil)
 	imd.Color = c
@@ -238,5 +237,5 @@ func DrawPath(win *pixelgl.Window, path []*Node, c color.Color) {
 	}
 	imd.Line(2)
 
-	imd.Draw(win)
+	imd.Draw(t)
 }
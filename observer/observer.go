@@ -7,6 +7,7 @@ import (
 
 // Event describes an interface for events
 type Event interface {
+	Data() []EventData
 	Description() string
 	String() string
 	Time() time.Time // time of event
@@ -12,7 +12,7 @@ import (
 
 func run() {
 
-	rand.Seed(time.Now().UnixNano())
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
 	r := pixel.R(10, 10, 10, 10)
@@ -22,7 +22,7 @@ func run() {
 	log.Println(r2.Intersect(r))
 	log.Println(r2.Contains(r.Center()))
 
-	log.Println(time.Second * time.Duration(utils.RandomInt(5, 10)))
+	log.Println(time.Second * time.Duration(utils.RandomInt(rng, 5, 10)))
 
 	r3 := pixel.R(0, 0, 10, 20)
 	r4 := pixel.R(2, 2, 4, 4)
@@ -5,236 +5,160 @@ import (
 	"image/color"
 	"log"
 	"math"
+	"math/rand"
 	"time"
 
 	"github.com/faiface/pixel"
 	colorful "github.com/lucasb-eyer/go-colorful"
 	"github.com/DanTulovsky/alphaville/utils"
 	"github.com/DanTulovsky/alphaville/world"
-	"golang.org/x/image/colornames"
 )
 
-// RandomEllipses populates the world with N random objects
-func RandomEllipses(w *world.World, n int) {
-
-	var minRadius, maxRadius, minMass, maxMass, minSpeed, maxSpeed float64
-
-	minRadius = w.MinObjectSide / 2
-	maxRadius = 60
-	minMass, maxMass = 1, 10
-	minSpeed, maxSpeed = 0.1, w.MaxObjectSpeed
-
-	for i := 0; i < n; i++ {
-
-		a := utils.RandomFloat64(minRadius, maxRadius+1)
-		b := utils.RandomFloat64(minRadius, maxRadius+1)
-
-		o := world.NewEllipseObject(
-			fmt.Sprintf("%v", i),
-			colorful.FastWarmColor(),
-			utils.RandomFloat64(minSpeed, maxSpeed),  // speed
-			utils.RandomFloat64(minMass, maxMass)/10, // mass
-			a,   // x radius
-			b,   // y radius
-			nil, //  behavior set later
-		)
-
-		behavior := world.NewWondererBehavior(o, w)
-		o.SetBehavior(behavior)
-
-		if err := w.AddObject(o); err != nil {
-			log.Fatalf("cannot add object: %v", err)
-		}
-	}
+// randomWarmColor returns a random dark, "warm" color, drawn from r
+// Same as colorful.FastWarmColor, which uses the global random source.
+func randomWarmColor(r *rand.Rand) colorful.Color {
+	return colorful.Hsv(
+		r.Float64()*360.0,
+		0.5+r.Float64()*0.3,
+		0.3+r.Float64()*0.3)
 }
 
-// RandomCircles populates the world with N random objects
-func RandomCircles(w *world.World, n int) {
-
-	var minRadius, maxRadius, minMass, maxMass, minSpeed, maxSpeed float64
-
-	minRadius = w.MinObjectSide / 2
-	maxRadius = 60
-	minMass, maxMass = 1, 10
-	minSpeed, maxSpeed = 0.1, w.MaxObjectSpeed
-
+// randomWarmPalette returns n evenly spread "warm" colors, drawn from r
+// Same as colorful.FastWarmPalette, which uses the global random source.
+func randomWarmPalette(r *rand.Rand, n int) []colorful.Color {
+	colors := make([]colorful.Color, n)
 	for i := 0; i < n; i++ {
-		radius := utils.RandomFloat64(minRadius, maxRadius+1)
-
-		o := world.NewCircleObject(
-			fmt.Sprintf("%v", i),
-			colorful.FastWarmColor(),
-			utils.RandomFloat64(minSpeed, maxSpeed),  // speed
-			utils.RandomFloat64(minMass, maxMass)/10, // mass
-			radius, // radius
-			nil,    // behavior set later
-		)
-		behavior := world.NewWondererBehavior(o, w)
-		o.SetBehavior(behavior)
-
-		if err := w.AddObject(o); err != nil {
-			log.Fatalf("cannot add object: %v", err)
-		}
+		colors[i] = colorful.Hsv(float64(i)*(360.0/float64(n)), 0.55+r.Float64()*0.2, 0.35+r.Float64()*0.2)
 	}
+	return colors
 }
 
-// RandomRectangles populates the world with N random rectangular objects
-func RandomRectangles(w *world.World, n int) {
-
-	var minWidth, maxWidth, minHeight, maxHeight, minMass, maxMass, minSpeed, maxSpeed float64
+// AddPopulation adds the objects of population p to the world
+func AddPopulation(w *world.World, p Population) error {
+	r := w.NewRand()
 
-	minWidth, maxWidth = w.MinObjectSide, 81
-	minHeight, maxHeight = w.MinObjectSide, 81
-	minMass, maxMass = 6, 10
-	minSpeed, maxSpeed = 0.1, w.MaxObjectSpeed
+	for i := 0; i < p.Count; i++ {
+		name := p.objectName(i)
 
-	for i := 0; i < n; i++ {
+		var c color.Color = randomWarmColor(r)
+		if p.Color != "" {
+			var err error
+			if c, err = parseColor(p.Color); err != nil {
+				return err
+			}
+		}
 
-		width := utils.RandomFloat64(minWidth, maxWidth+1)
-		height := utils.RandomFloat64(minHeight, maxHeight)
+		speed := p.Speed.pick(r)
+		mass := p.Mass.pick(r)
+
+		var behavior world.Behavior
+		switch p.Behavior {
+		case "target_seeker":
+			behavior = world.NewTargetSeekerBehavior(&world.DijkstraPathFinder{})
+		case "exit_seeker":
+			behavior = world.NewExitSeekerBehavior(&world.DijkstraPathFinder{})
+		case "manual":
+			behavior = world.NewManualBehavior()
+		case "default", "wonderer":
+			behavior = nil // default, wonderers need the object, set below
+		default:
+			return fmt.Errorf("population [%v]: unknown behavior %q", p.Name, p.Behavior)
+		}
 
-		o := world.NewRectObject(
-			fmt.Sprintf("%v", i),
-			colorful.FastWarmColor(),
-			utils.RandomFloat64(minSpeed, maxSpeed),  // speed
-			utils.RandomFloat64(minMass, maxMass)/10, // mass
-			width,  // width
-			height, // height
-			nil,    // behavior set later
-		)
+		var o world.Object
+		switch p.Shape {
+		case "rect":
+			o = world.NewRectObject(name, c, speed, mass, p.Width.pick(r), p.Height.pick(r), behavior)
+		case "circle":
+			o = world.NewCircleObject(name, c, speed, mass, p.Radius.pick(r), behavior)
+		case "ellipse":
+			a := p.Radius.pick(r)
+			b := p.Radius.pick(r)
+			o = world.NewEllipseObject(name, c, speed, mass, a, b, behavior)
+		default:
+			return fmt.Errorf("population [%v]: unknown shape %q", p.Name, p.Shape)
+		}
 
-		behavior := world.NewWondererBehavior(o, w)
-		o.SetBehavior(behavior)
+		if p.Behavior == "wonderer" {
+			o.(interface{ SetBehavior(world.Behavior) }).SetBehavior(world.NewWondererBehavior(o, w))
+		}
+		o.SetLifetime(time.Duration(p.Lifetime.pick(r) * float64(time.Second)))
+		if p.Restitution != nil {
+			o.SetRestitution(p.Restitution.pick(r))
+		}
+		if p.GravityScale != nil {
+			o.SetGravityScale(p.GravityScale.pick(r))
+		}
 
 		if err := w.AddObject(o); err != nil {
-			log.Fatalf("cannot add object: %v", err)
+			return fmt.Errorf("population [%v]: cannot add object: %v", p.Name, err)
+		}
+		if p.Behavior == "manual" {
+			w.ManualControl = o
 		}
 	}
+	return nil
 }
 
-// AddTargetSeeker adds an object that seeks a target
-func AddTargetSeeker(w *world.World, name string, speed float64, c color.Color) {
-
-	var minMass, maxMass float64
-
-	// minWidth, maxWidth = w.MinObjectSide+20, w.MinObjectSide+21
-	// minHeight, maxHeight = w.MinObjectSide+20, w.MinObjectSide+21
-	minMass, maxMass = 6, 10
-	// minSpeed, maxSpeed = 2, w.MaxObjectSpeed
-
-	width := w.MinObjectSide * 2
-	height := w.MinObjectSide * 2
-
-	if c == nil {
-		c = colorful.FastWarmColor()
-	}
-
-	// path finder algorithm
-	finder := &world.DijkstraPathFinder{}
-
-	o := world.NewRectObject(
-		fmt.Sprintf("ts-%v", name),
-		c,
-		speed,
-		utils.RandomFloat64(minMass, maxMass)/10, // mass
-		width,  // width
-		height, // height
-		world.NewTargetSeekerBehavior(finder),
-	)
-
-	if err := w.AddObject(o); err != nil {
-		log.Fatalf("cannot add object: %v", err)
-	}
-}
-
-// AddManualObject adds a manually controlled object to the world
-func AddManualObject(w *world.World, width, height float64) {
+// AddGates adds gates to the world, cool downs are picked at random from their range
+func AddGates(w *world.World, gates []GateConfig) error {
+	r := w.NewRand()
 
-	behavior := world.NewManualBehavior()
+	for _, g := range gates {
+		var filters []world.GateFilter
+		for _, name := range g.Filters {
+			f, ok := world.GateFilters[name]
+			if !ok {
+				return fmt.Errorf("gate [%v]: unknown filter %q", g.Name, name)
+			}
+			filters = append(filters, f)
+		}
 
-	o := world.NewRectObject(
-		"manual",
-		colornames.Red,
-		3,      // speed
-		1,      // mass
-		width,  // width
-		height, // height
-		behavior,
-	)
+		status := world.GateStatus(world.GateOpen)
+		if g.Closed {
+			status = world.GateClosed
+		}
+		coolDown := time.Duration(g.CoolDown.pick(r) * float64(time.Second))
 
-	if err := w.AddObject(o); err != nil {
-		log.Fatalf("cannot add object: %v", err)
+		gate := world.NewGate(g.Name, g.Location, status, coolDown, g.Radius, filters...)
+		if err := w.AddGate(gate); err != nil {
+			return fmt.Errorf("error adding gate: %v", err)
+		}
 	}
-	w.ManualControl = o
+	return nil
 }
 
-// AddGates adds gates to the world
-func AddGates(w *world.World) {
+// AddSinks adds sinks to the world, cool downs are picked at random from their range
+func AddSinks(w *world.World, sinks []SinkConfig) error {
+	r := w.NewRand()
 
-	var filterManualOnly world.GateFilter = func(o world.Object) bool {
-		switch o.Behavior().(type) {
-		case *world.ManualBehavior:
-			return true
+	for _, s := range sinks {
+		var filters []world.GateFilter
+		for _, name := range s.Filters {
+			f, ok := world.GateFilters[name]
+			if !ok {
+				return fmt.Errorf("sink [%v]: unknown filter %q", s.Name, name)
+			}
+			filters = append(filters, f)
 		}
-		return false
-	}
-
-	// var filterTargetSeekerOnly world.GateFilter = func(o world.Object) bool {
-	// 	switch o.Behavior().(type) {
-	// 	case *world.TargetSeekerBehavior:
-	// 		return true
-	// 	}
-	// 	return false
-	// }
-
-	type gate struct {
-		name     string
-		location pixel.Vec
-		status   world.GateStatus
-		coolDown time.Duration
-		radius   float64
-		filters  []world.GateFilter
-	}
-
-	// add spawn gate
-	gates := []gate{
-		{
-			name:     "One",
-			location: pixel.V(600, 600),
-			status:   world.GateOpen,
-			coolDown: time.Second * time.Duration(utils.RandomInt(2, 10)),
-			radius:   20,
-			// filters:  []world.GateFilter{filterTargetSeekerOnly},
-		},
-		{
-			name:     "Two",
-			location: pixel.V(200, 600),
-			status:   world.GateOpen,
-			coolDown: time.Second * time.Duration(utils.RandomInt(2, 10)),
-			radius:   25,
-			filters:  []world.GateFilter{world.DefaultGateFilter},
-		},
-		{
-			name:     "manual only",
-			location: pixel.V(400, 600),
-			status:   world.GateOpen,
-			coolDown: time.Second * time.Duration(utils.RandomInt(2, 10)),
-			radius:   25,
-			filters:  []world.GateFilter{filterManualOnly},
-		},
-	}
 
-	for _, g := range gates {
-		gate := world.NewGate(g.name, g.location, g.status, g.coolDown, g.radius, g.filters...)
+		status := world.GateStatus(world.GateOpen)
+		if s.Closed {
+			status = world.GateClosed
+		}
+		coolDown := time.Duration(s.CoolDown.pick(r) * float64(time.Second))
 
-		if err := w.AddGate(gate); err != nil {
-			log.Fatalf("error adding gate: %v", err)
+		sink := world.NewSink(s.Name, s.Location, status, coolDown, s.Radius, s.Capacity, filters...)
+		if err := w.AddSink(sink); err != nil {
+			return fmt.Errorf("error adding sink: %v", err)
 		}
 	}
+	return nil
 }
 
 // AddTarget adds targets to the world
 func AddTarget(w *world.World, radius float64, maxTargets int) error {
+	r := w.NewRand()
 
 	if len(w.Targets()) >= maxTargets {
 		return fmt.Errorf("already too many targets %v of %v", len(w.Targets()), maxTargets)
@@ -247,8 +171,8 @@ func AddTarget(w *world.World, radius float64, maxTargets int) error {
 	for !valid {
 		l := pixel.V(
 			// TODO fix these!
-			utils.RandomFloat64(55, w.X-65),
-			utils.RandomFloat64(w.Ground.Phys().Location().Max.Y+65, w.Y-65))
+			utils.RandomFloat64(r, 55, w.X-65),
+			utils.RandomFloat64(r, w.Ground.Phys().Location().Max.Y+65, w.Y-65))
 
 		t = world.NewSimpleTarget("one", l, radius, "desc")
 		valid = true
@@ -273,27 +197,74 @@ func AddTarget(w *world.World, radius float64, maxTargets int) error {
 }
 
 // AddFixture adds one specific fixture to the world
-func AddFixture(w *world.World) error {
+func AddFixture(w *world.World, fc FixtureConfig) error {
+	r := w.NewRand()
+
+	var c color.Color = randomWarmColor(r)
+	if fc.Color != "" {
+		var err error
+		if c, err = parseColor(fc.Color); err != nil {
+			return err
+		}
+	}
 
-	var width float64 = 144
-	var height float64 = 64
-	f := world.NewFixture("one", colorful.FastWarmColor(), width, height)
-	f.Place(pixel.V(761, 171))
+	f := world.NewFixture(fc.Name, c, fc.Width, fc.Height)
+	f.Place(fc.Location)
+	if fc.Motion != nil {
+		m, err := fc.Motion.motion()
+		if err != nil {
+			return err
+		}
+		if err := f.SetMotion(m); err != nil {
+			return err
+		}
+	}
 	if err := w.AddFixture(f); err != nil {
 		return err
 	}
 	return nil
 }
 
+// AddZone adds one force field zone to the world
+func AddZone(w *world.World, zc ZoneConfig) error {
+	z, err := zc.zone()
+	if err != nil {
+		return err
+	}
+	return w.AddZone(z)
+}
+
+// AddJoint adds one joint to the world, between objects or fixtures already in it, found by name
+func AddJoint(w *world.World, jc JointConfig) error {
+	named := func(name string) world.Object {
+		for _, o := range append(w.Fixtures(), w.Objects...) {
+			if o.Name() == name {
+				return o
+			}
+		}
+		return nil
+	}
+	a, b := named(jc.A), named(jc.B)
+	if a == nil || b == nil {
+		return fmt.Errorf("joint [%v]: joins objects not in the world, %q and %q", jc.Name, jc.A, jc.B)
+	}
+	j, err := jc.joint(a, b)
+	if err != nil {
+		return err
+	}
+	return w.AddJoint(j)
+}
+
 // AddFixtures add fixtures to the world.
 func AddFixtures(w *world.World, numFixtures int) {
+	r := w.NewRand()
 
 	var minWidth float64 = w.MinObjectSide
 	var maxWidth float64 = w.MinObjectSide + 30
 	var minHeight float64 = w.MinObjectSide
 	var maxHeight float64 = w.Y - 100
 
-	fcolors := colorful.FastWarmPalette(numFixtures)
+	fcolors := randomWarmPalette(r, numFixtures)
 
 	for x := 0; x < numFixtures; x++ {
 
@@ -304,10 +275,10 @@ func AddFixtures(w *world.World, numFixtures int) {
 		// should be fixed  by switching to trapezoid map instead
 		for intersect {
 			intersect = false
-			width := math.Floor(utils.RandomFloat64(minWidth, maxWidth))
-			height := math.Floor(utils.RandomFloat64(minHeight, maxHeight))
-			lX := utils.RandomFloat64(width, w.X-width)
-			lY := utils.RandomFloat64(height, w.Y-height)
+			width := math.Floor(utils.RandomFloat64(r, minWidth, maxWidth))
+			height := math.Floor(utils.RandomFloat64(r, minHeight, maxHeight))
+			lX := utils.RandomFloat64(r, width, w.X-width)
+			lY := utils.RandomFloat64(r, height, w.Y-height)
 
 			f = world.NewFixture(fmt.Sprintf("block-%v", x), fcolors[x], width, height)
 			f.Place(pixel.V(lX, lY))
@@ -0,0 +1,567 @@
+// Package populate builds worlds from scenarios: JSON files with the size and physics of a world and
+// what's in it (ground, edges, gates, sinks, fixtures, zones, joints, populations of objects and how
+// targets spawn). default.json is built in, the others in scenarios/ each show something off:
+//
+//   - seekers.json: target seekers finding their way around fixtures
+//   - evacuation.json: exit seekers heading for sinks
+//   - weather.json: wind, water and wells, drawn with "debug world zones draw true"
+//   - platforms.json: objects standing on fixtures and each other
+//   - moving.json: moving fixtures
+//   - joints.json: ropes, springs and welds, drawn with "debug world joints draw true"
+//   - torus.json: wrapping edges
+//
+// Only JSON for now, YAML would need a new dependency.
+package populate
+
+import (
+	"bytes"
+	_ "embed" // the default scenario
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/jroimartin/gocui"
+	"golang.org/x/image/colornames"
+
+	"github.com/DanTulovsky/alphaville/utils"
+	"github.com/DanTulovsky/alphaville/world"
+)
+
+// Scenario describes the initial state of a world: its size, physics and what's in it.
+// Scenarios are read from JSON files, see scenarios/default.json.
+type Scenario struct {
+	Width, Height  float64
+	Gravity        float64
+	MaxObjectSpeed float64
+
+	// force based physics, world defaults if not set
+	Drag            *float64
+	Friction        *float64
+	MaxAcceleration *float64
+	AngularDrag     *float64
+
+	// ticks an idle object waits before it falls asleep, world default if not set, never if 0
+	SleepAfter *int
+
+	Ground      GroundConfig
+	Boundaries  BoundaryConfig
+	Gates       []GateConfig
+	Sinks       []SinkConfig
+	Fixtures    []FixtureConfig
+	Zones       []ZoneConfig
+	Joints      []JointConfig
+	Populations []Population
+	Targets     TargetConfig
+
+	RandomFixtures int // number of fixtures placed at random
+}
+
+// GroundConfig describes the ground
+type GroundConfig struct {
+	Height float64
+	Color  string // color name (see colornames) or #rrggbb, white if empty
+}
+
+// BoundaryConfig describes what happens at the edges of the world, see world.Boundaries.
+// Edges not given are like All, solid if that is not given either.
+type BoundaryConfig struct {
+	All                      *EdgeConfig
+	Left, Right, Bottom, Top *EdgeConfig
+}
+
+// EdgeConfig describes one edge of the world
+type EdgeConfig struct {
+	Mode        string  // solid, bounce, wrap or open
+	Restitution float64 // bounce, 0 to 1
+}
+
+// boundaries returns the world boundaries described by bc
+func (bc BoundaryConfig) boundaries() (world.Boundaries, error) {
+	var all world.Boundary
+	if bc.All != nil {
+		all = world.Boundary{Mode: world.BoundaryMode(bc.All.Mode), Restitution: bc.All.Restitution}
+	}
+	edge := func(ec *EdgeConfig) world.Boundary {
+		if ec == nil {
+			return all
+		}
+		return world.Boundary{Mode: world.BoundaryMode(ec.Mode), Restitution: ec.Restitution}
+	}
+	bs := world.Boundaries{Left: edge(bc.Left), Right: edge(bc.Right), Bottom: edge(bc.Bottom), Top: edge(bc.Top)}
+	return bs, bs.Validate()
+}
+
+// GateConfig describes a gate
+type GateConfig struct {
+	Name     string
+	Location pixel.Vec
+	Radius   float64
+	Closed   bool
+	CoolDown Range    // seconds between spawns, picked at random
+	Filters  []string // names of world.GateFilters
+}
+
+// SinkConfig describes a sink, where objects leave the world
+type SinkConfig struct {
+	Name     string
+	Location pixel.Vec
+	Radius   float64
+	Closed   bool
+	CoolDown Range    // seconds between exits, picked at random
+	Capacity int      // number of objects the sink takes, unlimited if 0
+	Filters  []string // names of world.GateFilters
+}
+
+// FixtureConfig describes a fixture
+type FixtureConfig struct {
+	Name          string
+	Location      pixel.Vec // bottom left corner
+	Width, Height float64
+	Color         string        // random if empty
+	Motion        *MotionConfig // kinematic fixtures only, see world.Motion
+}
+
+// MotionConfig describes how a kinematic fixture moves, see world.Motion
+type MotionConfig struct {
+	Kind      string      // linear, waypoints or rotate
+	To        pixel.Vec   // linear, offset from Location to go back and forth to
+	Waypoints []pixel.Vec // waypoints, offsets from Location to go through, and back to the start
+	Pivot     pixel.Vec   // rotate, the point to turn around
+	Speed     float64     // pixels per tick, radians per tick (clockwise if negative) for rotate
+}
+
+// motion returns the world motion described by mc
+func (mc MotionConfig) motion() (*world.Motion, error) {
+	var m *world.Motion
+	switch world.MotionKind(mc.Kind) {
+	case world.MotionLinear:
+		m = world.NewLinearMotion(mc.To, mc.Speed)
+	case world.MotionWaypoints:
+		m = world.NewWaypointMotion(mc.Waypoints, mc.Speed)
+	case world.MotionRotate:
+		m = world.NewRotateMotion(mc.Pivot, mc.Speed)
+	default:
+		return nil, fmt.Errorf("unknown motion %q, want linear, waypoints or rotate", mc.Kind)
+	}
+	return m, m.Validate()
+}
+
+// ZoneConfig describes a force field zone, see world.Zone.
+// Wind and water zones cover Rect, or Polygon if it is set; wells cover a circle.
+type ZoneConfig struct {
+	Name    string
+	Kind    string // wind, water or well
+	Rect    pixel.Rect
+	Polygon []pixel.Vec
+	Color   string // by kind if empty
+
+	Force pixel.Vec // wind
+
+	Density float64 // water, buoyancy per square pixel under water
+	Drag    float64 // water, 0 to 1
+
+	Center   pixel.Vec // well
+	Radius   float64   // well
+	Strength float64   // well, negative pushes objects away
+}
+
+// zone returns the world zone described by zc
+func (zc ZoneConfig) zone() (*world.Zone, error) {
+	polygon := zc.Polygon
+	if len(polygon) == 0 {
+		polygon = world.RectPolygon(zc.Rect)
+	}
+
+	var z *world.Zone
+	switch world.ZoneKind(zc.Kind) {
+	case world.ZoneWind:
+		z = world.NewWindZone(zc.Name, polygon, zc.Force)
+	case world.ZoneWater:
+		z = world.NewWaterZone(zc.Name, polygon, zc.Density, zc.Drag)
+	case world.ZoneWell:
+		z = world.NewWellZone(zc.Name, zc.Center, zc.Radius, zc.Strength)
+	default:
+		return nil, fmt.Errorf("zone [%v]: unknown kind %q, want wind, water or well", zc.Name, zc.Kind)
+	}
+	if zc.Color != "" {
+		c, err := parseColor(zc.Color)
+		if err != nil {
+			return nil, err
+		}
+		z.Color = c
+	}
+	return z, z.Validate()
+}
+
+// JointConfig describes a joint between two objects, or an object and a fixture, see world.Joint
+type JointConfig struct {
+	Name       string
+	Kind       string  // distance, spring or weld
+	A, B       string  // names of the objects (or fixtures) joined
+	Length     float64 // distance and spring, as far apart as they are when both are spawned if 0
+	Stiffness  float64 // spring
+	Damping    float64 // spring
+	BreakForce float64 // never breaks if 0
+	Color      string  // by kind if empty
+}
+
+// joint returns the world joint described by jc, between a and b
+func (jc JointConfig) joint(a, b world.Object) (*world.Joint, error) {
+	var j *world.Joint
+	switch world.JointKind(jc.Kind) {
+	case world.JointDistance:
+		j = world.NewDistanceJoint(jc.Name, a, b, jc.Length)
+	case world.JointSpring:
+		j = world.NewSpringJoint(jc.Name, a, b, jc.Length, jc.Stiffness, jc.Damping)
+	case world.JointWeld:
+		j = world.NewWeldJoint(jc.Name, a, b)
+	default:
+		return nil, fmt.Errorf("joint [%v]: unknown kind %q, want distance, spring or weld", jc.Name, jc.Kind)
+	}
+	j.BreakForce = jc.BreakForce
+	if jc.Color != "" {
+		c, err := parseColor(jc.Color)
+		if err != nil {
+			return nil, err
+		}
+		j.Color = c
+	}
+	return j, j.Validate()
+}
+
+// Population describes a group of similar objects, their properties are picked at random from
+// the given ranges.
+// Objects are named <Name>-<i>, just <i> if the population has no name, or just <Name> if there is
+// only one of them.
+type Population struct {
+	Name     string
+	Count    int
+	Shape    string // rect, circle or ellipse
+	Behavior string // wonderer, target_seeker, exit_seeker, manual or default
+	Color    string // random if empty
+
+	Speed Range
+	Mass  Range
+
+	Width, Height Range // rect
+	Radius        Range // circle, ellipse (x and y radius are picked separately)
+
+	Lifetime     Range  // seconds each object stays in the world once spawned, forever if 0
+	Restitution  *Range // how bouncy the objects are, 0 to 1, world.DefaultRestitution if nil
+	GravityScale *Range // how strongly gravity pulls the objects, 1 if nil
+}
+
+// objectName returns the name of the i-th object of the population
+func (p Population) objectName(i int) string {
+	switch {
+	case p.Name != "" && p.Count == 1:
+		return p.Name
+	case p.Name != "":
+		return fmt.Sprintf("%v-%v", p.Name, i)
+	}
+	return fmt.Sprintf("%v", i)
+}
+
+// TargetConfig is the target spawn policy
+type TargetConfig struct {
+	Max      int     // maximum number of targets in the world at once
+	Radius   float64 // size of each target
+	Interval int64   // add a target (if there are less than Max) every Interval ticks, every tick if 0
+}
+
+// Range is a range of values, [Min, Max)
+type Range struct {
+	Min, Max float64
+}
+
+// pick returns a random value in the range
+func (rg Range) pick(r *rand.Rand) float64 {
+	if rg.Max <= rg.Min {
+		return rg.Min
+	}
+	return utils.RandomFloat64(r, rg.Min, rg.Max)
+}
+
+//go:embed scenarios/default.json
+var defaultScenario []byte
+
+// DefaultScenario returns the scenario the game starts with
+func DefaultScenario() *Scenario {
+	s, err := ReadScenario(bytes.NewReader(defaultScenario))
+	if err != nil {
+		log.Fatalf("invalid default scenario: %v", err)
+	}
+	return s
+}
+
+// ReadScenario reads a JSON scenario from in
+func ReadScenario(in io.Reader) (*Scenario, error) {
+	dec := json.NewDecoder(in)
+	dec.DisallowUnknownFields() // catch typos
+
+	s := &Scenario{}
+	if err := dec.Decode(s); err != nil {
+		return nil, fmt.Errorf("error reading scenario: %v", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ReadScenarioFile reads a JSON scenario from file
+func ReadScenarioFile(file string) (*Scenario, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s, err := ReadScenario(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", file, err)
+	}
+	return s, nil
+}
+
+// Validate returns an error if the scenario is not valid
+func (s *Scenario) Validate() error {
+	if s.Width <= 0 || s.Height <= 0 {
+		return fmt.Errorf("invalid world size [%v, %v]", s.Width, s.Height)
+	}
+	if s.Ground.Height < 0 || s.Ground.Height >= s.Height {
+		return fmt.Errorf("invalid ground height %v", s.Ground.Height)
+	}
+	if s.Drag != nil && (*s.Drag < 0 || *s.Drag >= 1) {
+		return fmt.Errorf("invalid drag %v, want [0, 1)", *s.Drag)
+	}
+	if s.Friction != nil && *s.Friction < 0 {
+		return fmt.Errorf("invalid friction %v", *s.Friction)
+	}
+	if s.MaxAcceleration != nil && *s.MaxAcceleration <= 0 {
+		return fmt.Errorf("invalid max acceleration %v", *s.MaxAcceleration)
+	}
+	if s.AngularDrag != nil && (*s.AngularDrag < 0 || *s.AngularDrag >= 1) {
+		return fmt.Errorf("invalid angular drag %v, want [0, 1)", *s.AngularDrag)
+	}
+	if s.SleepAfter != nil && *s.SleepAfter < 0 {
+		return fmt.Errorf("invalid sleep after %v", *s.SleepAfter)
+	}
+	if _, err := s.Boundaries.boundaries(); err != nil {
+		return err
+	}
+
+	colors := []string{s.Ground.Color}
+	manual := 0
+
+	for _, p := range s.Populations {
+		switch p.Shape {
+		case "rect", "circle", "ellipse":
+		default:
+			return fmt.Errorf("population [%v]: unknown shape %q, want rect, circle or ellipse", p.Name, p.Shape)
+		}
+		switch p.Behavior {
+		case "wonderer", "target_seeker", "exit_seeker", "default":
+		case "manual":
+			manual += p.Count
+		default:
+			return fmt.Errorf("population [%v]: unknown behavior %q, want wonderer, target_seeker, exit_seeker, manual or default", p.Name, p.Behavior)
+		}
+		if p.Count < 0 {
+			return fmt.Errorf("population [%v]: negative count", p.Name)
+		}
+		for _, rg := range []Range{p.Speed, p.Mass, p.Width, p.Height, p.Radius, p.Lifetime} {
+			if rg.Max < rg.Min {
+				return fmt.Errorf("population [%v]: invalid range %+v", p.Name, rg)
+			}
+		}
+		if p.Lifetime.Min < 0 {
+			return fmt.Errorf("population [%v]: negative lifetime", p.Name)
+		}
+		if rg := p.Restitution; rg != nil && (rg.Min < 0 || rg.Max > 1 || rg.Max < rg.Min) {
+			return fmt.Errorf("population [%v]: restitution %+v not in [0, 1]", p.Name, *rg)
+		}
+		if rg := p.GravityScale; rg != nil && (rg.Min < 0 || rg.Max < rg.Min) {
+			return fmt.Errorf("population [%v]: invalid gravity scale %+v", p.Name, *rg)
+		}
+		colors = append(colors, p.Color)
+	}
+	if manual > 1 {
+		return fmt.Errorf("only one manually controlled object allowed, got %v", manual)
+	}
+
+	for _, g := range s.Gates {
+		for _, f := range g.Filters {
+			if _, ok := world.GateFilters[f]; !ok {
+				return fmt.Errorf("gate [%v]: unknown filter %q", g.Name, f)
+			}
+		}
+		if g.CoolDown.Max < g.CoolDown.Min {
+			return fmt.Errorf("gate [%v]: invalid cool down %+v", g.Name, g.CoolDown)
+		}
+	}
+
+	for _, sink := range s.Sinks {
+		for _, f := range sink.Filters {
+			if _, ok := world.GateFilters[f]; !ok {
+				return fmt.Errorf("sink [%v]: unknown filter %q", sink.Name, f)
+			}
+		}
+		if sink.CoolDown.Max < sink.CoolDown.Min {
+			return fmt.Errorf("sink [%v]: invalid cool down %+v", sink.Name, sink.CoolDown)
+		}
+		if sink.Capacity < 0 {
+			return fmt.Errorf("sink [%v]: negative capacity", sink.Name)
+		}
+	}
+
+	for _, f := range s.Fixtures {
+		colors = append(colors, f.Color)
+		if f.Motion != nil {
+			if _, err := f.Motion.motion(); err != nil {
+				return fmt.Errorf("fixture [%v]: %v", f.Name, err)
+			}
+		}
+	}
+
+	for _, zc := range s.Zones {
+		if _, err := zc.zone(); err != nil {
+			return err
+		}
+	}
+
+	// stand ins for the objects and fixtures joints join, by name
+	joinable := make(map[string]world.Object)
+	for _, p := range s.Populations {
+		for i := 0; i < p.Count; i++ {
+			name := p.objectName(i)
+			joinable[name] = world.NewRectObject(name, colornames.White, 0, 1, 1, 1, nil)
+		}
+	}
+	for _, f := range s.Fixtures {
+		joinable[f.Name] = world.NewFixture(f.Name, colornames.White, 1, 1)
+	}
+	for _, jc := range s.Joints {
+		for _, name := range []string{jc.A, jc.B} {
+			if _, ok := joinable[name]; !ok {
+				return fmt.Errorf("joint [%v]: unknown object %q", jc.Name, name)
+			}
+		}
+		if _, err := jc.joint(joinable[jc.A], joinable[jc.B]); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range colors {
+		if c == "" {
+			continue
+		}
+		if _, err := parseColor(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseColor returns the color named c (see colornames), or given as #rrggbb
+func parseColor(c string) (color.Color, error) {
+	if strings.HasPrefix(c, "#") {
+		var rgb color.RGBA
+		if _, err := fmt.Sscanf(c, "#%02x%02x%02x", &rgb.R, &rgb.G, &rgb.B); err != nil {
+			return nil, fmt.Errorf("invalid color %q: %v", c, err)
+		}
+		rgb.A = 0xff
+		return rgb, nil
+	}
+
+	rgb, ok := colornames.Map[strings.ToLower(c)]
+	if !ok {
+		return nil, fmt.Errorf("unknown color %q", c)
+	}
+	return rgb, nil
+}
+
+// NewWorld returns a new, empty, world of the scenario's size and physics
+func (s *Scenario) NewWorld(seed int64, debug *world.DebugConfig, console *gocui.Gui) *world.World {
+	var c color.Color = colornames.White
+	if s.Ground.Color != "" {
+		c, _ = parseColor(s.Ground.Color) // validated
+	}
+
+	ground := world.NewGroundObject(
+		"ground", c, 0, 0, s.Width, s.Ground.Height)
+	groundPhys := world.NewBaseObjectPhys(pixel.R(0, 0, s.Width, s.Ground.Height), ground)
+	ground.SetPhys(groundPhys)
+	ground.SetNextPhys(ground.Phys().Copy())
+
+	w := world.NewWorld(s.Width, s.Height, ground, s.Gravity, s.MaxObjectSpeed, seed, debug, console)
+	if s.Drag != nil {
+		w.Drag = *s.Drag
+	}
+	if s.Friction != nil {
+		w.Friction = *s.Friction
+	}
+	if s.MaxAcceleration != nil {
+		w.MaxAcceleration = *s.MaxAcceleration
+	}
+	if s.AngularDrag != nil {
+		w.AngularDrag = *s.AngularDrag
+	}
+	if s.SleepAfter != nil {
+		w.SleepAfter = *s.SleepAfter
+	}
+	w.Boundaries, _ = s.Boundaries.boundaries() // validated
+	return w
+}
+
+// Populate adds the scenario's objects, gates, sinks, fixtures, zones and joints to w
+func (s *Scenario) Populate(w *world.World) error {
+	for _, p := range s.Populations {
+		if err := AddPopulation(w, p); err != nil {
+			return err
+		}
+	}
+	if len(s.Gates) > 0 {
+		if err := AddGates(w, s.Gates); err != nil {
+			return err
+		}
+	}
+	if len(s.Sinks) > 0 {
+		if err := AddSinks(w, s.Sinks); err != nil {
+			return err
+		}
+	}
+	for _, f := range s.Fixtures {
+		if err := AddFixture(w, f); err != nil {
+			return fmt.Errorf("fixture [%v]: %v", f.Name, err)
+		}
+	}
+	if s.RandomFixtures > 0 {
+		AddFixtures(w, s.RandomFixtures)
+	}
+	for _, zc := range s.Zones {
+		if err := AddZone(w, zc); err != nil {
+			return err
+		}
+	}
+	for _, jc := range s.Joints {
+		if err := AddJoint(w, jc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Step advances w by one tick, adding targets as the target policy says first
+func (s *Scenario) Step(w *world.World) {
+	if s.Targets.Max > 0 && (s.Targets.Interval <= 1 || w.Clock().Ticks()%s.Targets.Interval == 0) {
+		AddTarget(w, s.Targets.Radius, s.Targets.Max)
+	}
+	w.Step()
+}
@@ -0,0 +1,485 @@
+package populate
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/go-test/deep"
+	"golang.org/x/image/colornames"
+
+	"github.com/DanTulovsky/alphaville/world"
+)
+
+func TestScenarioFiles(t *testing.T) {
+	files, err := filepath.Glob("scenarios/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			s, err := ReadScenarioFile(file)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			w := s.NewWorld(1, world.NewDebugConfig(), nil)
+			if err := s.Populate(w); err != nil {
+				t.Fatalf("Populate: %v", err)
+			}
+
+			want := 0
+			for _, p := range s.Populations {
+				want += p.Count
+			}
+			if got := len(w.Objects); got != want {
+				t.Errorf("expected %v objects, got %v", want, got)
+			}
+			if got := len(w.Gates); got != len(s.Gates) {
+				t.Errorf("expected %v gates, got %v", len(s.Gates), got)
+			}
+			if got := len(w.Sinks); got != len(s.Sinks) {
+				t.Errorf("expected %v sinks, got %v", len(s.Sinks), got)
+			}
+
+			for i := 0; i < 100; i++ {
+				s.Step(w)
+			}
+		})
+	}
+}
+
+func TestReadScenario_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario string
+		err      string
+	}{
+		{
+			name:     "unknown field",
+			scenario: `{"Width": 100, "Height": 100, "Gravty": -2}`,
+			err:      "unknown field",
+		},
+		{
+			name:     "no size",
+			scenario: `{}`,
+			err:      "invalid world size",
+		},
+		{
+			name:     "drag too high",
+			scenario: `{"Width": 100, "Height": 100, "Drag": 1}`,
+			err:      "invalid drag",
+		},
+		{
+			name:     "no acceleration",
+			scenario: `{"Width": 100, "Height": 100, "MaxAcceleration": 0}`,
+			err:      "invalid max acceleration",
+		},
+		{
+			name:     "angular drag too high",
+			scenario: `{"Width": 100, "Height": 100, "AngularDrag": 1}`,
+			err:      "invalid angular drag",
+		},
+		{
+			name:     "sleep after negative",
+			scenario: `{"Width": 100, "Height": 100, "SleepAfter": -1}`,
+			err:      "invalid sleep after",
+		},
+		{
+			name:     "unknown shape",
+			scenario: `{"Width": 100, "Height": 100, "Populations": [{"Shape": "star", "Behavior": "wonderer"}]}`,
+			err:      "unknown shape",
+		},
+		{
+			name:     "unknown behavior",
+			scenario: `{"Width": 100, "Height": 100, "Populations": [{"Shape": "rect", "Behavior": "dancer"}]}`,
+			err:      "unknown behavior",
+		},
+		{
+			name:     "bad range",
+			scenario: `{"Width": 100, "Height": 100, "Populations": [{"Shape": "rect", "Behavior": "wonderer", "Speed": {"Min": 2, "Max": 1}}]}`,
+			err:      "invalid range",
+		},
+		{
+			name:     "negative lifetime",
+			scenario: `{"Width": 100, "Height": 100, "Populations": [{"Shape": "rect", "Behavior": "wonderer", "Lifetime": {"Min": -1, "Max": 1}}]}`,
+			err:      "negative lifetime",
+		},
+		{
+			name:     "restitution too high",
+			scenario: `{"Width": 100, "Height": 100, "Populations": [{"Shape": "rect", "Behavior": "wonderer", "Restitution": {"Min": 0.5, "Max": 1.5}}]}`,
+			err:      "restitution",
+		},
+		{
+			name:     "negative gravity scale",
+			scenario: `{"Width": 100, "Height": 100, "Populations": [{"Shape": "rect", "Behavior": "wonderer", "GravityScale": {"Min": -1, "Max": 1}}]}`,
+			err:      "invalid gravity scale",
+		},
+		{
+			name:     "two manual objects",
+			scenario: `{"Width": 100, "Height": 100, "Populations": [{"Shape": "rect", "Behavior": "manual", "Count": 2}]}`,
+			err:      "only one manually controlled object",
+		},
+		{
+			name:     "unknown gate filter",
+			scenario: `{"Width": 100, "Height": 100, "Gates": [{"Name": "g", "Filters": ["nobody"]}]}`,
+			err:      "unknown filter",
+		},
+		{
+			name:     "negative sink capacity",
+			scenario: `{"Width": 100, "Height": 100, "Sinks": [{"Name": "s", "Capacity": -1}]}`,
+			err:      "negative capacity",
+		},
+		{
+			name:     "unknown sink filter",
+			scenario: `{"Width": 100, "Height": 100, "Sinks": [{"Name": "s", "Filters": ["nobody"]}]}`,
+			err:      "unknown filter",
+		},
+		{
+			name:     "unknown color",
+			scenario: `{"Width": 100, "Height": 100, "Fixtures": [{"Name": "f", "Color": "ultraviolet"}]}`,
+			err:      "unknown color",
+		},
+		{
+			name:     "unknown zone kind",
+			scenario: `{"Width": 100, "Height": 100, "Zones": [{"Name": "z", "Kind": "lava"}]}`,
+			err:      "unknown kind",
+		},
+		{
+			name:     "zone without an area",
+			scenario: `{"Width": 100, "Height": 100, "Zones": [{"Name": "z", "Kind": "wind", "Force": {"X": 1, "Y": 0}}]}`,
+			err:      "non empty area",
+		},
+		{
+			name:     "zone color",
+			scenario: `{"Width": 100, "Height": 100, "Zones": [{"Name": "z", "Kind": "well", "Radius": 10, "Color": "ultraviolet"}]}`,
+			err:      "unknown color",
+		},
+		{
+			name:     "unknown motion",
+			scenario: `{"Width": 100, "Height": 100, "Fixtures": [{"Name": "f", "Width": 20, "Height": 20, "Motion": {"Kind": "teleport"}}]}`,
+			err:      "unknown motion",
+		},
+		{
+			name:     "motion speed",
+			scenario: `{"Width": 100, "Height": 100, "Fixtures": [{"Name": "f", "Width": 20, "Height": 20, "Motion": {"Kind": "linear", "To": {"X": 10, "Y": 0}}}]}`,
+			err:      "invalid motion speed",
+		},
+		{
+			name:     "unknown boundary mode",
+			scenario: `{"Width": 100, "Height": 100, "Boundaries": {"Top": {"Mode": "sticky"}}}`,
+			err:      "unknown mode",
+		},
+		{
+			name:     "wrap on one side",
+			scenario: `{"Width": 100, "Height": 100, "Boundaries": {"All": {"Mode": "wrap"}, "Left": {"Mode": "open"}}}`,
+			err:      "left and right",
+		},
+		{
+			name:     "unknown joint kind",
+			scenario: `{"Width": 100, "Height": 100, "Fixtures": [{"Name": "f"}], "Populations": [{"Name": "o", "Shape": "rect", "Behavior": "wonderer", "Count": 1}], "Joints": [{"Name": "j", "Kind": "glue", "A": "f", "B": "o"}]}`,
+			err:      "unknown kind",
+		},
+		{
+			name:     "joint to an unknown object",
+			scenario: `{"Width": 100, "Height": 100, "Populations": [{"Name": "o", "Shape": "rect", "Behavior": "wonderer", "Count": 2}], "Joints": [{"Name": "j", "Kind": "distance", "A": "o-0", "B": "o"}]}`,
+			err:      "unknown object \"o\"",
+		},
+		{
+			name:     "joint between fixtures",
+			scenario: `{"Width": 100, "Height": 100, "Fixtures": [{"Name": "f"}, {"Name": "g"}], "Joints": [{"Name": "j", "Kind": "weld", "A": "f", "B": "g"}]}`,
+			err:      "joins two fixtures",
+		},
+		{
+			name:     "spring stiffness",
+			scenario: `{"Width": 100, "Height": 100, "Populations": [{"Name": "o", "Shape": "rect", "Behavior": "wonderer", "Count": 2}], "Joints": [{"Name": "j", "Kind": "spring", "A": "o-0", "B": "o-1"}]}`,
+			err:      "invalid stiffness",
+		},
+		{
+			name:     "joint color",
+			scenario: `{"Width": 100, "Height": 100, "Populations": [{"Name": "o", "Shape": "rect", "Behavior": "wonderer", "Count": 2}], "Joints": [{"Name": "j", "Kind": "distance", "A": "o-0", "B": "o-1", "Color": "ultraviolet"}]}`,
+			err:      "unknown color",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadScenario(strings.NewReader(tt.scenario))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestScenario_Physics(t *testing.T) {
+	s, err := ReadScenario(strings.NewReader(`{"Width": 100, "Height": 100, "Drag": 0, "Friction": 0.5, "AngularDrag": 0.1}`))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	w := s.NewWorld(1, world.NewDebugConfig(), nil)
+
+	want := []float64{0, 0.5, world.DefaultMaxAcceleration, 0.1}
+	got := []float64{w.Drag, w.Friction, w.MaxAcceleration, w.AngularDrag}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("expected drag, friction, max acceleration and angular drag %v, got %v: %v", want, got, diff)
+	}
+}
+
+func TestScenario_SleepAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario string
+		want     int
+	}{
+		{name: "default", scenario: `{"Width": 100, "Height": 100}`, want: world.DefaultSleepAfter},
+		{name: "set", scenario: `{"Width": 100, "Height": 100, "SleepAfter": 120}`, want: 120},
+		{name: "never", scenario: `{"Width": 100, "Height": 100, "SleepAfter": 0}`, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ReadScenario(strings.NewReader(tt.scenario))
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			if got := s.NewWorld(1, world.NewDebugConfig(), nil).SleepAfter; got != tt.want {
+				t.Errorf("expected sleep after %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAddPopulation_Restitution(t *testing.T) {
+	tests := []struct {
+		name        string
+		restitution *Range
+		want        float64
+	}{
+		{name: "default", want: world.DefaultRestitution},
+		{name: "inelastic", restitution: &Range{Min: 0, Max: 0}, want: 0},
+		{name: "elastic", restitution: &Range{Min: 1, Max: 1}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := DefaultScenario().NewWorld(1, world.NewDebugConfig(), nil)
+			p := Population{Count: 1, Shape: "circle", Behavior: "default", Radius: Range{Min: 10, Max: 10}, Restitution: tt.restitution}
+			if err := AddPopulation(w, p); err != nil {
+				t.Fatalf("AddPopulation: %v", err)
+			}
+			if got := w.Objects[0].Restitution(); got != tt.want {
+				t.Errorf("expected restitution %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAddPopulation_GravityScale(t *testing.T) {
+	tests := []struct {
+		name         string
+		gravityScale *Range
+		want         float64
+	}{
+		{name: "default", want: 1},
+		{name: "floating", gravityScale: &Range{Min: 0, Max: 0}, want: 0},
+		{name: "heavy", gravityScale: &Range{Min: 2, Max: 2}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := DefaultScenario().NewWorld(1, world.NewDebugConfig(), nil)
+			p := Population{Count: 1, Shape: "circle", Behavior: "default", Radius: Range{Min: 10, Max: 10}, GravityScale: tt.gravityScale}
+			if err := AddPopulation(w, p); err != nil {
+				t.Fatalf("AddPopulation: %v", err)
+			}
+			if got := w.Objects[0].GravityScale(); got != tt.want {
+				t.Errorf("expected gravity scale %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScenario_Zones(t *testing.T) {
+	s, err := ReadScenario(strings.NewReader(`{"Width": 800, "Height": 600, "Zones": [
+		{"Name": "wind", "Kind": "wind", "Rect": {"Min": {"X": 0, "Y": 300}, "Max": {"X": 800, "Y": 600}}, "Force": {"X": 1, "Y": 0}},
+		{"Name": "pond", "Kind": "water", "Polygon": [{"X": 0, "Y": 0}, {"X": 100, "Y": 0}, {"X": 50, "Y": 50}], "Density": 0.01, "Drag": 0.1, "Color": "teal"},
+		{"Name": "well", "Kind": "well", "Center": {"X": 400, "Y": 300}, "Radius": 50, "Strength": -1}
+	]}`))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	w := s.NewWorld(1, world.NewDebugConfig(), nil)
+	if err := s.Populate(w); err != nil {
+		t.Fatalf("Populate: %v", err)
+	}
+
+	zones := w.Zones()
+	if len(zones) != 3 {
+		t.Fatalf("expected 3 zones, got %v", zones)
+	}
+	want := []world.ZoneKind{world.ZoneWind, world.ZoneWater, world.ZoneWell}
+	for i, z := range zones {
+		if z.Kind != want[i] {
+			t.Errorf("expected zone %v to be %v, got %v", z.Name, want[i], z.Kind)
+		}
+	}
+	if got := zones[0].Covered(pixel.R(0, 0, 800, 600)); got != 800*300 {
+		t.Errorf("expected the wind to cover %v, got %v", 800*300, got)
+	}
+	if diff := deep.Equal(zones[1].Color, colornames.Teal); diff != nil {
+		t.Errorf("expected a teal pond: %v", diff)
+	}
+}
+
+func TestScenario_MovingFixtures(t *testing.T) {
+	s, err := ReadScenario(strings.NewReader(`{"Width": 800, "Height": 600, "Fixtures": [
+		{"Name": "wall", "Location": {"X": 100, "Y": 100}, "Width": 20, "Height": 100},
+		{"Name": "lift", "Location": {"X": 200, "Y": 100}, "Width": 60, "Height": 20, "Motion": {"Kind": "linear", "To": {"X": 0, "Y": 200}, "Speed": 1}},
+		{"Name": "loop", "Location": {"X": 400, "Y": 100}, "Width": 60, "Height": 20, "Motion": {"Kind": "waypoints", "Waypoints": [{"X": 100, "Y": 0}, {"X": 100, "Y": 100}], "Speed": 2}},
+		{"Name": "sweeper", "Location": {"X": 600, "Y": 300}, "Width": 100, "Height": 20, "Motion": {"Kind": "rotate", "Pivot": {"X": 650, "Y": 310}, "Speed": -0.02}}
+	]}`))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	w := s.NewWorld(1, world.NewDebugConfig(), nil)
+	if err := s.Populate(w); err != nil {
+		t.Fatalf("Populate: %v", err)
+	}
+
+	want := []*world.Motion{
+		nil,
+		world.NewLinearMotion(pixel.V(0, 200), 1),
+		world.NewWaypointMotion([]pixel.Vec{pixel.V(100, 0), pixel.V(100, 100)}, 2),
+		world.NewRotateMotion(pixel.V(650, 310), -0.02),
+	}
+	for i, o := range w.Fixtures() {
+		if diff := deep.Equal(o.(*world.Fixture).Motion(), want[i]); diff != nil {
+			t.Errorf("fixture [%v]: unexpected motion: %v", o.Name(), diff)
+		}
+	}
+}
+
+func TestScenario_Boundaries(t *testing.T) {
+	tests := []struct {
+		name       string
+		boundaries string
+		want       world.Boundaries
+	}{
+		{
+			name: "solid",
+			want: world.Boundaries{},
+		},
+		{
+			name:       "all",
+			boundaries: `{"All": {"Mode": "wrap"}}`,
+			want:       world.NewBoundaries(world.Boundary{Mode: world.BoundaryWrap}),
+		},
+		{
+			name:       "each edge",
+			boundaries: `{"All": {"Mode": "wrap"}, "Bottom": {"Mode": "bounce", "Restitution": 0.5}, "Top": {"Mode": "open"}}`,
+			want: world.Boundaries{
+				Left:   world.Boundary{Mode: world.BoundaryWrap},
+				Right:  world.Boundary{Mode: world.BoundaryWrap},
+				Bottom: world.Boundary{Mode: world.BoundaryBounce, Restitution: 0.5},
+				Top:    world.Boundary{Mode: world.BoundaryOpen},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := `{"Width": 800, "Height": 600}`
+			if tt.boundaries != "" {
+				in = fmt.Sprintf(`{"Width": 800, "Height": 600, "Boundaries": %v}`, tt.boundaries)
+			}
+			s, err := ReadScenario(strings.NewReader(in))
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			w := s.NewWorld(1, world.NewDebugConfig(), nil)
+			if diff := deep.Equal(w.Boundaries, tt.want); diff != nil {
+				t.Errorf("unexpected boundaries: %v", diff)
+			}
+		})
+	}
+}
+
+func TestScenario_Joints(t *testing.T) {
+	s, err := ReadScenario(strings.NewReader(`{"Width": 800, "Height": 600, "Gravity": -2,
+		"Fixtures": [{"Name": "beam", "Location": {"X": 300, "Y": 500}, "Width": 200, "Height": 20}],
+		"Populations": [
+			{"Name": "link", "Shape": "rect", "Behavior": "default", "Count": 2, "Mass": {"Min": 1, "Max": 1}, "Width": {"Min": 20, "Max": 20}, "Height": {"Min": 20, "Max": 20}},
+			{"Name": "bob", "Shape": "circle", "Behavior": "default", "Count": 1, "Mass": {"Min": 1, "Max": 1}, "Radius": {"Min": 10, "Max": 10}}
+		],
+		"Joints": [
+			{"Name": "rope", "Kind": "distance", "A": "beam", "B": "link-0", "Length": 40},
+			{"Name": "spring", "Kind": "spring", "A": "link-0", "B": "link-1", "Length": 30, "Stiffness": 0.1, "Damping": 0.2, "Color": "teal"},
+			{"Name": "weld", "Kind": "weld", "A": "link-1", "B": "bob", "BreakForce": 5}
+		]}`))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	w := s.NewWorld(1, world.NewDebugConfig(), nil)
+	if err := s.Populate(w); err != nil {
+		t.Fatalf("Populate: %v", err)
+	}
+
+	want := []struct {
+		kind world.JointKind
+		a, b string
+	}{
+		{world.JointDistance, "beam", "link-0"},
+		{world.JointSpring, "link-0", "link-1"},
+		{world.JointWeld, "link-1", "bob"},
+	}
+	joints := w.Joints()
+	if len(joints) != len(want) {
+		t.Fatalf("expected %v joints, got %v", len(want), joints)
+	}
+	for i, j := range joints {
+		if j.Kind != want[i].kind || j.A.Name() != want[i].a || j.B.Name() != want[i].b {
+			t.Errorf("expected joint [%v] to be a %v between %v and %v, got a %v between %v and %v",
+				j.Name, want[i].kind, want[i].a, want[i].b, j.Kind, j.A.Name(), j.B.Name())
+		}
+	}
+	if diff := deep.Equal(joints[1].Color, colornames.Teal); diff != nil {
+		t.Errorf("expected a teal spring: %v", diff)
+	}
+	if got := joints[2].BreakForce; got != 5 {
+		t.Errorf("expected the weld to break above 5, got %v", got)
+	}
+}
+
+func TestAddPopulation_Names(t *testing.T) {
+	tests := []struct {
+		p    Population
+		want []string
+	}{
+		{p: Population{Count: 2}, want: []string{"0", "1"}},
+		{p: Population{Name: "ts", Count: 2}, want: []string{"ts-0", "ts-1"}},
+		{p: Population{Name: "manual", Count: 1}, want: []string{"manual"}},
+	}
+
+	for _, tt := range tests {
+		w := DefaultScenario().NewWorld(1, world.NewDebugConfig(), nil)
+		tt.p.Shape = "rect"
+		tt.p.Behavior = "default"
+		tt.p.Width = Range{Min: 30, Max: 30}
+		tt.p.Height = Range{Min: 30, Max: 30}
+
+		if err := AddPopulation(w, tt.p); err != nil {
+			t.Fatalf("AddPopulation: %v", err)
+		}
+		got := []string{}
+		for _, o := range w.Objects {
+			got = append(got, o.Name())
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("expected names %v, got %v", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+// Package record records simulation runs to a file and replays them.
+//
+// A recording is a JSON-lines file: a Header with the seed and the scenario the world was built
+// from, followed by one Entry per observer event, in the order the world sent them, and a final
+// "end" entry. Manual input and console commands are events as well (world.InputEvent), so
+// replaying re-applies them at the tick they happened and checks that the rest of the event stream
+// comes out the same.
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/DanTulovsky/alphaville/observer"
+	"github.com/DanTulovsky/alphaville/populate"
+	"github.com/DanTulovsky/alphaville/world"
+)
+
+// Version is the version of the recording format
+const Version = 1
+
+// Entry kinds
+const (
+	KindEvent = "event"
+	KindInput = "input"
+	KindEnd   = "end"
+)
+
+// Header is the first line of a recording
+type Header struct {
+	Version      int
+	Seed         int64
+	TickDuration time.Duration
+	Scenario     *populate.Scenario
+}
+
+// Entry is one line of a recording
+type Entry struct {
+	Tick        int64
+	Kind        string
+	Type        string               // go type of the event
+	Description string               `json:",omitempty"`
+	Data        []observer.EventData `json:",omitempty"`
+}
+
+// newEntry returns the entry for e, sent at tick
+func newEntry(tick int64, e observer.Event) Entry {
+	kind := KindEvent
+	if _, ok := e.(*world.InputEvent); ok {
+		kind = KindInput
+	}
+	return Entry{
+		Tick:        tick,
+		Kind:        kind,
+		Type:        fmt.Sprintf("%T", e),
+		Description: e.Description(),
+		Data:        e.Data(),
+	}
+}
+
+// ids are random on every run, they are ignored when comparing events
+var idRE = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+
+// signature returns the parts of the entry that must match on replay
+func (e Entry) signature() string {
+	return idRE.ReplaceAllString(fmt.Sprintf("%v %v %v", e.Type, e.Description, e.Data), "<id>")
+}
+
+// String returns the entry as string
+func (e Entry) String() string {
+	return fmt.Sprintf("[tick %v] %v %q %v", e.Tick, e.Type, e.Description, e.Data)
+}
+
+// wallTimeEvent returns true for events that depend on wall time (e.g. frame rate), these are
+// not part of the simulation and are not recorded
+func wallTimeEvent(e observer.Event) bool {
+	for _, d := range e.Data() {
+		switch d.Key {
+		case "fps", "ups":
+			return true
+		}
+	}
+	return false
+}
+
+// Recorder records all events in a world
+type Recorder struct {
+	w   *world.World
+	enc *json.Encoder
+	err error // first write error
+}
+
+// NewRecorder writes the header to out and starts recording all events in w to it.
+// w must be built from s, must not be populated yet and must run on a world.SimClock.
+func NewRecorder(out io.Writer, w *world.World, s *populate.Scenario) (*Recorder, error) {
+	if _, ok := w.Clock().(*world.SimClock); !ok {
+		return nil, fmt.Errorf("cannot record a world running on %T, need a *world.SimClock", w.Clock())
+	}
+
+	r := &Recorder{
+		w:   w,
+		enc: json.NewEncoder(out),
+	}
+	h := Header{
+		Version:      Version,
+		Seed:         w.Seed(),
+		TickDuration: w.Clock().TickDuration(),
+		Scenario:     s,
+	}
+	if err := r.enc.Encode(h); err != nil {
+		return nil, fmt.Errorf("error writing header: %v", err)
+	}
+
+	w.Monitor(r)
+	return r, nil
+}
+
+// OnNotify records e
+func (r *Recorder) OnNotify(e observer.Event) {
+	if e == nil || wallTimeEvent(e) {
+		return
+	}
+	r.write(newEntry(r.w.Clock().Ticks(), e))
+}
+
+// Name returns the name of the recorder
+func (r *Recorder) Name() string {
+	return "recorder"
+}
+
+func (r *Recorder) write(e Entry) {
+	if r.err != nil {
+		return
+	}
+	if err := r.enc.Encode(e); err != nil {
+		r.err = fmt.Errorf("error writing entry %v: %v", e, err)
+	}
+}
+
+// Close ends the recording at the current tick, call it after w.End().
+// It returns the first error hit while recording.
+func (r *Recorder) Close() error {
+	r.write(Entry{Tick: r.w.Clock().Ticks(), Kind: KindEnd})
+	return r.err
+}
+
+// Divergence is returned by Replay when the replayed run does not match the recording
+type Divergence struct {
+	Tick     int64
+	Expected *Entry // nil if the replay sent an event that is not in the recording
+	Got      *Entry // nil if the replay did not send an expected event
+}
+
+// Error implements the error interface
+func (d *Divergence) Error() string {
+	switch {
+	case d.Expected == nil:
+		return fmt.Sprintf("replay diverged at tick %v: unexpected %v", d.Tick, d.Got)
+	case d.Got == nil:
+		return fmt.Sprintf("replay diverged at tick %v: missing %v", d.Tick, d.Expected)
+	}
+	return fmt.Sprintf("replay diverged at tick %v: expected %v, got %v", d.Tick, d.Expected, d.Got)
+}
+
+// checker compares the events of a replayed world with the recorded ones
+type checker struct {
+	w        *world.World
+	expected []Entry
+	next     int
+	err      *Divergence // first divergence
+}
+
+// OnNotify compares e with the next expected event
+func (c *checker) OnNotify(e observer.Event) {
+	if e == nil || wallTimeEvent(e) || c.err != nil {
+		return
+	}
+
+	got := newEntry(c.w.Clock().Ticks(), e)
+	if c.next >= len(c.expected) {
+		c.err = &Divergence{Tick: got.Tick, Got: &got}
+		return
+	}
+
+	expected := c.expected[c.next]
+	if expected.Tick != got.Tick || expected.signature() != got.signature() {
+		c.err = &Divergence{Tick: expected.Tick, Expected: &expected, Got: &got}
+		return
+	}
+	c.next++
+}
+
+// Name returns the name of the checker
+func (c *checker) Name() string {
+	return "replay checker"
+}
+
+// Read reads a recording
+func Read(in io.Reader) (*Header, []Entry, error) {
+	dec := json.NewDecoder(bufio.NewReader(in))
+
+	h := &Header{}
+	if err := dec.Decode(h); err != nil {
+		return nil, nil, fmt.Errorf("error reading header: %v", err)
+	}
+	if h.Version != Version {
+		return nil, nil, fmt.Errorf("unsupported recording version %v, want %v", h.Version, Version)
+	}
+	if h.Scenario == nil {
+		return nil, nil, fmt.Errorf("recording has no scenario")
+	}
+
+	var entries []Entry
+	for {
+		var e Entry
+		if err := dec.Decode(&e); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, nil, fmt.Errorf("error reading entry %v: %v", len(entries), err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 || entries[len(entries)-1].Kind != KindEnd {
+		return nil, nil, fmt.Errorf("recording is truncated, no end entry")
+	}
+
+	return h, entries, nil
+}
+
+// Replay re-runs the recording in in and checks that the world sends the same events.
+// It returns a *Divergence error at the first event that does not match.
+func Replay(in io.Reader) error {
+	h, entries, err := Read(in)
+	if err != nil {
+		return err
+	}
+	end := entries[len(entries)-1].Tick
+	events := entries[:len(entries)-1]
+
+	w := h.Scenario.NewWorld(h.Seed, world.NewDebugConfig(), nil)
+	w.SetClock(world.NewSimClock(h.TickDuration))
+	c := &checker{w: w, expected: events}
+	w.Monitor(c)
+	if err := h.Scenario.Populate(w); err != nil {
+		return err
+	}
+
+	for next := 0; c.err == nil; {
+		// re-apply the inputs in the order they came in at this tick
+		for ; next < len(events) && events[next].Tick <= w.Clock().Ticks(); next++ {
+			if events[next].Kind != KindInput {
+				continue
+			}
+			if err := apply(w, events[next]); err != nil {
+				return err
+			}
+		}
+		if w.Clock().Ticks() >= end {
+			break
+		}
+		h.Scenario.Step(w)
+	}
+	if c.err == nil {
+		w.End()
+	}
+
+	if c.err != nil {
+		return c.err
+	}
+	if c.next < len(events) {
+		return &Divergence{Tick: events[c.next].Tick, Expected: &events[c.next]}
+	}
+	return nil
+}
+
+// apply sends the recorded input e to w
+func apply(w *world.World, e Entry) error {
+	for _, d := range e.Data {
+		switch d.Key {
+		case "manual_velocity":
+			v, err := world.ParseVec(d.Value)
+			if err != nil {
+				return err
+			}
+			w.SetManualVelocity(v)
+		case "command":
+			w.ProcessCommand(d.Value, io.Discard)
+		}
+	}
+	return nil
+}
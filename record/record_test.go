@@ -0,0 +1,93 @@
+package record
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/DanTulovsky/alphaville/populate"
+	"github.com/DanTulovsky/alphaville/world"
+)
+
+var seekers = populate.Population{
+	Name:     "ts",
+	Count:    2,
+	Shape:    "rect",
+	Behavior: "target_seeker",
+	Speed:    populate.Range{Min: 3, Max: 3},
+	Mass:     populate.Range{Min: 1, Max: 1},
+	Width:    populate.Range{Min: 40, Max: 40},
+	Height:   populate.Range{Min: 40, Max: 40},
+}
+
+// record runs s for ticks and returns the recording
+func record(t *testing.T, s *populate.Scenario, seed int64, ticks int) *bytes.Buffer {
+	out := &bytes.Buffer{}
+
+	w := s.NewWorld(seed, world.NewDebugConfig(), nil)
+	w.SetClock(world.NewSimClock(world.DefaultTickDuration))
+	r, err := NewRecorder(out, w, s)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	if err := s.Populate(w); err != nil {
+		t.Fatalf("Populate: %v", err)
+	}
+
+	for i := 0; i < ticks; i++ {
+		if i == ticks/2 {
+			w.ProcessCommand("help", &bytes.Buffer{})
+		}
+		s.Step(w)
+	}
+	w.End()
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return out
+}
+
+func TestReplay(t *testing.T) {
+	s := populate.DefaultScenario()
+	s.Populations = append(s.Populations, seekers)
+
+	out := record(t, s, 1, 300)
+	if err := Replay(bytes.NewReader(out.Bytes())); err != nil {
+		t.Errorf("replay of an unchanged recording failed: %v", err)
+	}
+}
+
+func TestReplay_Divergence(t *testing.T) {
+	s := populate.DefaultScenario()
+	s.Populations = append(s.Populations, seekers)
+
+	recording := record(t, s, 1, 300).String()
+	lines := strings.Split(strings.TrimSpace(recording), "\n")
+
+	// replace the first recorded event with a bogus one
+	for i, l := range lines {
+		if strings.Contains(l, `"Kind":"event"`) {
+			lines[i] = `{"Tick":0,"Kind":"event","Type":"*world.GateEvent","Description":"bogus"}`
+			break
+		}
+	}
+
+	err := Replay(strings.NewReader(strings.Join(lines, "\n")))
+	d, ok := err.(*Divergence)
+	if !ok {
+		t.Fatalf("expected a *Divergence, got: %v", err)
+	}
+	if d.Expected == nil || d.Expected.Description != "bogus" {
+		t.Errorf("divergence reported at the wrong event: %v", d)
+	}
+}
+
+func TestNewRecorder_WallClock(t *testing.T) {
+	s := populate.DefaultScenario()
+	w := s.NewWorld(1, world.NewDebugConfig(), nil)
+	w.SetClock(world.NewWallClock(world.DefaultTickDuration))
+
+	if _, err := NewRecorder(&bytes.Buffer{}, w, s); err == nil {
+		t.Errorf("expected an error recording a wall clock world")
+	}
+}
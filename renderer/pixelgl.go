@@ -0,0 +1,95 @@
+// Package renderer provides world.Renderer backends that need a display
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/DanTulovsky/alphaville/utils"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+)
+
+// PixelGLRenderer draws into a pixelgl window
+type PixelGLRenderer struct {
+	win *pixelgl.Window
+	imd *imdraw.IMDraw
+}
+
+// NewPixelGLRenderer returns a renderer drawing into win
+func NewPixelGLRenderer(win *pixelgl.Window) *PixelGLRenderer {
+	return &PixelGLRenderer{
+		win: win,
+		imd: imdraw.New(nil),
+	}
+}
+
+// reset prepares the shared imdraw for a new shape
+func (r *PixelGLRenderer) reset(c color.Color) {
+	r.imd.Clear()
+	r.imd.Reset()
+	r.imd.SetMatrix(pixel.IM)
+	r.imd.Color = c
+}
+
+// Circle draws a circle
+func (r *PixelGLRenderer) Circle(center pixel.Vec, radius float64, c color.Color, thickness float64) {
+	r.reset(c)
+	r.imd.Push(center)
+	r.imd.Circle(radius, thickness)
+	r.imd.Draw(r.win)
+}
+
+// Ellipse draws an ellipse, radius.X is the horizontal radius before rotating it by angle
+func (r *PixelGLRenderer) Ellipse(center pixel.Vec, radius pixel.Vec, angle float64, c color.Color, thickness float64) {
+	r.reset(c)
+	r.imd.SetMatrix(pixel.IM.Rotated(center, angle))
+	r.imd.Push(center)
+	r.imd.Ellipse(radius, thickness)
+	r.imd.Draw(r.win)
+}
+
+// Line draws a line through all the points
+func (r *PixelGLRenderer) Line(points []pixel.Vec, c color.Color, thickness float64) {
+	r.reset(c)
+	for _, p := range points {
+		r.imd.Push(p)
+	}
+	r.imd.Line(thickness)
+	r.imd.Draw(r.win)
+}
+
+// Polygon draws a polygon with the points as its corners
+func (r *PixelGLRenderer) Polygon(points []pixel.Vec, c color.Color, thickness float64) {
+	r.reset(c)
+	for _, p := range points {
+		r.imd.Push(p)
+	}
+	r.imd.Polygon(thickness)
+	r.imd.Draw(r.win)
+}
+
+// Rect draws a rectangle
+func (r *PixelGLRenderer) Rect(rect pixel.Rect, c color.Color, thickness float64) {
+	r.reset(c)
+	r.imd.Push(rect.Min)
+	r.imd.Push(rect.Max)
+	r.imd.Rectangle(thickness)
+	r.imd.Draw(r.win)
+}
+
+// Text draws lines of text, each centered on v.X
+func (r *PixelGLRenderer) Text(v pixel.Vec, c color.Color, lines ...string) {
+	txt := text.New(v, utils.Atlas())
+	txt.Color = c
+
+	for _, l := range lines {
+		txt.Dot.X -= txt.BoundsOf(l).W() / 2
+		fmt.Fprintf(txt, "%v\n", l)
+	}
+
+	txt.Draw(r.win, pixel.IM)
+}
@@ -1,23 +1,24 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	_ "image/png"
 	"log"
 	"math"
-	"math/rand"
+	"os"
 	"strconv"
 	"time"
 
-	"github.com/tevino/abool"
-
-	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
-	"github.com/jroimartin/gocui"
 	"github.com/DanTulovsky/alphaville/console"
 	"github.com/DanTulovsky/alphaville/observer"
 	"github.com/DanTulovsky/alphaville/populate"
+	"github.com/DanTulovsky/alphaville/record"
+	"github.com/DanTulovsky/alphaville/renderer"
 	"github.com/DanTulovsky/alphaville/world"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/jroimartin/gocui"
 	"golang.org/x/image/colornames"
 
 	"net/http"
@@ -30,27 +31,18 @@ var (
 	second  = time.Tick(time.Second)
 	paused  = false
 
-	debug = &world.DebugConfig{
-		QT: world.QuadTreeDebug{
-			DrawTree:    abool.NewBool(false),
-			ColorTree:   abool.NewBool(false),
-			DrawText:    abool.NewBool(false),
-			DrawObjects: abool.NewBool(false),
-		},
-	}
-)
-
-const (
-	// MsPerUpdate ms per game update loop, excluding rendering. This needs to be less than the time for the main update() loop
-	MsPerUpdate = 4
-	gravity     = -2
+	// the manual velocity last passed on to the manually controlled object, only changes are passed on
+	// (and recorded)
+	lastCtrl   pixel.Vec
+	lastManual world.Object
 
-	worldMaxX, worldMaxY           = 1200, 1200
-	visibleWinMaxX, visibleWinMaxY = worldMaxX, worldMaxY
-	groundHeight                   = 40
+	seed      = flag.Int64("seed", 0, "random seed for the world, 0 picks one from the clock")
+	wallClock = flag.Bool("wallclock", false, "run the world on wall clock time instead of simulated tick time")
+	ups       = flag.Int("ups", 60, "world updates (ticks) per second, independent of the frame rate")
+	recordTo  = flag.String("record", "", "record the run to this file, replay it with alphaville-sim -replay")
+	scenario  = flag.String("scenario", "", "JSON scenario file to populate the world from, the built in default if empty")
 
-	maxTargets     = 5
-	maxObjectSpeed = 4
+	debug = world.NewDebugConfig()
 )
 
 // processMouseLeftInput handles left click
@@ -83,8 +75,7 @@ func processInput(win *pixelgl.Window, w *world.World, ctrl pixel.Vec) {
 		processMouseLeftInput(w, win.MousePosition())
 	}
 
-	mo := w.ManualControl
-	if !mo.IsSpawned() {
+	if !w.ManualControl.IsSpawned() {
 		return
 	}
 
@@ -99,8 +90,10 @@ func processInput(win *pixelgl.Window, w *world.World, ctrl pixel.Vec) {
 		ctrl.Y--
 	}
 
-	mo.SetManualVelocity(ctrl)
-
+	if ctrl != lastCtrl || w.ManualControl != lastManual {
+		w.SetManualVelocity(ctrl)
+		lastCtrl, lastManual = ctrl, w.ManualControl
+	}
 }
 
 func togglePause() {
@@ -111,17 +104,15 @@ func toggleDebug(w *world.World) {
 
 }
 
-// update calls each object's update
-func update(w *world.World) {
+// update advances the world by one tick
+func update(w *world.World, s *populate.Scenario) {
 	// defer utils.Elapsed("update")()
-	w.Update()
-	w.NextTick()
-	w.SpawnAllNew()
+	s.Step(w)
 }
 
-// draw draws the world
-func draw(w *world.World, win *pixelgl.Window) {
-	w.Draw(win)
+// draw draws the world, alpha of the way to the next tick
+func draw(w *world.World, r world.Renderer, alpha float64) {
+	w.Draw(r, alpha)
 }
 
 func run() {
@@ -146,40 +137,59 @@ func run() {
 		log.Println(http.ListenAndServe("localhost:6060", nil))
 	}()
 
-	ground := world.NewGroundObject(
-		"ground", colornames.White, 0, 0, worldMaxX, groundHeight)
-	groundPhys := world.NewBaseObjectPhys(pixel.R(0, 0, worldMaxX, groundHeight), ground)
-	ground.SetPhys(groundPhys)
-	ground.SetNextPhys(ground.Phys().Copy())
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
 
-	w := world.NewWorld(math.Min(mWidth, worldMaxX), math.Min(mHeight, worldMaxY), ground, gravity, maxObjectSpeed, debug, g)
-	fmt.Fprintf(w.ConsoleO(), "The World is Born...\n")
+	s := populate.DefaultScenario()
+	if *scenario != "" {
+		var err error
+		if s, err = populate.ReadScenarioFile(*scenario); err != nil {
+			log.Fatalf("cannot read scenario: %v", err)
+		}
+	}
+	s.Width = math.Min(mWidth, s.Width)
+	s.Height = math.Min(mHeight, s.Height)
+
+	w := s.NewWorld(*seed, debug, g)
+	stepper := world.NewFixedStep(*ups)
+	if *wallClock {
+		w.SetClock(world.NewWallClock(stepper.Step()))
+	} else {
+		w.SetClock(world.NewSimClock(stepper.Step()))
+	}
+	fmt.Fprintf(w.ConsoleO(), "The World is Born (seed: %v)...\n", w.Seed())
 
 	if err := g.SetKeybinding("input", gocui.KeyEnter, gocui.ModNone, w.HandleConsoleInput); err != nil {
 		log.Panicln(err)
 	}
 
+	if *recordTo != "" {
+		f, err := os.Create(*recordTo)
+		if err != nil {
+			log.Fatalf("cannot create recording: %v", err)
+		}
+		defer f.Close()
+
+		rec, err := record.NewRecorder(f, w, s)
+		if err != nil {
+			log.Fatalf("cannot record: %v", err)
+		}
+		defer func() {
+			if err := rec.Close(); err != nil {
+				log.Printf("error recording: %v", err)
+			}
+		}()
+	}
+
 	// populate the world
-	// tsColors := colorful.FastHappyPalette(10)
-	// populate.AddTargetSeeker(w, "1", 3, tsColors[0])
-	// populate.AddTargetSeeker(w, "2", 4, tsColors[1])
-	// populate.AddTargetSeeker(w, "3", 5, tsColors[2])
-	// populate.AddTargetSeeker(w, "4", 2.2, tsColors[3])
-	// populate.AddTargetSeeker(w, "5", 3, tsColors[4])
-	// populate.AddTargetSeeker(w, "6", 4, tsColors[5])
-	// populate.AddTargetSeeker(w, "7", 5, tsColors[6])
-	// populate.AddTargetSeeker(w, "8", 2.2, tsColors[7])
-
-	populate.RandomCircles(w, 2)
-	populate.RandomRectangles(w, 10)
-	// populate.RandomEllipses(w, 8)
-	// populate.AddManualObject(w, 60, 60)
-	populate.AddGates(w)
-	// populate.AddFixtures(w, 6)
+	if err := s.Populate(w); err != nil {
+		log.Fatalf("cannot populate the world: %v", err)
+	}
 
 	cfg := pixelgl.WindowConfig{
 		Title:     "Play!",
-		Bounds:    pixel.R(0, 0, math.Min(mWidth, visibleWinMaxX), math.Min(mHeight, visibleWinMaxY)),
+		Bounds:    pixel.R(0, 0, s.Width, s.Height),
 		VSync:     true,
 		Resizable: false,
 	}
@@ -192,10 +202,9 @@ func run() {
 	// set to false for pixel art
 	win.SetSmooth(true)
 	win.Clear(colornames.Black)
+	r := renderer.NewPixelGLRenderer(win)
 
 	previous := time.Now()
-	// how far the game's clock is behind compared to the real world; in ms
-	var lag int64
 
 	// show world stats periodically
 	ticker := time.NewTicker(time.Second * 5)
@@ -207,31 +216,26 @@ func run() {
 
 	// Main loop to keep window running
 	for !win.Closed() {
-		elapsed := time.Since(previous).Nanoseconds() / 1000000
+		elapsed := time.Since(previous)
 		previous = time.Now()
-		lag += elapsed
 
 		// manual control
 		ctrl := pixel.ZV
 		// user input
 		processInput(win, w, ctrl)
+		w.ProcessCommands()
 
+		// update the game state at a fixed rate, regardless of the frame rate
 		if !paused {
-			populate.AddTarget(w, 10, maxTargets)
-			update(w)
-			updates++
+			for steps := stepper.Advance(elapsed); steps > 0; steps-- {
+				update(w, s)
+				updates++
+			}
 		}
 
-		// // update the game state
-		// for lag >= MsPerUpdate {
-		// 	update(w)
-		// 	updates++
-		// 	lag -= MsPerUpdate
-		// }
-
 		// render below here
 		win.Clear(colornames.Black)
-		draw(w, win)
+		draw(w, r, stepper.Alpha())
 		win.Update()
 
 		frames++
@@ -251,8 +255,8 @@ func run() {
 }
 
 func main() {
+	flag.Parse()
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	rand.Seed(time.Now().UnixNano())
 
 	pixelgl.Run(run)
 }
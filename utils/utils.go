@@ -25,15 +25,15 @@ func Elapsed(what string) func() {
 	}
 }
 
-// RandomInt returns a random number in [min, max)
-func RandomInt(min, max int) int {
-	return rand.Intn(max-min) + min
+// RandomInt returns a random number in [min, max), drawn from r
+func RandomInt(r *rand.Rand, min, max int) int {
+	return r.Intn(max-min) + min
 }
 
-// RandomFloat64 returns a random number in [min, max)
-func RandomFloat64(min, max float64) float64 {
+// RandomFloat64 returns a random number in [min, max), drawn from r
+func RandomFloat64(r *rand.Rand, min, max float64) float64 {
 	// return float64(rand.Int63n(int64(max-min)) + int64(min))
-	return AffineTransform(rand.Float64(), 0, 1, min, max)
+	return AffineTransform(r.Float64(), 0, 1, min, max)
 }
 
 // AffineTransform x (in the range [a, b] to a number in [c, d]
@@ -57,6 +57,11 @@ func D2R(d float64) float64 {
 	return d * math.Pi / 180
 }
 
+// R2D converts radians to degrees
+func R2D(r float64) float64 {
+	return r * 180 / math.Pi
+}
+
 // RotateRect returns a new rect rotated angle degrees
 func RotateRect(r pixel.Rect, angle float64) pixel.Rect {
 
@@ -65,7 +70,7 @@ func RotateRect(r pixel.Rect, angle float64) pixel.Rect {
 	points := r.Vertices()
 
 	var minX, minY float64 = math.MaxFloat64, math.MaxFloat64
-	var maxX, maxY float64
+	var maxX, maxY float64 = -math.MaxFloat64, -math.MaxFloat64
 
 	for _, p := range points {
 		x := center.X + (p.X-center.X)*math.Cos(theta) - (p.Y-center.Y)*math.Sin(theta)
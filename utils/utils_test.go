@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/faiface/pixel"
@@ -24,7 +25,7 @@ func TestRandomInt(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := RandomInt(tt.args.min, tt.args.max)
+			got := RandomInt(rand.New(rand.NewSource(1)), tt.args.min, tt.args.max)
 			if tt.succeed {
 				if got < tt.args.min || got >= tt.args.max {
 					t.Errorf("expected value between %v and %v; got %v", tt.args.min, tt.args.max, got)
@@ -52,7 +53,7 @@ func TestRandomFloat64(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := RandomFloat64(tt.args.min, tt.args.max)
+			got := RandomFloat64(rand.New(rand.NewSource(1)), tt.args.min, tt.args.max)
 			if got < tt.args.min || got >= tt.args.max {
 				t.Errorf("expected value between %v and %v; got %v", tt.args.min, tt.args.max, got)
 			}
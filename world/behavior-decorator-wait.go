@@ -7,7 +7,7 @@ import (
 	"github.com/askft/go-behave/core"
 )
 
-// Delayer waits a specified amount of time and prepends ? to the name to signify the waiting.
+// Delayer waits a specified amount of (world clock) time and prepends ? to the name to signify the waiting.
 func Delayer(params core.Params, child core.Node) core.Node {
 	base := core.NewDecorator("Delayer", params, child)
 	d := &delayer{Decorator: base}
@@ -31,15 +31,19 @@ type delayer struct {
 
 // Enter ...
 func (d *delayer) Enter(ctx *core.Context) {
-	d.start = time.Now()
-	o := ctx.Owner.(Object)
+	d.wait(ctx.Owner.(Object), ctx.Data.(*World).Clock().Now())
+}
+
+// wait starts waiting at start, the name of o shows it is waiting
+func (d *delayer) wait(o Object, start time.Time) {
+	d.start = start
 	d.savedName = o.Name()
 	o.SetName(fmt.Sprintf("[?] %v", d.savedName))
 }
 
 // Tick ...
 func (d *delayer) Tick(ctx *core.Context) core.Status {
-	if time.Since(d.start) > d.delay {
+	if ctx.Data.(*World).Clock().Since(d.start) > d.delay {
 		return core.Update(d.Child, ctx)
 	}
 	return core.StatusRunning
@@ -51,3 +55,16 @@ func (d *delayer) Leave(ctx *core.Context) {
 	o := ctx.Owner.(Object)
 	o.SetName(d.savedName)
 }
+
+// waitOver returns when the delayer waiting in the tree under node is done, zero if none is waiting
+func waitOver(node core.Node) time.Time {
+	if d, ok := node.(*delayer); ok && d.GetStatus() == core.StatusRunning {
+		return d.start.Add(d.delay)
+	}
+	for _, child := range node.GetChildren() {
+		if t := waitOver(child); !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -4,10 +4,11 @@ import (
 	"bytes"
 	"html/template"
 	"log"
+	"math/rand"
+	"time"
 
 	behave "github.com/askft/go-behave"
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
 	"github.com/DanTulovsky/alphaville/utils"
 )
 
@@ -17,6 +18,8 @@ type DefaultBehavior struct {
 	name        string
 	parent      Object
 	t           *behave.BehaviorTree
+	rng         *rand.Rand  // per object random stream, set when the object is added to the world
+	src         *RandSource // source of rng
 }
 
 // NewDefaultBehavior return a DefaultBehavior
@@ -63,11 +66,37 @@ func (b *DefaultBehavior) SetParent(p Object) {
 	b.parent = p
 }
 
+// Rand returns the random source of the behavior
+// Behaviors not (yet) added to a world get a fixed seed, so they are still deterministic.
+func (b *DefaultBehavior) Rand() *rand.Rand {
+	if b.rng == nil {
+		b.SetRand(NewRandSource(1))
+	}
+	return b.rng
+}
+
+// RandSource returns the source of Rand()
+func (b *DefaultBehavior) RandSource() *RandSource {
+	b.Rand()
+	return b.src
+}
+
+// SetRand sets the random source of the behavior
+func (b *DefaultBehavior) SetRand(s *RandSource) {
+	b.src = s
+	b.rng = rand.New(s)
+}
+
 // Description returns the name of the behavior
 func (b *DefaultBehavior) Description() string {
 	return b.description
 }
 
+// Sleepy returns true, the default behavior only reacts to what happens to o, see Sleeper
+func (b *DefaultBehavior) Sleepy(w *World, o Object) (bool, time.Time) {
+	return true, time.Time{}
+}
+
 // Update executes the next world step for the object
 // It updates the NextPhys() of the object for next step based on the encoded behavior
 func (b *DefaultBehavior) Update(w *World, o Object) {
@@ -95,111 +124,195 @@ func (b *DefaultBehavior) Update(w *World, o Object) {
 // ChangeVerticalDirection updates the vertical direction if needed
 func (b *DefaultBehavior) ChangeVerticalDirection(w *World, o Object) bool {
 	phys := o.NextPhys()
-	currentY := phys.Vel().Y
+	currentY := phys.DesiredVel().Y
 
 	if phys.IsAboveGround(w) {
 		// fall speed based on mass and gravity
-		new := phys.Vel()
-		new.Y = w.gravity * phys.CurrentMass()
-		phys.SetVel(new)
-
-		if phys.Vel().X != 0 {
-			v := phys.PreviousVel()
-			v.X = phys.Vel().X
-			phys.SetPreviousVel(v)
-
-			v = phys.Vel()
-			v.X = 0
-			phys.SetVel(v)
-		}
+		d := phys.DesiredVel()
+		d.Y = w.gravity * o.GravityScale() * phys.CurrentMass()
+		phys.SetDesiredVel(d)
+
+		b.stopHorizontal(phys)
 	}
 
 	if phys.IsZeroMass() {
 		// rise speed based on mass and gravity
-		v := phys.Vel()
-		v.Y = -1 * w.gravity * o.Mass()
-		phys.SetVel(v)
-
-		if phys.Vel().X != 0 {
-			v = phys.PreviousVel()
-			v.X = phys.Vel().X
-			phys.SetPreviousVel(v)
-
-			v = phys.Vel()
-			v.X = 0
-			phys.SetVel(v)
-		}
+		d := phys.DesiredVel()
+		d.Y = -1 * w.gravity * o.GravityScale() * o.Mass()
+		phys.SetDesiredVel(d)
+
+		b.stopHorizontal(phys)
 	}
 	// something was changed
-	if currentY != phys.Vel().Y {
+	if currentY != phys.DesiredVel().Y {
 		return true
 	}
 
 	return false
 }
 
+// stopHorizontal stops moving sideways, remembering the direction for later
+func (b *DefaultBehavior) stopHorizontal(phys ObjectPhys) {
+	if phys.DesiredVel().X != 0 {
+		v := phys.PreviousVel()
+		v.X = phys.DesiredVel().X
+		phys.SetPreviousVel(v)
+
+		d := phys.DesiredVel()
+		d.X = 0
+		phys.SetDesiredVel(d)
+	}
+}
+
+// stopHorizontalVel stops o's horizontal movement at once, it is up against border e; it bounces back
+// if the border is bouncy
+func (b *DefaultBehavior) stopHorizontalVel(phys ObjectPhys, e Boundary) {
+	v := phys.Vel()
+	v.X = e.rebound(v.X)
+	phys.SetVel(v)
+}
+
+// bounceOffGround bounces o back up if it lands on the ground and the bottom border is bouncy, fast
+// enough to leave the ground again. It returns false if o lands as usual.
+func (b *DefaultBehavior) bounceOffGround(w *World, o Object) bool {
+	phys := o.NextPhys()
+	if w.Boundaries.Bottom.Mode != BoundaryBounce ||
+		w.landingUnder(o, phys.Bounds(), phys.Vel().Y) > w.groundTop() {
+		return false
+	}
+	v := phys.Vel()
+	if v.Y = w.Boundaries.Bottom.rebound(v.Y); v.Y <= w.MaxAcceleration {
+		return false
+	}
+	phys.SetVel(v)
+	return true
+}
+
+// stopVertical stops o's vertical movement at once, it is up against something
+func (b *DefaultBehavior) stopVertical(phys ObjectPhys) {
+	v := phys.Vel()
+	v.Y = 0
+	phys.SetVel(v)
+
+	d := phys.DesiredVel()
+	d.Y = 0
+	phys.SetDesiredVel(d)
+}
+
 // HandleCollisions returns true if o has any collisions
-// it adjusts the physical properties of o to avoid the collision
+// o and the objects it collides with bounce off each other, see World.ResolveCollisions,
+// o moves up to the first of them, and changes where it wants to go to avoid the collision
 func (b *DefaultBehavior) HandleCollisions(w *World, o Object) bool {
 	phys := o.NextPhys()
 
-	clocations := phys.HaveCollisionsAt(w)
+	collisions := phys.CollisionsAt(w)
+	w.ResolveCollisions(o, collisions)
+	if b.avoidCollisions(w, o, collisions) {
+		phys.MoveToContact(w, collisions)
+		return true
+	}
+	return false
+}
 
-	for _, clocation := range clocations {
+// avoidCollisions returns true if o runs into any of collisions, changing where it wants to go
+func (b *DefaultBehavior) avoidCollisions(w *World, o Object, collisions []Collision) bool {
+	phys := o.NextPhys()
+
+	for _, c := range collisions {
 
 		switch {
-		case phys.MovingDown() && clocation == "below":
-			b.avoidCollisionBelow(phys)
+		case phys.MovingDown() && c.Location == "below":
+			if supports(w, c.Other) {
+				b.landOn(phys, c.Other.Phys().Bounds().Max.Y)
+			} else {
+				b.avoidCollisionBelow(phys)
+			}
 			return true
-		case phys.MovingUp() && clocation == "above":
+		case phys.MovingUp() && c.Location == "above":
 			b.avoidCollisionAbove(phys, w)
 			return true
-		case phys.MovingRight() && clocation == "right":
+		case phys.MovingRight() && c.Location == "right":
 			b.avoidCollisionRight(phys)
 			return true
-		case phys.MovingLeft() && clocation == "left":
+		case phys.MovingLeft() && c.Location == "left":
 			b.avoidCollisionLeft(phys)
 			return true
 		}
 	}
+
+	// running into something sideways on, wait for the bounce
+	for _, c := range collisions {
+		if phys.Vel().Dot(c.Normal(o)) > 0 {
+			return true
+		}
+	}
 	return false
 }
 
+// supports returns true if o can be stood on: a fixture, or an object resting on something itself,
+// not about to rise
+func supports(w *World, o Object) bool {
+	if _, ok := o.(*Fixture); ok {
+		return true
+	}
+	phys := o.Phys()
+	return phys.OnGround(w) && !phys.MovingUp() && !phys.IsZeroMass()
+}
+
+// landOn changes o to stop falling, it lands on top as it would on the ground, and resume the X
+// movement from before
+func (b *DefaultBehavior) landOn(phys ObjectPhys, top float64) {
+	phys.SetLocation(phys.Location().Moved(pixel.V(0, top-phys.Bounds().Min.Y)))
+	b.stopVertical(phys)
+	d := phys.DesiredVel()
+	d.X = phys.PreviousVel().X
+	phys.SetDesiredVel(d)
+}
+
 // avoidCollisionBelow changes o to avoid collision with an object below while moving down
 func (b *DefaultBehavior) avoidCollisionBelow(phys ObjectPhys) {
 
 	// avoid collision by stopping the fall and rising again
 	phys.SetCurrentMass(0)
-	v := phys.Vel()
-	v.Y = 0
-	phys.SetVel(v)
+	b.stopVerticalDrive(phys)
 }
 
 // avoidCollisionAbove changes o to avoid collision with an object above while moving up
 func (b *DefaultBehavior) avoidCollisionAbove(phys ObjectPhys, w *World) {
 
 	phys.SetCurrentMass(phys.ParentObject().Mass())
-	v := phys.Vel()
-	v.Y = 0
-	// if on ground, Y is now 0 and X is 0 from before, reset X movement
+	b.stopVerticalDrive(phys)
+	// if on ground, resume the X movement from before
 	if phys.OnGround(w) {
-		v.X = phys.PreviousVel().X
+		d := phys.DesiredVel()
+		d.X = phys.PreviousVel().X
+		phys.SetDesiredVel(d)
 	}
-	phys.SetVel(v)
 }
 
-// ChangeHorizontalDirection changes the horizontal direction of the object to the opposite of current
+// stopVerticalDrive stops o wanting to move vertically, the collision takes care of its velocity
+func (b *DefaultBehavior) stopVerticalDrive(phys ObjectPhys) {
+	d := phys.DesiredVel()
+	d.Y = 0
+	phys.SetDesiredVel(d)
+}
+
+// ChangeHorizontalDirection turns the object around horizontally
 func (b *DefaultBehavior) ChangeHorizontalDirection(phys ObjectPhys) {
 	v := phys.Vel()
-	v.X = -1 * v.X
-	phys.SetVel(v)
+	d := phys.DesiredVel()
+	if d.X*v.X > 0 {
+		// still heading the way it was moving
+		d.X = -1 * d.X
+		phys.SetDesiredVel(d)
+	}
 }
 
 // avoidHorizontalCollision changes the object to avoid a horizontal collision
 func (b *DefaultBehavior) avoidHorizontalCollision(phys ObjectPhys) {
 
 	// Going to bump, 50/50 chance of rising up or changing direction
-	if utils.RandomInt(0, 100) > 50 {
+	if utils.RandomInt(b.Rand(), 0, 100) > 50 {
 		phys.SetCurrentMass(0)
 	} else {
 		b.ChangeHorizontalDirection(phys)
@@ -224,27 +337,33 @@ func (b *DefaultBehavior) Move(w *World, o Object, v pixel.Vec) {
 	mv := phys.CollisionBordersVector(w, v)
 
 	// TODO: Clean this so code is not duplicated with above function
+	bs := w.Boundaries
 	switch {
-	case phys.MovingLeft() && phys.Location().Min.X+phys.Vel().X <= 0:
+	case phys.MovingLeft() && phys.Bounds().Min.X+phys.Vel().X <= 0 && bs.Left.blocks():
 		// left border
 		b.ChangeHorizontalDirection(phys)
+		b.stopHorizontalVel(phys, bs.Left)
 
-	case phys.MovingRight() && phys.Location().Max.X+phys.Vel().X >= w.X:
+	case phys.MovingRight() && phys.Bounds().Max.X+phys.Vel().X >= w.X && bs.Right.blocks():
 		// right border
 		b.ChangeHorizontalDirection(phys)
+		b.stopHorizontalVel(phys, bs.Right)
 
-	case phys.MovingDown() && phys.Location().Min.Y+phys.Vel().Y < w.Ground.Phys().Location().Max.Y:
-		// stop at ground level
-		v := phys.Vel()
-		v.Y = 0
-		v.X = phys.PreviousVel().X
-		phys.SetVel(v)
-
-	case phys.MovingUp() && phys.Location().Max.Y+phys.Vel().Y >= w.Y && phys.Vel().Y > 0:
-		// stop at ceiling if going up
-		v := phys.Vel()
-		v.Y = 0
-		phys.SetVel(v)
+	case phys.MovingDown() && phys.Bounds().Min.Y+phys.Vel().Y < w.landingUnder(o, phys.Bounds(), phys.Vel().Y):
+		if b.bounceOffGround(w, o) {
+			break
+		}
+		// stop at ground level (or on whatever is below), and resume the X movement from before
+		b.stopVertical(phys)
+		d := phys.DesiredVel()
+		d.X = phys.PreviousVel().X
+		phys.SetDesiredVel(d)
+
+	case phys.MovingUp() && phys.Bounds().Max.Y+phys.Vel().Y >= w.Y && phys.Vel().Y > 0 && bs.Top.blocks():
+		// stop at ceiling if going up, or bounce off it
+		vy := bs.Top.rebound(phys.Vel().Y)
+		b.stopVertical(phys)
+		phys.SetVel(pixel.V(phys.Vel().X, vy))
 		phys.SetCurrentMass(o.Mass())
 
 	}
@@ -252,7 +371,7 @@ func (b *DefaultBehavior) Move(w *World, o Object, v pixel.Vec) {
 }
 
 // Draw draws any artifacts of the behavior
-func (b *DefaultBehavior) Draw(win *pixelgl.Window) {
+func (b *DefaultBehavior) Draw(r Renderer, alpha float64) {
 
 }
 
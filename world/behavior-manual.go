@@ -1,8 +1,9 @@
 package world
 
 import (
+	"time"
+
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
 )
 
 // ManualBehavior is human controlled
@@ -24,11 +25,22 @@ func NewManualBehavior() *ManualBehavior {
 func (b *ManualBehavior) Update(w *World, o Object) {
 	phys := o.NextPhys()
 
-	if len(phys.HaveCollisionsAt(w)) == 0 {
-		b.Move(w, o, phys.CollisionBordersVector(w, phys.Vel()))
+	collisions := phys.CollisionsAt(w)
+	w.ResolveCollisions(o, collisions)
+	if len(collisions) == 0 {
+		mv := phys.CollisionBordersVector(w, phys.Vel())
+		w.rebound(phys)
+		b.Move(w, o, mv)
+	} else {
+		phys.MoveToContact(w, collisions)
 	}
 }
 
+// Sleepy returns false, a human may take over at any time
+func (b *ManualBehavior) Sleepy(w *World, o Object) (bool, time.Time) {
+	return false, time.Time{}
+}
+
 // Move moves the object
 func (b *ManualBehavior) Move(w *World, o Object, v pixel.Vec) {
 	newLocation := o.NextPhys().Location().Moved(pixel.V(v.X, v.Y))
@@ -36,6 +48,6 @@ func (b *ManualBehavior) Move(w *World, o Object, v pixel.Vec) {
 }
 
 // Draw draws any artifacts of the behavior
-func (b *ManualBehavior) Draw(win *pixelgl.Window) {
+func (b *ManualBehavior) Draw(r Renderer, alpha float64) {
 
 }
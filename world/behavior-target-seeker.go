@@ -9,13 +9,19 @@ import (
 	"time"
 
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
 	"github.com/DanTulovsky/alphaville/graph"
 	"github.com/DanTulovsky/alphaville/observer"
 	"github.com/DanTulovsky/alphaville/utils"
 	"golang.org/x/image/colornames"
 )
 
+const (
+	// replanAhead is how many nodes of its path a seeker checks for kinematic fixtures moving into it
+	replanAhead = 3
+	// replanLookahead is how many ticks of their motion the path keeps clear of kinematic fixtures
+	replanLookahead = 30
+)
+
 // TargetSeekerBehavior moves in shortest path to the target
 type TargetSeekerBehavior struct {
 	DefaultBehavior
@@ -29,9 +35,10 @@ type TargetSeekerBehavior struct {
 	finder          PathFinder // path finder function
 	turnsAtLocation int        // number of turns at current location
 	targetsCaught   int64
+	exits           bool // head for the closest sink instead of a target
 
-	// TODO: Change this to be based on expected steps rather than wall time
-	targetAcquireTime    time.Time     // when this target was acquired
+	targetAcquireTime    time.Time     // when this target was acquired (world clock)
+	targetChaseTime      time.Duration // time spent chasing the current target, as of the last update
 	maxTargetAcquireTime time.Duration // max allowed time to get to the target
 }
 
@@ -42,14 +49,31 @@ func NewTargetSeekerBehavior(f PathFinder) *TargetSeekerBehavior {
 			name:        "target_seeker",
 			description: "Travels in shortest path to target, if given, otherwise stands still.",
 		},
-		finder:               f,
-		maxTargetAcquireTime: time.Second * time.Duration(utils.RandomInt(10, 20)),
+		finder: f,
 	}
 }
 
+// NewExitSeekerBehavior return a TargetSeekerBehavior that heads for the closest sink to leave the world
+func NewExitSeekerBehavior(f PathFinder) *TargetSeekerBehavior {
+	return &TargetSeekerBehavior{
+		DefaultBehavior: DefaultBehavior{
+			name:        "exit_seeker",
+			description: "Travels in shortest path to the closest exit and leaves the world through it.",
+		},
+		finder: f,
+		exits:  true,
+	}
+}
+
+// SetRand sets the random source of the behavior and picks the time allowed to catch each target
+func (b *TargetSeekerBehavior) SetRand(s *RandSource) {
+	b.DefaultBehavior.SetRand(s)
+	b.maxTargetAcquireTime = time.Second * time.Duration(utils.RandomInt(b.Rand(), 10, 20))
+}
+
 // RemainingTargetAcquireTime returns the remaining time to catch a target
 func (b *TargetSeekerBehavior) RemainingTargetAcquireTime() time.Duration {
-	return (b.maxTargetAcquireTime - time.Since(b.targetAcquireTime)).Round(time.Millisecond)
+	return (b.maxTargetAcquireTime - b.targetChaseTime).Round(time.Millisecond)
 }
 
 // MaxTargetAcquireTime returns the max time allowed to catch a target
@@ -101,6 +125,15 @@ func (b *TargetSeekerBehavior) populateMoveGraph(w *World) *Tree {
 	// they are grown by 1/2 size of object on each side to account for movement
 	cobjects, _ := w.CollisionObjectsExclude(b.parent) // must be all for now
 
+	rects := make([]pixel.Rect, len(cobjects))
+	for i, o := range cobjects {
+		rects[i] = o.Phys().Bounds()
+		// kinematic fixtures are in the way wherever they go next, not just where they are
+		if f, ok := o.(*Fixture); ok && f.Motion() != nil {
+			rects[i] = f.Motion().Bounds(f.origin, f.ticks, replanLookahead)
+		}
+	}
+
 	phys := b.parent.Phys()
 
 	// add start and target to the quadtree
@@ -120,6 +153,7 @@ func (b *TargetSeekerBehavior) populateMoveGraph(w *World) *Tree {
 	// Use own quadtree
 	/////////////////////////////////////
 	cobjects = append(cobjects, &startObj, &targetObj)
+	rects = append(rects, start, target)
 
 	// minimum size of rectangle side at which we stop splitting
 	// based on the size of the target seeker
@@ -129,10 +163,12 @@ func (b *TargetSeekerBehavior) populateMoveGraph(w *World) *Tree {
 	qtBounds := pixel.R(
 		w.Ground.Phys().Location().Min.X+phys.Location().W()/2, w.Ground.Phys().Location().Max.Y+phys.Location().H()/2,
 		w.X-phys.Location().W()/2, w.Y-phys.Location().H()/2)
-	qt, err := NewTree(qtBounds, cobjects, minSize, phys.Location().Size())
+	qt, err := newTreeOf(qtBounds, cobjects, rects, minSize, phys.Location().Size())
 	if err != nil {
 		log.Fatalf("error creating quadtree: %v", err)
 	}
+	// the centers of objects wrapping around go from one edge of qtBounds to the other
+	qt.Wrap(w.Boundaries.WrapX(), w.Boundaries.WrapY())
 
 	startNode, err := qt.Locate(startObj.Phys().Location().Center())
 	if err != nil {
@@ -175,29 +211,44 @@ func (b *TargetSeekerBehavior) TurnsBlocked() int {
 }
 
 // isAtTarget returns true if any part of the object covers the target
-func (b *TargetSeekerBehavior) isAtTarget(o Object) bool {
+// The target is claimed once all objects are updated, other seekers may be at it as well.
+func (b *TargetSeekerBehavior) isAtTarget(w *World, o Object) bool {
 
 	// if utils.VecLen(o.Phys().Location().Center(), b.target.Bounds().Center()) < o.Speed() {
 	if o.Phys().Location().Contains(b.target.Location()) {
-
-		o.Notify(NewObjectEvent(
-			fmt.Sprintf("[%v] found target [%v]", o.Name(), b.target.Name()), time.Now(),
-			observer.EventData{Key: "target_found", Value: b.target.Name()}))
-		b.target.Destroy()
-		b.target = nil
-
+		if b.exits {
+			// wait for the sink to take us, see World.ExitObjects
+			return true
+		}
+		t := b.target
+		w.Defer(o, func() { b.catchTarget(w, o, t) })
 		return true
 	}
 	return false
 }
 
+// catchTarget destroys t, unless another seeker caught it first
+func (b *TargetSeekerBehavior) catchTarget(w *World, o Object, t Target) {
+	b.target = nil
+	if !t.Available() {
+		return
+	}
+
+	now := w.Clock().Now()
+	o.Notify(NewObjectEvent(
+		fmt.Sprintf("[%v] found target [%v]", o.Name(), t.Name()), now,
+		observer.EventData{Key: "target_found", Value: t.Name()}))
+	t.Destroy(now)
+	b.targetsCaught++
+}
+
 // Direction returns the next direction to travel and the target itself
 func (b *TargetSeekerBehavior) Direction(w *World, o Object) (pixel.Vec, pixel.Vec) {
 	// remove the current location from path
 	// circle := pixel.C(o.Phys().Location().Center(), o.Speed()*2)
 
 	// for len(b.path) > 0 && circle.Contains(b.path[0].Value().V) {
-	for len(b.path) > 0 && utils.VecLen(o.Phys().Location().Center(), b.path[0].Bounds().Center()) < o.Speed() {
+	for len(b.path) > 0 && b.delta(w, o, o.Phys().Location().Center(), b.path[0].Bounds().Center()).Len() < o.Speed() {
 		// if len(b.path) > 0 && o.Phys().Location().Contains(b.path[0].Value().V) {
 
 		b.source = b.path[0].Bounds().Center()
@@ -209,10 +260,10 @@ func (b *TargetSeekerBehavior) Direction(w *World, o Object) (pixel.Vec, pixel.V
 		return pixel.ZV, pixel.V(0, 0)
 	}
 	source := b.source
-	// target is the next node in the path
-	target := b.path[0].Bounds().Center()
+	// target is the next node in the path, on the same side of the world as source if it wraps around
+	target := b.nearest(w, o, source, b.path[0].Bounds().Center())
 	// current location of target seeker
-	c := o.Phys().Location().Center()
+	c := b.nearest(w, o, source, o.Phys().Location().Center())
 
 	// log.Printf("From: %v; To: %v\n", source, target)
 	// log.Printf("  Current location: %v (%v)", o.Phys().Location(), o.Phys().Location().Center())
@@ -311,14 +362,25 @@ func (b *TargetSeekerBehavior) Direction(w *World, o Object) (pixel.Vec, pixel.V
 	}
 
 	if len(moves) > 0 {
-		return moves[utils.RandomInt(0, len(moves))], target
+		return moves[utils.RandomInt(b.Rand(), 0, len(moves))], target
 	}
 
-	o.SetManualVelocity(pixel.ZV)
+	o.NextPhys().SetManualVelocity(pixel.ZV)
 	return pixel.ZV, pixel.ZV
 
 }
 
+// nearest returns p, or p across the edges of the world if they wrap around and that is closer to
+// from, both centers of o
+func (b *TargetSeekerBehavior) nearest(w *World, o Object, from, p pixel.Vec) pixel.Vec {
+	return nearest(from, p, w.wrapSize(o.Phys().Location().Size()))
+}
+
+// delta returns the shortest way for o from a to c, across the edges of the world if they wrap around
+func (b *TargetSeekerBehavior) delta(w *World, o Object, a, c pixel.Vec) pixel.Vec {
+	return b.nearest(w, o, a, c).Sub(a)
+}
+
 // FindPath returns the path and cost between start and target
 func (b *TargetSeekerBehavior) FindPath(start, target pixel.Vec) (NodeList, int, error) {
 
@@ -338,16 +400,38 @@ func (b *TargetSeekerBehavior) FindAndSetNewTarget(w *World, o Object) error {
 	var t Target
 	var err error
 
-	if t, err = w.GetTarget(); err != nil {
+	if b.exits {
+		if t, err = w.GetExit(o); err != nil {
+			return fmt.Errorf("error picking exit: %v", err)
+		}
+		b.SetTarget(t)
+		b.recalculateMoveInfo(w, o)
+		b.targetAcquireTime = w.Clock().Now()
+		b.targetChaseTime = 0
+		return nil
+	}
+
+	if t, err = w.GetTarget(b.Rand()); err != nil {
 		return fmt.Errorf("error picking target: %v", err)
 	}
 
-	t.Register(b)
 	b.SetTarget(t)
+	// registering changes the target, wait until all objects are updated
+	w.Defer(o, func() {
+		if !t.Available() {
+			// caught by another seeker this tick
+			if b.target == t {
+				b.target = nil
+			}
+			return
+		}
+		t.Register(b)
+	})
 	// log.Printf("[%v] target [%v] acquired", b.parent.Name(), t.ID())
 
 	b.recalculateMoveInfo(w, o)
-	b.targetAcquireTime = time.Now()
+	b.targetAcquireTime = w.Clock().Now()
+	b.targetChaseTime = 0
 
 	return nil
 }
@@ -405,6 +489,32 @@ func (b *TargetSeekerBehavior) recalculateMoveInfo(w *World, o Object) {
 	b.source = phys.Location().Center()
 }
 
+// pathBlocked returns true if a kinematic fixture moved into the next few nodes of the path
+func (b *TargetSeekerBehavior) pathBlocked(w *World, o Object) bool {
+	c := o.Phys().Location().Center()
+	size := o.Phys().Location().Size()
+	for _, f := range w.moving {
+		// grown by the size of o, like the fixtures in the graph
+		fb := f.Phys().Bounds()
+		fb = fb.Resized(fb.Center(), fb.Size().Add(size))
+		for i, n := range b.path {
+			if i == replanAhead {
+				break
+			}
+			if nb := n.Bounds(); !nb.Contains(c) && nb.Intersect(fb).Area() > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Sleepy returns true if there is no target to hunt, a new one wakes the object up.
+// Exit seekers don't sleep, sinks open and free up by themselves.
+func (b *TargetSeekerBehavior) Sleepy(w *World, o Object) (bool, time.Time) {
+	return b.target == nil && !b.exits, time.Time{}
+}
+
 // Update implements the Behavior Update method
 // In this method, execute the planned move in the NextPhys object
 // If unable to do so due to any reason, change the Velocity, but do not move
@@ -420,14 +530,14 @@ func (b *TargetSeekerBehavior) Update(w *World, o Object) {
 		return
 	}
 
-	if b.isAtTarget(o) {
-		b.targetsCaught++
+	if b.isAtTarget(w, o) {
 		return
 	}
 
-	// If too much wall clock time has passed, give up on this target and find another one
-	if time.Since(b.targetAcquireTime) > b.maxTargetAcquireTime {
-		log.Printf("[%v] Time spent (%v) to catch [%v] expired (max %v), trying another target...", b.parent.Name(), time.Since(b.targetAcquireTime), b.target.Name(), b.maxTargetAcquireTime)
+	// If too much time has passed, give up on this target and find another one
+	b.targetChaseTime = w.Clock().Since(b.targetAcquireTime)
+	if b.targetChaseTime > b.maxTargetAcquireTime {
+		log.Printf("[%v] Time spent (%v) to catch [%v] expired (max %v), trying another target...", b.parent.Name(), b.targetChaseTime, b.target.Name(), b.maxTargetAcquireTime)
 		if err := b.FindAndSetNewTarget(w, o); err != nil {
 			log.Printf("... but failed to find new target: %v", err)
 		}
@@ -439,20 +549,27 @@ func (b *TargetSeekerBehavior) Update(w *World, o Object) {
 		if b.turnsAtLocation%35 == 0 {
 			b.recalculateMoveInfo(w, o)
 		}
+	} else if b.pathBlocked(w, o) {
+		b.recalculateMoveInfo(w, o)
 	}
 
-	if len(phys.HaveCollisionsAt(w)) == 0 && !(phys.Vel() == pixel.ZV) {
+	collisions := phys.CollisionsAt(w)
+	if len(collisions) == 0 && !(phys.Vel() == pixel.ZV) {
 		// move, checking collisions with world borders
-		b.Move(w, o, phys.CollisionBordersVector(w, phys.Vel()))
+		mv := phys.CollisionBordersVector(w, phys.Vel())
+		w.rebound(phys)
+		b.Move(w, o, mv)
 		b.turnsAtLocation = 0
 	} else {
+		w.ResolveCollisions(o, collisions)
+		phys.MoveToContact(w, collisions)
 		b.turnsAtLocation++
 	}
 
 	// unable to move via path for a long time, try random walk
 	if b.turnsAtLocation > 50 {
-		randx := float64(utils.RandomInt(-1, 2))
-		randy := float64(utils.RandomInt(-1, 2))
+		randx := float64(utils.RandomInt(b.Rand(), -1, 2))
+		randy := float64(utils.RandomInt(b.Rand(), -1, 2))
 		// if randx != 0 {
 		// 	randy = 0
 		// }
@@ -467,12 +584,12 @@ func (b *TargetSeekerBehavior) Update(w *World, o Object) {
 
 	// if moving takes us further away from the target than we currently are
 	// just move directly on top of the target, if possible
-	currentDistance := utils.VecLen(phys.Location().Center(), target)
-	newDistance := utils.VecLen(phys.Location().Moved(phys.Vel()).Center(), target)
+	currentDistance := b.delta(w, o, phys.Location().Center(), target).Len()
+	newDistance := b.delta(w, o, phys.Location().Moved(phys.DesiredVel()).Center(), target).Len()
 
 	if newDistance > currentDistance {
-		v := target.Sub(phys.Location().Center())
-		phys.SetVel(v)
+		v := b.delta(w, o, phys.Location().Center(), target)
+		phys.SetDesiredVel(v)
 	}
 }
 
@@ -483,30 +600,30 @@ func (b *TargetSeekerBehavior) Move(w *World, o Object, v pixel.Vec) {
 }
 
 // Draw draws any artifacts of the behavior
-func (b *TargetSeekerBehavior) Draw(win *pixelgl.Window) {
+func (b *TargetSeekerBehavior) Draw(r Renderer, alpha float64) {
 	if b.target == nil {
 		return
 	}
 
 	// draw the quadtree
 	// drawTree, colorTree, drawText, drawObjects := true, true, false, true
-	// b.qt.Draw(win, drawTree, colorTree, drawText, drawObjects)
+	// b.qt.Draw(r, drawTree, colorTree, drawText, drawObjects)
 
 	pathColor := b.parent.Color()
 
 	if len(b.path) > 0 {
 		// draw the path from current location
-		l := b.parent.Phys().Location().Center()
+		l := Interpolate(b.parent, alpha).Center()
 		drawPath := make([]pixel.Vec, len(b.path)+2)
 		drawPath[0] = l
 		for i := 0; i < len(b.path); i++ {
 			drawPath[i+1] = b.path[i].Bounds().Center()
 		}
 		drawPath[len(drawPath)-1] = b.target.Bounds().Center()
-		DrawPath(win, drawPath, pathColor)
+		DrawPath(r, drawPath, pathColor)
 	}
 	// draw the full path
-	// DrawPath(win, b.fullpath, pathColor)
+	// DrawPath(r, b.fullpath, pathColor)
 }
 
 // Implement the EventObserver interface
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"html/template"
 	"log"
+	"time"
 
 	behave "github.com/askft/go-behave"
 	"github.com/askft/go-behave/core"
@@ -76,3 +77,8 @@ func (b *WondererBehavior) Update(w *World, o Object) {
 	b.t.Update()
 	// util.PrintTreeInColor(b.t.Root)
 }
+
+// Sleepy returns true, wonderers sleep while they think about what to do next, until their wait is over
+func (b *WondererBehavior) Sleepy(w *World, o Object) (bool, time.Time) {
+	return true, waitOver(b.t.Root)
+}
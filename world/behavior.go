@@ -1,17 +1,21 @@
 package world
 
 import (
+	"math/rand"
+
 	behave "github.com/askft/go-behave"
-	"github.com/faiface/pixel/pixelgl"
 )
 
 // Behavior is the interface for all behaviors
 type Behavior interface {
 	Description() string
-	Draw(win *pixelgl.Window)
+	Draw(Renderer, float64) // float64 is the interpolation factor between Phys() and NextPhys()
 	Name() string
 	Parent() Object
+	Rand() *rand.Rand
+	RandSource() *RandSource
 	SetParent(Object)
+	SetRand(*RandSource)
 	Tree() *behave.BehaviorTree
 	Update(*World, Object)
 }
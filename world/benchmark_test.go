@@ -0,0 +1,268 @@
+package world
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+// benchSizes are the number of objects in the benchmark worlds
+var benchSizes = []int{10, 100, 1000}
+
+const (
+	benchCell   = 60 // each object or fixture gets a cell this size
+	benchGround = 40
+)
+
+// newEmptyBenchWorld returns a headless world of size x, y with nothing in it but the ground
+func newEmptyBenchWorld(x, y float64) *World {
+	ground := NewGroundObject("ground", colornames.White, 0, 0, x, benchGround)
+	ground.SetPhys(NewBaseObjectPhys(pixel.R(0, 0, x, benchGround), ground))
+	ground.SetNextPhys(ground.Phys().Copy())
+
+	w := NewWorld(x, y, ground, -2, 4, 1, &DebugConfig{}, nil)
+	// the default stats console is stdout, keep the benchmark output clean
+	w.Stats.console = struct {
+		io.Reader
+		io.Writer
+	}{strings.NewReader(""), io.Discard}
+	return w
+}
+
+// newBenchWorld returns a headless world with n spawned objects and n/10 (at least 1) fixtures laid out
+// on a grid, moving in random directions
+func newBenchWorld(b *testing.B, n int) *World {
+	b.Helper()
+
+	fixtures := n/10 + 1
+	cols := int(math.Ceil(math.Sqrt(float64(n + fixtures))))
+	rows := (n + fixtures + cols - 1) / cols
+	x, y := float64(cols*benchCell), float64(rows*benchCell+benchGround)
+
+	w := newEmptyBenchWorld(x, y)
+	r := w.NewRand()
+
+	for i := 0; i < n+fixtures; i++ {
+		c := pixel.V(float64(i%cols)*benchCell+benchCell/2, float64(i/cols)*benchCell+benchCell/2+benchGround)
+
+		if i%(n/fixtures+1) == 0 && len(w.Fixtures()) < fixtures {
+			f := NewFixture(fmt.Sprintf("f-%v", i), colornames.Gray, 40, 40)
+			f.Place(c.Sub(pixel.V(20, 20)))
+			if err := w.AddFixture(f); err != nil {
+				b.Fatalf("failed to add fixture: %v", err)
+			}
+			continue
+		}
+
+		var o Object
+		switch i % 3 {
+		case 0:
+			o = NewRectObject(fmt.Sprintf("%v", i), colornames.Red, 2, 1, 20, 20, nil)
+		case 1:
+			o = NewCircleObject(fmt.Sprintf("%v", i), colornames.Green, 2, 1, 10, nil)
+		default:
+			o = NewEllipseObject(fmt.Sprintf("%v", i), colornames.Blue, 2, 1, 14, 10, nil)
+		}
+		if err := w.AddObject(o); err != nil {
+			b.Fatalf("failed to add object: %v", err)
+		}
+
+		// spawn in place, gates would take a long time to spawn this many
+		phys := NewBaseObjectPhys(o.BoundingBox(c), o)
+		phys.SetVel(pixel.V(r.Float64()*4-2, r.Float64()*4-2))
+		phys.SetCurrentMass(o.Mass())
+		o.SetPhys(phys)
+		o.SetNextPhys(phys.Copy())
+		o.SetSpawnTime(w.Clock().Now())
+		if err := w.qt.Insert(o); err != nil {
+			b.Fatalf("failed to add object to the quadtree: %v", err)
+		}
+	}
+	if got := len(w.SpawnedObjects()); got != n {
+		b.Fatalf("expected %v spawned objects, got %v", n, got)
+	}
+	return w
+}
+
+// newRestingBenchWorld returns a headless world with n spawned objects standing still in stacks on the
+// ground, run until they had time to fall asleep (if sleepAfter isn't 0)
+func newRestingBenchWorld(b *testing.B, n, sleepAfter int) *World {
+	b.Helper()
+
+	const side = 20
+	cols := int(math.Ceil(math.Sqrt(float64(n))))
+	rows := (n + cols - 1) / cols
+	w := newEmptyBenchWorld(float64(cols*benchCell), float64(benchGround+rows*side+benchCell))
+	w.SleepAfter = sleepAfter
+
+	for i := 0; i < n; i++ {
+		c := pixel.V(float64(i%cols)*benchCell+benchCell/2, float64(benchGround+(i/cols)*side+side/2))
+		o := NewRectObject(fmt.Sprintf("%v", i), colornames.Red, 0, 1, side, side, nil)
+		if err := w.AddObject(o); err != nil {
+			b.Fatalf("failed to add object: %v", err)
+		}
+
+		phys := NewBaseObjectPhys(o.BoundingBox(c), o)
+		phys.SetCurrentMass(o.Mass())
+		o.SetPhys(phys)
+		o.SetNextPhys(phys.Copy())
+		o.SetSpawnTime(w.Clock().Now())
+		if err := w.qt.Insert(o); err != nil {
+			b.Fatalf("failed to add object to the quadtree: %v", err)
+		}
+	}
+
+	for i := 0; i <= sleepAfter; i++ {
+		w.Update()
+		w.NextTick()
+	}
+	if sleepAfter > 0 && w.Sleeping() != n {
+		b.Fatalf("expected all %v objects asleep, %v are", n, w.Sleeping())
+	}
+	return w
+}
+
+// benchPoints returns random points in the world, above the ground
+func benchPoints(w *World, n int) []pixel.Vec {
+	r := rand.New(rand.NewSource(1))
+	points := make([]pixel.Vec, n)
+	for i := range points {
+		points[i] = pixel.V(r.Float64()*w.X, benchGround+r.Float64()*(w.Y-benchGround))
+	}
+	return points
+}
+
+func BenchmarkNewTree(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("objects=%v", n), func(b *testing.B) {
+			w := newBenchWorld(b, n)
+			cobjects, _ := w.CollisionObjects()
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := NewTree(pixel.R(0, 0, w.X, w.Y), cobjects, w.MinObjectSide, pixel.ZV); err != nil {
+					b.Fatalf("failed to create tree: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkTree_Locate(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("objects=%v", n), func(b *testing.B) {
+			w := newBenchWorld(b, n)
+			points := benchPoints(w, 1024)
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := w.QuadTree().Locate(points[i%len(points)]); err != nil {
+					b.Fatalf("failed to locate: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNode_Neighbors(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("objects=%v", n), func(b *testing.B) {
+			w := newBenchWorld(b, n)
+			leaves := w.QuadTree().Leaves
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				leaves[i%len(leaves)].Neighbors()
+			}
+		})
+	}
+}
+
+func BenchmarkDijkstraPathFinder_Path(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("objects=%v", n), func(b *testing.B) {
+			w := newBenchWorld(b, n)
+			qt := w.QuadTree()
+
+			// corner to corner, through the gaps between the objects.
+			// Like target seekers, go from the center of the start node to the center of the target node.
+			locate := func(pt pixel.Vec) pixel.Vec {
+				node, err := qt.Locate(pt)
+				if err != nil {
+					b.Fatalf("failed to locate: %v", err)
+				}
+				node.SetColor(colornames.White)
+				return node.Bounds().Center()
+			}
+			start := locate(pixel.V(1, benchGround+1))
+			target := locate(pixel.V(w.X-1, w.Y-1))
+
+			finder := &DijkstraPathFinder{}
+			if _, _, err := finder.Path(qt, start, target); err != nil {
+				b.Fatalf("no path: %v", err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				finder.Path(qt, start, target)
+			}
+		})
+	}
+}
+
+func BenchmarkBaseObjectPhys_HaveCollisionsAt(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("objects=%v", n), func(b *testing.B) {
+			w := newBenchWorld(b, n)
+			objects := w.SpawnedObjects()
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				objects[i%len(objects)].NextPhys().HaveCollisionsAt(w)
+			}
+		})
+	}
+}
+
+func BenchmarkWorld_UpdateNextTick(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("objects=%v", n), func(b *testing.B) {
+			w := newBenchWorld(b, n)
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				w.Update()
+				w.NextTick()
+			}
+		})
+	}
+}
+
+func BenchmarkWorld_UpdateResting(b *testing.B) {
+	for _, n := range benchSizes {
+		for _, sleepAfter := range []int{0, DefaultSleepAfter} {
+			b.Run(fmt.Sprintf("objects=%v/sleep_after=%v", n, sleepAfter), func(b *testing.B) {
+				w := newRestingBenchWorld(b, n, sleepAfter)
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					w.Update()
+					w.NextTick()
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package world
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Each edge of the world has a boundary mode, what happens to objects that run into it:
+//
+//   - solid: they stop at the edge, objects with the default behavior turn around
+//   - bounce: they bounce back off it, keeping Restitution of their speed
+//   - wrap: they come back into the world through the opposite edge, which must wrap too
+//   - open: they leave the world through it, and are removed once they are all the way out
+//
+// The bottom edge is the top of the ground; objects don't stand on the ground if the bottom edge
+// wraps around or is open, they fall through it.
+// Objects wrapping around stay in the world as a whole: one whose front reaches an edge comes back
+// in with its back at the opposite edge, as if the world (less the size of the object) were a torus.
+// They only do if there is room for them on the other side, otherwise the edge is solid for now.
+
+// BoundaryMode is what happens to objects at an edge of the world
+type BoundaryMode string
+
+const (
+	// BoundarySolid stops objects at the edge
+	BoundarySolid BoundaryMode = "solid"
+	// BoundaryBounce bounces objects back off the edge
+	BoundaryBounce BoundaryMode = "bounce"
+	// BoundaryWrap brings objects back in through the opposite edge
+	BoundaryWrap BoundaryMode = "wrap"
+	// BoundaryOpen lets objects leave the world
+	BoundaryOpen BoundaryMode = "open"
+)
+
+// Boundary is an edge of the world, the zero Boundary is solid
+type Boundary struct {
+	Mode        BoundaryMode
+	Restitution float64 // bounce, how much of its speed an object keeps bouncing off the edge, 0 to 1
+}
+
+// Boundaries are the edges of the world, all solid by default
+type Boundaries struct {
+	Left, Right, Bottom, Top Boundary
+}
+
+// NewBoundaries returns boundaries with all four edges the same
+func NewBoundaries(b Boundary) Boundaries {
+	return Boundaries{Left: b, Right: b, Bottom: b, Top: b}
+}
+
+// Validate returns an error if the boundaries make no sense
+func (bs Boundaries) Validate() error {
+	for _, e := range []struct {
+		name string
+		b    Boundary
+	}{{"left", bs.Left}, {"right", bs.Right}, {"bottom", bs.Bottom}, {"top", bs.Top}} {
+		switch e.b.Mode {
+		case "", BoundarySolid, BoundaryWrap, BoundaryOpen:
+		case BoundaryBounce:
+			if e.b.Restitution < 0 || e.b.Restitution > 1 {
+				return fmt.Errorf("%v boundary: restitution %v not in [0, 1]", e.name, e.b.Restitution)
+			}
+		default:
+			return fmt.Errorf("%v boundary: unknown mode %q, want solid, bounce, wrap or open", e.name, e.b.Mode)
+		}
+	}
+	if (bs.Left.Mode == BoundaryWrap) != (bs.Right.Mode == BoundaryWrap) {
+		return fmt.Errorf("the left and right boundaries must both wrap, or neither")
+	}
+	if (bs.Bottom.Mode == BoundaryWrap) != (bs.Top.Mode == BoundaryWrap) {
+		return fmt.Errorf("the bottom and top boundaries must both wrap, or neither")
+	}
+	return nil
+}
+
+// WrapX returns true if objects leaving through the left or right edge come back through the other
+func (bs Boundaries) WrapX() bool {
+	return bs.Left.Mode == BoundaryWrap
+}
+
+// WrapY returns true if objects leaving through the bottom or top edge come back through the other
+func (bs Boundaries) WrapY() bool {
+	return bs.Bottom.Mode == BoundaryWrap
+}
+
+// wrapSize returns how far the center of an object of size s goes to come around the world back to
+// where it was, horizontally and vertically, 0 if the world doesn't wrap around that way
+func (w *World) wrapSize(s pixel.Vec) pixel.Vec {
+	var size pixel.Vec
+	if w.Boundaries.WrapX() {
+		size.X = w.X - s.X
+	}
+	if w.Boundaries.WrapY() {
+		size.Y = w.Y - w.groundTop() - s.Y
+	}
+	return size
+}
+
+// nearest returns p, or p moved around the world (by wrap, see wrapSize) if that is closer to from
+func nearest(from, p, wrap pixel.Vec) pixel.Vec {
+	if d := p.X - from.X; wrap.X > 0 && math.Abs(d) > wrap.X/2 {
+		p.X -= math.Copysign(wrap.X, d)
+	}
+	if d := p.Y - from.Y; wrap.Y > 0 && math.Abs(d) > wrap.Y/2 {
+		p.Y -= math.Copysign(wrap.Y, d)
+	}
+	return p
+}
+
+// blocks returns true if objects stop at the edge, solid or bouncy
+func (b Boundary) blocks() bool {
+	return b.Mode != BoundaryWrap && b.Mode != BoundaryOpen
+}
+
+// rebound returns the velocity, along the normal of the edge, of an object running into it at v
+func (b Boundary) rebound(v float64) float64 {
+	if b.Mode == BoundaryBounce {
+		return -b.Restitution * v
+	}
+	return 0
+}
+
+// floor returns the height objects stand on when there is nothing else under them: the top of the
+// ground, or -Inf if the bottom edge wraps around or is open
+func (w *World) floor() float64 {
+	if !w.Boundaries.Bottom.blocks() {
+		return math.Inf(-1)
+	}
+	return w.groundTop()
+}
+
+// crossBorder returns how o, at r, moves by vel across an edge of the world: it stops at the edge (by
+// stop), unless the edge wraps around and there is room for o on the other side (it moves by vel and
+// shift), or it is open (it moves by vel)
+func (w *World) crossBorder(o Object, r pixel.Rect, vel pixel.Vec, b Boundary, stop, shift pixel.Vec) pixel.Vec {
+	switch b.Mode {
+	case BoundaryOpen:
+		return vel
+	case BoundaryWrap:
+		if mv := vel.Add(shift); w.roomFor(o, r.Moved(mv)) {
+			return mv
+		}
+	}
+	return stop
+}
+
+// roomFor returns true if nothing but o is in r, or moves into it this tick
+func (w *World) roomFor(o Object, r pixel.Rect) bool {
+	for _, other := range w.CollisionObjectsIn(r) {
+		phys := other.Phys()
+		if other.ID() != o.ID() && sweep(phys.Bounds(), phys.Vel()).Intersect(r).Area() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// rebound bounces phys back off the bouncy edges of the world it runs into this tick, for behaviors
+// that don't turn around at the edges themselves
+func (w *World) rebound(phys ObjectPhys) {
+	r, v := phys.Bounds(), phys.Vel()
+	bs := w.Boundaries
+	switch {
+	case v.X < 0 && r.Min.X+v.X <= 0 && bs.Left.Mode == BoundaryBounce:
+		v.X = bs.Left.rebound(v.X)
+	case v.X > 0 && r.Max.X+v.X >= w.X && bs.Right.Mode == BoundaryBounce:
+		v.X = bs.Right.rebound(v.X)
+	}
+	switch {
+	case v.Y < 0 && r.Min.Y+v.Y < w.groundTop() && bs.Bottom.Mode == BoundaryBounce:
+		v.Y = bs.Bottom.rebound(v.Y)
+	case v.Y > 0 && r.Max.Y+v.Y >= w.Y && bs.Top.Mode == BoundaryBounce:
+		v.Y = bs.Top.rebound(v.Y)
+	}
+	phys.SetVel(v)
+}
+
+// outside returns true if r is all the way out of the world through an open edge
+func (w *World) outside(r pixel.Rect) bool {
+	bs := w.Boundaries
+	return bs.Left.Mode == BoundaryOpen && r.Max.X <= 0 ||
+		bs.Right.Mode == BoundaryOpen && r.Min.X >= w.X ||
+		bs.Bottom.Mode == BoundaryOpen && r.Max.Y <= w.groundTop() ||
+		bs.Top.Mode == BoundaryOpen && r.Min.Y >= w.Y
+}
+
+// RemoveDepartedObjects removes all objects that left the world through an open edge
+func (w *World) RemoveDepartedObjects() {
+	for _, o := range w.SpawnedObjects() {
+		if w.outside(o.Phys().Bounds()) {
+			if err := w.removeObject(o, "left"); err != nil {
+				log.Fatalf("error removing departed object: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package world
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+func TestBoundaries_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		b    Boundaries
+		err  string
+	}{
+		{name: "solid", b: Boundaries{}},
+		{name: "all wrap", b: NewBoundaries(Boundary{Mode: BoundaryWrap})},
+		{
+			name: "mixed",
+			b: Boundaries{
+				Left:   Boundary{Mode: BoundaryWrap},
+				Right:  Boundary{Mode: BoundaryWrap},
+				Bottom: Boundary{Mode: BoundaryBounce, Restitution: 0.5},
+				Top:    Boundary{Mode: BoundaryOpen},
+			},
+		},
+		{name: "unknown mode", b: Boundaries{Top: Boundary{Mode: "sticky"}}, err: "unknown mode"},
+		{name: "restitution", b: Boundaries{Left: Boundary{Mode: BoundaryBounce, Restitution: 1.5}}, err: "restitution"},
+		{name: "wrap one side", b: Boundaries{Left: Boundary{Mode: BoundaryWrap}}, err: "left and right"},
+		{name: "wrap the top", b: Boundaries{Top: Boundary{Mode: BoundaryWrap}, Bottom: Boundary{Mode: BoundaryOpen}}, err: "bottom and top"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.b.Validate()
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("expected error containing %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+// wrapX wraps the world around horizontally, leaving the bottom and top solid
+var wrapX = Boundaries{Left: Boundary{Mode: BoundaryWrap}, Right: Boundary{Mode: BoundaryWrap}}
+
+// jumped returns true if the object went further than step in one tick somewhere along trail
+func jumped(trail []ObjectPhys, step float64) bool {
+	for i := 1; i < len(trail); i++ {
+		if trail[i].Location().Min.To(trail[i-1].Location().Min).Len() > step {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWorld_Boundaries(t *testing.T) {
+	faller := func(t *testing.T, w *World) Object {
+		o := NewRectObject("faller", colornames.Red, 0, 1, 20, 20, nil)
+		addRestingObject(t, w, o, pixel.V(100, 200))
+		return o
+	}
+	carAt := func(c pixel.Vec) func(t *testing.T, w *World) Object {
+		return func(t *testing.T, w *World) Object { return addCar(t, w, c) }
+	}
+
+	tests := []struct {
+		name       string
+		boundaries Boundaries
+		setup      func(t *testing.T, w *World) Object
+		ticks      int
+		check      func(w *World, o Object, trail []ObjectPhys) bool
+		want       string
+	}{
+		{
+			name:  "solid",
+			setup: carAt(pixel.V(760, 50)),
+			ticks: 60,
+			check: func(w *World, o Object, trail []ObjectPhys) bool {
+				last := trail[len(trail)-1]
+				return !jumped(trail, 4) && last.Location().Max.X < 790 && last.DesiredVel().X < 0
+			},
+			want: "to turn around at the right edge",
+		},
+		{
+			name:       "wrap",
+			boundaries: wrapX,
+			setup:      carAt(pixel.V(760, 50)),
+			ticks:      60,
+			check: func(w *World, o Object, trail []ObjectPhys) bool {
+				last := trail[len(trail)-1]
+				for _, phys := range trail {
+					if r := phys.Location(); r.Min.X < 0 || r.Max.X > w.X {
+						return false
+					}
+				}
+				return jumped(trail, 700) && last.Location().Min.X < 200 && last.DesiredVel().X > 0
+			},
+			want: "to come back in on the left, heading on",
+		},
+		{
+			name:       "wrap blocked",
+			boundaries: wrapX,
+			setup: func(t *testing.T, w *World) Object {
+				addRestingObject(t, w, NewRectObject("box", colornames.Gray, 0, 1, 20, 20, nil), pixel.V(10, 50))
+				return addCar(t, w, pixel.V(760, 50))
+			},
+			ticks: 60,
+			check: func(w *World, o Object, trail []ObjectPhys) bool {
+				return !jumped(trail, 4) && trail[len(trail)-1].Location().Max.X > w.X-1 && w.Stats.Overlaps == 0
+			},
+			want: "to wait at the right edge",
+		},
+		{
+			name: "bounce",
+			boundaries: Boundaries{
+				Left:  Boundary{Mode: BoundaryBounce, Restitution: 0.9},
+				Right: Boundary{Mode: BoundaryBounce, Restitution: 0.9},
+			},
+			setup: carAt(pixel.V(760, 50)),
+			ticks: 60,
+			check: func(w *World, o Object, trail []ObjectPhys) bool {
+				for _, phys := range trail {
+					if phys.Location().Max.X >= w.X-1e-9 {
+						return phys.Vel().X < -1.5
+					}
+				}
+				return false
+			},
+			want: "to bounce back off the right edge at once",
+		},
+		{
+			name:       "bounce off the ground",
+			boundaries: Boundaries{Bottom: Boundary{Mode: BoundaryBounce, Restitution: 0.9}},
+			setup: func(t *testing.T, w *World) Object {
+				// heavy, to fall fast enough to bounce high
+				o := NewRectObject("faller", colornames.Red, 0, 2, 20, 20, nil)
+				addRestingObject(t, w, o, pixel.V(100, 200))
+				return o
+			},
+			ticks: 200,
+			check: func(w *World, o Object, trail []ObjectPhys) bool {
+				landed := false
+				for _, phys := range trail {
+					if phys.Location().Min.Y <= w.groundTop()+1e-9 {
+						landed = true
+					}
+					if landed && phys.Location().Min.Y > w.groundTop()+3 {
+						return true
+					}
+				}
+				return false
+			},
+			want: "to bounce up off the ground",
+		},
+		{
+			name:       "open",
+			boundaries: Boundaries{Right: Boundary{Mode: BoundaryOpen}},
+			setup:      carAt(pixel.V(760, 50)),
+			ticks:      60,
+			check: func(w *World, o Object, trail []ObjectPhys) bool {
+				return trail[len(trail)-1].Location().Max.X > w.X && len(w.Objects) == 0 && w.Stats.ObjectsLeft == 1
+			},
+			want: "to leave the world through the right edge",
+		},
+		{
+			name:       "open bottom",
+			boundaries: Boundaries{Bottom: Boundary{Mode: BoundaryOpen}},
+			setup:      faller,
+			ticks:      200,
+			check: func(w *World, o Object, trail []ObjectPhys) bool {
+				return trail[len(trail)-1].Location().Min.Y < w.groundTop() && len(w.Objects) == 0 && w.Stats.ObjectsLeft == 1
+			},
+			want: "to fall through the ground and out",
+		},
+		{
+			name:       "wrap vertically",
+			boundaries: Boundaries{Bottom: Boundary{Mode: BoundaryWrap}, Top: Boundary{Mode: BoundaryWrap}},
+			setup:      faller,
+			ticks:      200,
+			check: func(w *World, o Object, trail []ObjectPhys) bool {
+				for _, phys := range trail {
+					if r := phys.Location(); r.Min.Y < w.groundTop() || r.Max.Y > w.Y {
+						return false
+					}
+				}
+				return jumped(trail, 400) && len(w.Objects) == 1
+			},
+			want: "to fall through the ground and come back in at the top",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			w.Boundaries = tt.boundaries
+			o := tt.setup(t, w)
+
+			var trail []ObjectPhys
+			for i := 0; i < tt.ticks; i++ {
+				w.Step()
+				if !o.IsSpawned() {
+					break
+				}
+				trail = append(trail, o.Phys().Copy())
+			}
+			if len(trail) == 0 {
+				t.Fatalf("expected the object in the world for a while")
+			}
+			if !tt.check(w, o, trail) {
+				t.Errorf("expected the object %v, it ended up at %v", tt.want, trail[len(trail)-1].Location())
+			}
+		})
+	}
+}
+
+func TestInterpolate_Wrap(t *testing.T) {
+	tests := []struct {
+		name       string
+		boundaries Boundaries
+		setup      func(t *testing.T, w *World) Object
+		heading    pixel.Vec
+	}{
+		{
+			name:       "right to left",
+			boundaries: wrapX,
+			setup:      func(t *testing.T, w *World) Object { return addCar(t, w, pixel.V(760, 50)) },
+			heading:    pixel.V(1, 0),
+		},
+		{
+			name:       "bottom to top",
+			boundaries: Boundaries{Bottom: Boundary{Mode: BoundaryWrap}, Top: Boundary{Mode: BoundaryWrap}},
+			setup: func(t *testing.T, w *World) Object {
+				o := NewRectObject("faller", colornames.Red, 0, 1, 20, 20, nil)
+				addRestingObject(t, w, o, pixel.V(100, 200))
+				return o
+			},
+			heading: pixel.V(0, -1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			w.Boundaries = tt.boundaries
+			o := tt.setup(t, w)
+
+			for i := 0; i < 200; i++ {
+				w.Step()
+				current, next := o.Phys().Location(), o.NextPhys().Location()
+				if current.Min.To(next.Min).Len() < 300 {
+					continue
+				}
+
+				// it wraps around this tick, it is drawn going off the edge it is heading for
+				for _, alpha := range []float64{0.25, 0.5, 0.75} {
+					d := Interpolate(o, alpha).Min.Sub(current.Min)
+					if d.Dot(tt.heading) < 0 || d.Len() > 20 {
+						t.Errorf("expected %v drawn moving a little %v from %v, got %v", o.Name(), tt.heading, current, d)
+					}
+				}
+				return
+			}
+			t.Fatalf("expected %v to wrap around", o.Name())
+		})
+	}
+}
+
+func TestTree_Wrap(t *testing.T) {
+	// a wall down the middle, from the bottom to the top
+	wall := NewFixture("wall", colornames.Gray, 40, 600)
+	wall.Place(pixel.V(380, 0))
+	start, target := pixel.V(100, 300), pixel.V(700, 300)
+
+	tests := []struct {
+		name     string
+		wrap     bool
+		path     bool
+		maxCost  int
+		neighbor bool
+	}{
+		{name: "walled off", wrap: false, path: false},
+		{name: "around the world", wrap: true, path: true, maxCost: 450, neighbor: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt, err := NewTree(pixel.R(0, 0, 800, 600), []Object{wall}, 20, pixel.ZV)
+			if err != nil {
+				t.Fatalf("failed to create tree: %v", err)
+			}
+			qt.Wrap(tt.wrap, false)
+
+			west, err := qt.Locate(pixel.V(1, 300))
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			east, err := qt.Locate(pixel.V(799, 300))
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			found := false
+			for _, n := range qt.Neighbors(west) {
+				found = found || n == east
+			}
+			if found != tt.neighbor {
+				t.Errorf("expected the east edge a neighbour of the west edge %v, got %v", tt.neighbor, found)
+			}
+
+			if got := qt.Delta(start, target); tt.wrap && got != pixel.V(-200, 0) || !tt.wrap && got != pixel.V(600, 0) {
+				t.Errorf("unexpected delta from %v to %v: %v", start, target, got)
+			}
+
+			s, _ := qt.Locate(start)
+			e, _ := qt.Locate(target)
+			path, cost, err := (&DijkstraPathFinder{}).Path(qt, s.Bounds().Center(), e.Bounds().Center())
+			if got := err == nil && len(path) > 0; got != tt.path {
+				t.Fatalf("expected a path %v, got %v (%v)", tt.path, path, err)
+			}
+			if tt.path && cost > tt.maxCost {
+				t.Errorf("expected the path around the world, at most %v long, got %v", tt.maxCost, cost)
+			}
+		})
+	}
+}
+
+func TestTree_WrapUpdates(t *testing.T) {
+	qt, err := NewTree(pixel.R(0, 0, 800, 600), nil, 20, pixel.ZV)
+	if err != nil {
+		t.Fatalf("failed to create tree: %v", err)
+	}
+	qt.Wrap(true, false)
+
+	// a box on the east edge splits the leaf there
+	box := NewFixture("box", colornames.Gray, 40, 40)
+	box.Place(pixel.V(760, 280))
+	if err := qt.Insert(box); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	west, err := qt.Locate(pixel.V(1, 300))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	across := 0
+	for _, n := range qt.Neighbors(west) {
+		if !qt.Leaves.contains(n) {
+			t.Errorf("expected only leaves of the tree as neighbours, got %v", n.Bounds())
+		}
+		if n.Bounds().Max.X == 800 {
+			across++
+			if n.Color() != colornames.White || n.Bounds().Intersect(box.Phys().Bounds()).Area() > 0 {
+				t.Errorf("expected the box not a neighbour, got %v", n.Bounds())
+			}
+		}
+	}
+	if across == 0 {
+		t.Errorf("expected the west edge next to the east edge still")
+	}
+}
+
+func TestTargetSeekerBehavior_WrapsAround(t *testing.T) {
+	w := newTestWorld(1)
+	w.Boundaries = wrapX
+	wall := NewFixture("wall", colornames.Gray, 40, 560)
+	wall.Place(pixel.V(380, 40))
+	if err := w.AddFixture(wall); err != nil {
+		t.Fatalf("failed to add fixture: %v", err)
+	}
+
+	b := NewTargetSeekerBehavior(&DijkstraPathFinder{})
+	o := NewRectObject("seeker", colornames.Red, 2, 1, 20, 20, b)
+	addRestingObject(t, w, o, pixel.V(100, 50))
+	if err := w.AddTarget(NewSimpleTarget("target", pixel.V(700, 50), 5, "target")); err != nil {
+		t.Fatalf("failed to add target: %v", err)
+	}
+
+	jumps := 0
+	for i := 0; i < 1000 && b.TargetsCaught() == 0; i++ {
+		before := o.Phys().Location()
+		w.Step()
+		if before.Min.X-o.Phys().Location().Min.X < -700 {
+			jumps++
+		}
+	}
+	if b.TargetsCaught() != 1 {
+		t.Fatalf("expected the seeker to catch the target, it is at %v", o.Phys().Location())
+	}
+	if jumps != 1 {
+		t.Errorf("expected the seeker to go around the world once, it went %v times", jumps)
+	}
+}
@@ -0,0 +1,100 @@
+package world
+
+import (
+	"time"
+)
+
+// DefaultTickDuration is the simulated time that passes in one tick (60 updates per second)
+const DefaultTickDuration = time.Second / 60
+
+// Clock keeps time in the world. The world advances it by one tick in NextTick().
+// Everything that waits (gate cool downs, delays, time limits) should ask the world's
+// clock, not time.Now(), so that it behaves the same regardless of machine speed.
+type Clock interface {
+	Now() time.Time
+	Since(time.Time) time.Duration
+	Tick()
+	TickDuration() time.Duration
+	Ticks() int64
+}
+
+// SimClock is a Clock driven purely by ticks; each tick advances time by a fixed amount,
+// no matter how long it took to compute. Pausing, fast forwarding and headless runs all
+// see the same time.
+type SimClock struct {
+	start        time.Time
+	ticks        int64
+	tickDuration time.Duration
+}
+
+// NewSimClock returns a new simulated clock, each tick is d long
+func NewSimClock(d time.Duration) *SimClock {
+	return &SimClock{
+		start:        time.Unix(0, 0).UTC(),
+		tickDuration: d,
+	}
+}
+
+// Now returns the simulated time
+func (c *SimClock) Now() time.Time {
+	return c.start.Add(time.Duration(c.ticks) * c.tickDuration)
+}
+
+// Since returns the simulated time elapsed since t
+func (c *SimClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Tick advances the clock by one tick
+func (c *SimClock) Tick() {
+	c.ticks++
+}
+
+// TickDuration returns the simulated duration of one tick
+func (c *SimClock) TickDuration() time.Duration {
+	return c.tickDuration
+}
+
+// Ticks returns the number of ticks so far
+func (c *SimClock) Ticks() int64 {
+	return c.ticks
+}
+
+// WallClock is a Clock that follows real time; ticks are only counted.
+// This is how the world used to behave, time depends on how fast the machine runs.
+type WallClock struct {
+	ticks        int64
+	tickDuration time.Duration
+}
+
+// NewWallClock returns a new wall clock, d is the nominal length of a tick
+func NewWallClock(d time.Duration) *WallClock {
+	return &WallClock{
+		tickDuration: d,
+	}
+}
+
+// Now returns the current wall time
+func (c *WallClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since returns the wall time elapsed since t
+func (c *WallClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// Tick counts one more tick
+func (c *WallClock) Tick() {
+	c.ticks++
+}
+
+// TickDuration returns the nominal duration of one tick
+func (c *WallClock) TickDuration() time.Duration {
+	return c.tickDuration
+}
+
+// Ticks returns the number of ticks so far
+func (c *WallClock) Ticks() int64 {
+	return c.ticks
+}
@@ -0,0 +1,22 @@
+package world
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimClock(t *testing.T) {
+	c := NewSimClock(time.Millisecond * 10)
+	start := c.Now()
+
+	for i := 0; i < 100; i++ {
+		c.Tick()
+	}
+
+	if c.Ticks() != 100 {
+		t.Errorf("expected 100 ticks, got %v", c.Ticks())
+	}
+	if got := c.Since(start); got != time.Second {
+		t.Errorf("expected %v to pass, got %v", time.Second, got)
+	}
+}
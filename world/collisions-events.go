@@ -0,0 +1,53 @@
+package world
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/DanTulovsky/alphaville/observer"
+	"github.com/faiface/pixel"
+)
+
+// Kinds of collision events
+const (
+	CollisionContact = "contact" // two objects ran into each other and bounced
+	CollisionOverlap = "overlap" // two objects ended up overlapping, see CheckIntersect
+)
+
+// CollisionEvent is sent when objects collide. Its data has:
+//
+//	kind: CollisionContact or CollisionOverlap
+//	object, other: the ids of the two objects
+//	side: where other is, as seen from object (above, below, left, right), "" if unknown
+//	normal: the unit vector from object towards other, see FormatVec
+//	relative_velocity: the velocity of object relative to other, before they bounce
+//	tick: the world tick the collision happened at
+type CollisionEvent struct {
+	observer.BaseEvent
+}
+
+// NewCollisionEvent create a new collision event
+func NewCollisionEvent(d string, t time.Time, data ...observer.EventData) observer.Event {
+	e := &CollisionEvent{}
+	e.SetData(data)
+	e.SetDescription(d)
+	e.SetTime(t)
+
+	return e
+}
+
+// notifyCollision sends a CollisionEvent about o and other to the observers of the world.
+// Not safe to call while objects are updating, see Defer.
+func (w *World) notifyCollision(kind string, o, other Object, side string, n, rel pixel.Vec) {
+	w.Wake(o)
+	w.Wake(other)
+	w.Notify(NewCollisionEvent(fmt.Sprintf("%v: [%v] and [%v]", kind, o.Name(), other.Name()), w.clock.Now(),
+		observer.EventData{Key: "kind", Value: kind},
+		observer.EventData{Key: "object", Value: o.ID().String()},
+		observer.EventData{Key: "other", Value: other.ID().String()},
+		observer.EventData{Key: "side", Value: side},
+		observer.EventData{Key: "normal", Value: FormatVec(n)},
+		observer.EventData{Key: "relative_velocity", Value: FormatVec(rel)},
+		observer.EventData{Key: "tick", Value: strconv.FormatInt(w.clock.Ticks(), 10)}))
+}
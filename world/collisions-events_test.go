@@ -0,0 +1,51 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/DanTulovsky/alphaville/observer"
+	"github.com/faiface/pixel"
+	"github.com/go-test/deep"
+	"golang.org/x/image/colornames"
+)
+
+func TestWorld_CollisionEvents(t *testing.T) {
+	w := newTestWorld(1)
+	w.Drag, w.Friction, w.MaxAcceleration = 0, 0, 0 // just the collision
+	events := &eventLog{}
+	w.Register(events)
+
+	mover := NewRectObject("mover", colornames.Red, 0, 1, 20, 20, NewManualBehavior())
+	target := NewRectObject("target", colornames.Blue, 0, 1, 20, 20, NewManualBehavior())
+	placeObject(t, w, mover, pixel.V(100, 200))
+	placeObject(t, w, target, pixel.V(200, 200))
+	mover.NextPhys().SetVel(pixel.V(2, 0))
+
+	for i := 0; i < 100; i++ {
+		w.Update()
+		w.NextTick()
+	}
+
+	var got [][]observer.EventData
+	for _, e := range events.events {
+		if c, ok := e.(*CollisionEvent); ok {
+			got = append(got, c.Data())
+		}
+	}
+	// they touch after 40 ticks, and bounce apart once
+	want := [][]observer.EventData{{
+		{Key: "kind", Value: CollisionContact},
+		{Key: "object", Value: mover.ID().String()},
+		{Key: "other", Value: target.ID().String()},
+		{Key: "side", Value: "right"},
+		{Key: "normal", Value: "1,0"},
+		{Key: "relative_velocity", Value: "2,0"},
+		{Key: "tick", Value: "40"},
+	}}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("expected collision events %v, got %v: %v", want, got, diff)
+	}
+	if w.Stats.Collisions != 1 || w.Stats.Overlaps != 0 {
+		t.Errorf("expected 1 collision and no overlaps, got %v and %v", w.Stats.Collisions, w.Stats.Overlaps)
+	}
+}
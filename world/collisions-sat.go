@@ -0,0 +1,155 @@
+package world
+
+import (
+	"math"
+	"sort"
+
+	"github.com/faiface/pixel"
+)
+
+// ellipseSides is the number of sides of the polygon standing in for a rotated ellipse
+const ellipseSides = 16
+
+// Polygon returns the corners of the rotated shape, counterclockwise. Rects are their oriented
+// bounding box, ellipses and circles the smallest ellipseSides sided polygon around them.
+// The polygons are symmetric around their center.
+func (b Body) Polygon() []pixel.Vec {
+	c := b.Rect.Center()
+	half := radii(b.Rect)
+
+	var corners []pixel.Vec
+	switch b.Shape {
+	case ShapeRect:
+		corners = []pixel.Vec{
+			pixel.V(-half.X, -half.Y), pixel.V(half.X, -half.Y), pixel.V(half.X, half.Y), pixel.V(-half.X, half.Y),
+		}
+	default:
+		grow := 1 / math.Cos(math.Pi/ellipseSides) // the sides touch the ellipse
+		for i := 0; i < ellipseSides; i++ {
+			sin, cos := math.Sincos(2 * math.Pi * float64(i) / ellipseSides)
+			corners = append(corners, pixel.V(half.X*cos*grow, half.Y*sin*grow))
+		}
+	}
+
+	for i, p := range corners {
+		corners[i] = p.Rotated(b.Angle).Add(c)
+	}
+	return corners
+}
+
+// axes returns the normals of the sides of polygon p, only half of them, the others are parallel
+func axes(p []pixel.Vec) []pixel.Vec {
+	axes := make([]pixel.Vec, 0, len(p)/2)
+	for i := 0; i < len(p)/2; i++ {
+		axes = append(axes, p[(i+1)%len(p)].Sub(p[i]).Normal().Unit())
+	}
+	return axes
+}
+
+// project returns the interval polygon p covers along axis
+func project(p []pixel.Vec, axis pixel.Vec) (min, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, v := range p {
+		x := v.Dot(axis)
+		min, max = math.Min(min, x), math.Max(max, x)
+	}
+	return min, max
+}
+
+// sweepPolygons returns the contact of convex polygon p1, moving by d, with p2, using the separating
+// axis test: they overlap while their projections overlap on the normals of all their sides.
+func sweepPolygons(p1 []pixel.Vec, d pixel.Vec, p2 []pixel.Vec) (Contact, bool) {
+	enter, exit := math.Inf(-1), math.Inf(1)
+	var normal pixel.Vec
+
+	// already overlapping, the side they overlap the least along
+	depth := math.Inf(1)
+	var push pixel.Vec
+
+	for _, axis := range append(axes(p1), axes(p2)...) {
+		min1, max1 := project(p1, axis)
+		min2, max2 := project(p2, axis)
+		speed := d.Dot(axis)
+
+		if min1 < max2 && min2 < max1 {
+			if overlap := math.Min(max1-min2, max2-min1); overlap < depth {
+				depth = overlap
+				push = axis
+				if max2-min1 < max1-min2 {
+					push = axis.Scaled(-1) // p2 is behind p1 along axis
+				}
+			}
+		}
+
+		if speed == 0 {
+			if !(min1 < max2 && min2 < max1) {
+				return Contact{}, false // separated along axis for good
+			}
+			continue
+		}
+
+		// when p1 starts and stops overlapping p2 along axis
+		tIn, tOut := (min2-max1)/speed, (max2-min1)/speed
+		n := axis
+		if speed < 0 {
+			tIn, tOut = tOut, tIn
+			n = axis.Scaled(-1)
+		}
+		if tIn > enter {
+			enter = tIn
+			normal = n
+		}
+		exit = math.Min(exit, tOut)
+	}
+
+	if enter < 0 && exit > 0 || math.IsInf(enter, -1) {
+		if math.IsInf(depth, 1) {
+			return Contact{}, false
+		}
+		return Contact{T: 0, Point: contactPoint(p1, p2, push), Normal: push}, true
+	}
+	if enter >= exit || enter < 0 || enter > 1 {
+		return Contact{}, false
+	}
+
+	moved := make([]pixel.Vec, len(p1))
+	for i, v := range p1 {
+		moved[i] = v.Add(d.Scaled(enter))
+	}
+	return Contact{T: enter, Point: contactPoint(moved, p2, normal), Normal: normal}, true
+}
+
+// contactPoint returns where p1 touches p2, n is the normal from p1 towards p2.
+// A corner going into a side is the corner, two sides touching the middle of where they do.
+func contactPoint(p1, p2 []pixel.Vec, n pixel.Vec) pixel.Vec {
+	const eps = 1e-6
+
+	support := func(p []pixel.Vec, dir pixel.Vec) []pixel.Vec {
+		best := math.Inf(-1)
+		for _, v := range p {
+			best = math.Max(best, v.Dot(dir))
+		}
+		points := []pixel.Vec{}
+		for _, v := range p {
+			if v.Dot(dir) > best-eps {
+				points = append(points, v)
+			}
+		}
+		return points
+	}
+
+	s1 := support(p1, n)
+	s2 := support(p2, n.Scaled(-1))
+	switch {
+	case len(s1) == 1:
+		return s1[0]
+	case len(s2) == 1:
+		return s2[0]
+	}
+
+	// side to side, the middle two of the four ends along the side
+	ends := append(s1[:2:2], s2[:2]...)
+	tangent := n.Normal()
+	sort.Slice(ends, func(i, j int) bool { return ends[i].Dot(tangent) < ends[j].Dot(tangent) })
+	return ends[1].Add(ends[2]).Scaled(0.5)
+}
@@ -0,0 +1,127 @@
+package world
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestBody_Bounds(t *testing.T) {
+	tests := []struct {
+		name string
+		b    Body
+		want pixel.Rect
+	}{
+		{name: "not rotated", b: Body{Shape: ShapeRect, Rect: pixel.R(-10, -5, 10, 5)}, want: pixel.R(-10, -5, 10, 5)},
+		{name: "rect on end", b: Body{Shape: ShapeRect, Rect: pixel.R(-10, -5, 10, 5), Angle: math.Pi / 2}, want: pixel.R(-5, -10, 5, 10)},
+		{name: "rect on a corner", b: Body{Shape: ShapeRect, Rect: pixel.R(-10, -10, 10, 10), Angle: math.Pi / 4}, want: pixel.R(-10*math.Sqrt2, -10*math.Sqrt2, 10*math.Sqrt2, 10*math.Sqrt2)},
+		{name: "ellipse on end", b: Body{Shape: ShapeEllipse, Rect: ellipseBox(pixel.V(100, 100), pixel.V(20, 10)), Angle: math.Pi / 2}, want: ellipseBox(pixel.V(100, 100), pixel.V(10, 20))},
+		{name: "circles don't change", b: Body{Shape: ShapeCircle, Rect: circleBox(pixel.V(100, 100), 10), Angle: 1}, want: circleBox(pixel.V(100, 100), 10)},
+	}
+
+	const tolerance = 1e-9
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.b.Bounds()
+			if got.Min.Sub(tt.want.Min).Len() > tolerance || got.Max.Sub(tt.want.Max).Len() > tolerance {
+				t.Errorf("expected bounds %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestShapeCollision_Rotated(t *testing.T) {
+	diamond := func(c pixel.Vec, v pixel.Vec) Body {
+		return Body{Shape: ShapeRect, Rect: pixel.R(c.X-10, c.Y-10, c.X+10, c.Y+10), Angle: math.Pi / 4, Vel: v}
+	}
+	tip := 10 * math.Sqrt2 // from the center of a diamond to its corners
+
+	tests := []struct {
+		name   string
+		b1, b2 Body
+		want   bool
+		c      Contact
+	}{
+		{
+			name: "corner into a side",
+			b1:   diamond(pixel.ZV, pixel.V(10, 0)),
+			b2:   Body{Shape: ShapeRect, Rect: pixel.R(20, -20, 40, 20)},
+			want: true,
+			c:    Contact{T: (20 - tip) / 10, Point: pixel.V(20, 0), Normal: pixel.V(1, 0)},
+		},
+		{
+			name: "side into a corner",
+			b1:   Body{Shape: ShapeRect, Rect: pixel.R(20, -20, 40, 20), Vel: pixel.V(-10, 0)},
+			b2:   diamond(pixel.ZV, pixel.ZV),
+			want: true,
+			c:    Contact{T: (20 - tip) / 10, Point: pixel.V(tip, 0), Normal: pixel.V(-1, 0)},
+		},
+		{
+			name: "diamonds, only the bounds overlap",
+			b1:   diamond(pixel.ZV, pixel.ZV),
+			b2:   diamond(pixel.V(25, 25), pixel.ZV),
+			want: false,
+		},
+		{
+			name: "diamonds, side to side",
+			b1:   diamond(pixel.ZV, pixel.V(20, 20)),
+			b2:   diamond(pixel.V(25, 25), pixel.ZV),
+			want: true,
+			c:    Contact{T: (50 - 2*tip) / 40, Point: pixel.V((50-tip)/2, (50-tip)/2), Normal: pixel.V(1, 1).Unit()},
+		},
+		{
+			name: "already overlapping",
+			b1:   diamond(pixel.ZV, pixel.ZV),
+			b2:   Body{Shape: ShapeRect, Rect: pixel.R(10, -5, 30, 5)},
+			want: true,
+			c:    Contact{T: 0, Point: pixel.V(tip, 0), Normal: pixel.V(1, 0)},
+		},
+		{
+			name: "circle into a corner",
+			b1:   Body{Shape: ShapeCircle, Rect: circleBox(pixel.ZV, 10), Vel: pixel.V(20, 0)},
+			b2:   diamond(pixel.V(40, 0), pixel.ZV),
+			want: true,
+			c:    Contact{T: (30 - tip) / 20, Point: pixel.V(40-tip, 0), Normal: pixel.V(1, 0)},
+		},
+		{
+			name: "rotated circles are still circles",
+			b1:   Body{Shape: ShapeCircle, Rect: circleBox(pixel.ZV, 10), Angle: 1, Vel: pixel.V(20, 0)},
+			b2:   Body{Shape: ShapeCircle, Rect: circleBox(pixel.V(30, 0), 10), Angle: 2},
+			want: true,
+			c:    Contact{T: 0.5, Point: pixel.V(20, 0), Normal: pixel.V(1, 0)},
+		},
+		{
+			name: "ellipse on end into a rect",
+			b1:   Body{Shape: ShapeEllipse, Rect: ellipseBox(pixel.ZV, pixel.V(20, 10)), Angle: math.Pi / 2, Vel: pixel.V(10, 0)},
+			b2:   Body{Shape: ShapeRect, Rect: pixel.R(15, -50, 35, 50)},
+			want: true,
+			// the polygon around the ellipse sticks out a little
+			c: Contact{T: (15 - 10/math.Cos(math.Pi/ellipseSides)) / 10, Point: pixel.V(15, 0), Normal: pixel.V(1, 0)},
+		},
+		{
+			name: "moving apart",
+			b1:   diamond(pixel.ZV, pixel.V(-10, 0)),
+			b2:   Body{Shape: ShapeRect, Rect: pixel.R(20, -20, 40, 20)},
+			want: false,
+		},
+	}
+
+	const tolerance = 1e-4
+	near := func(a, b pixel.Vec) bool { return a.Sub(b).Len() < tolerance }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := ShapeCollision(tt.b1, tt.b2)
+			if ok != tt.want {
+				t.Fatalf("expected collision: %v, got %v (%+v)", tt.want, ok, c)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(c.T-tt.c.T) > tolerance || !near(c.Point, tt.c.Point) || !near(c.Normal, tt.c.Normal) {
+				t.Errorf("expected contact %+v, got %+v", tt.c, c)
+			}
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package world
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+
+	"github.com/DanTulovsky/alphaville/utils"
+)
+
+// Shape is the outline of an object, it fills its bounding box
+type Shape int
+
+// The shapes of objects, circles and ellipses touch the sides of their bounding box
+const (
+	ShapeRect Shape = iota
+	ShapeCircle
+	ShapeEllipse
+)
+
+const (
+	// contactTolerance is how close (in units of the first shape's radius) ellipses have to get to touch
+	contactTolerance = 1e-6
+	// maxContactSteps limits the iterations looking for the time ellipses touch
+	maxContactSteps = 64
+	// contactSkin is how far short of a contact objects moving up to it stop
+	contactSkin = 1e-6
+)
+
+// Contact is where two moving shapes first touch
+type Contact struct {
+	T      float64   // fraction of the move when they touch, 0 if they already overlap
+	Point  pixel.Vec // where they touch, at T
+	Normal pixel.Vec // unit vector from the first shape towards the second one, at Point
+}
+
+// Body is a shape in the world, moving
+type Body struct {
+	Shape Shape
+	Rect  pixel.Rect // bounding box of the shape before rotating it
+	Angle float64    // radians, counterclockwise around the center of Rect
+	Vel   pixel.Vec
+}
+
+// Bounds returns the bounding box of the rotated shape
+func (b Body) Bounds() pixel.Rect {
+	if b.Angle == 0 || b.Shape == ShapeCircle {
+		return b.Rect
+	}
+
+	switch b.Shape {
+	case ShapeEllipse:
+		e := radii(b.Rect)
+		sin, cos := math.Sincos(b.Angle)
+		half := pixel.V(math.Hypot(e.X*cos, e.Y*sin), math.Hypot(e.X*sin, e.Y*cos))
+		c := b.Rect.Center()
+		return pixel.Rect{Min: c.Sub(half), Max: c.Add(half)}
+	}
+	return utils.RotateRect(b.Rect, utils.R2D(b.Angle))
+}
+
+// ShapeCollision is the narrow phase of the collision detection, see HaveCollisions for the broad one.
+// It returns the first contact between b1 and b2 while they move, and false if they don't touch.
+// Shapes just touching don't collide.
+func ShapeCollision(b1, b2 Body) (Contact, bool) {
+	b1.Rect, b2.Rect = b1.Rect.Norm(), b2.Rect.Norm()
+	for _, b := range []*Body{&b1, &b2} {
+		if b.Shape == ShapeCircle {
+			b.Angle = 0 // circles look the same any way round
+		}
+	}
+
+	// move b1 relative to b2, the sweeps return the contact point relative to b2 standing still
+	var c Contact
+	var ok bool
+	d := b1.Vel.Sub(b2.Vel)
+	switch {
+	case b1.Angle == 0 && b2.Angle == 0:
+		c, ok = sweepAligned(b1.Shape, b1.Rect, d, b2.Shape, b2.Rect)
+	case b1.Shape == ShapeCircle:
+		c, ok = sweepCircleRotated(b1.Rect, d, b2)
+	case b2.Shape == ShapeCircle:
+		c, ok = sweepCircleRotated(b2.Rect, d.Scaled(-1), b1)
+		c = c.swapped(d)
+	default:
+		c, ok = sweepPolygons(b1.Polygon(), d, b2.Polygon())
+	}
+	if !ok {
+		return Contact{}, false
+	}
+
+	c.Point = c.Point.Add(b2.Vel.Scaled(c.T))
+	return c, true
+}
+
+// ShapesOverlap returns true if b1 and b2 overlap, where they are now
+func ShapesOverlap(b1, b2 Body) bool {
+	b1.Vel, b2.Vel = pixel.ZV, pixel.ZV
+	_, ok := ShapeCollision(b1, b2)
+	return ok
+}
+
+// swapped returns c, found with the shapes the other way round: the second shape moving by -d
+// relative to the first one
+func (c Contact) swapped(d pixel.Vec) Contact {
+	c.Normal = c.Normal.Scaled(-1)
+	c.Point = c.Point.Add(d.Scaled(c.T))
+	return c
+}
+
+// sweepAligned returns the contact of s1 in r1, moving by d, with s2 in r2, neither of them rotated
+func sweepAligned(s1 Shape, r1 pixel.Rect, d pixel.Vec, s2 Shape, r2 pixel.Rect) (Contact, bool) {
+	switch {
+	case s1 == ShapeRect && s2 == ShapeRect:
+		return sweepRectRect(r1, d, r2)
+	case s2 == ShapeRect:
+		return sweepEllipseRect(r1, d, r2)
+	case s1 == ShapeRect:
+		c, ok := sweepEllipseRect(r2, d.Scaled(-1), r1)
+		return c.swapped(d), ok
+	case s1 == ShapeCircle && s2 == ShapeCircle:
+		return sweepCircleCircle(r1, d, r2)
+	}
+	return sweepEllipseEllipse(r1, d, r2)
+}
+
+// sweepCircleRotated returns the contact of the circle in r1, moving by d, with the rotated body b.
+// Turning the world around the center of b so that b is axis aligned leaves the circle a circle.
+func sweepCircleRotated(r1 pixel.Rect, d pixel.Vec, b Body) (Contact, bool) {
+	pivot := b.Rect.Center()
+	turn := func(v pixel.Vec, angle float64) pixel.Vec { return v.Sub(pivot).Rotated(angle).Add(pivot) }
+
+	c := turn(r1.Center(), -b.Angle)
+	r1 = r1.Moved(c.Sub(r1.Center()))
+	contact, ok := sweepAligned(ShapeCircle, r1, d.Rotated(-b.Angle), b.Shape, b.Rect)
+	if !ok {
+		return Contact{}, false
+	}
+
+	contact.Point = turn(contact.Point, b.Angle)
+	contact.Normal = contact.Normal.Rotated(b.Angle)
+	return contact, true
+}
+
+// sideOf returns the side (above, below, left or right) normal n points to
+func sideOf(n pixel.Vec) string {
+	switch {
+	case math.Abs(n.X) > math.Abs(n.Y) && n.X > 0:
+		return "right"
+	case math.Abs(n.X) > math.Abs(n.Y):
+		return "left"
+	case n.Y > 0:
+		return "above"
+	}
+	return "below"
+}
+
+// radii returns the x and y radius of the ellipse filling r
+func radii(r pixel.Rect) pixel.Vec {
+	return r.Size().Scaled(0.5)
+}
+
+// sweepRect returns when point p, moving by d, enters r, and the normal of the side of r it enters
+// through, pointing into r. If p is already inside, it goes through the closest side at 0.
+func sweepRect(p, d pixel.Vec, r pixel.Rect) (float64, pixel.Vec, bool) {
+	if r.Min.X < p.X && p.X < r.Max.X && r.Min.Y < p.Y && p.Y < r.Max.Y {
+		sides := []struct {
+			depth  float64
+			normal pixel.Vec
+		}{
+			{p.X - r.Min.X, pixel.V(1, 0)},
+			{r.Max.X - p.X, pixel.V(-1, 0)},
+			{p.Y - r.Min.Y, pixel.V(0, 1)},
+			{r.Max.Y - p.Y, pixel.V(0, -1)},
+		}
+		closest := sides[0]
+		for _, s := range sides[1:] {
+			if s.depth < closest.depth {
+				closest = s
+			}
+		}
+		return 0, closest.normal, true
+	}
+
+	tmin, tmax := math.Inf(-1), math.Inf(1)
+	var normal pixel.Vec
+
+	slab := func(p, d, min, max float64, n pixel.Vec) bool {
+		if d == 0 {
+			return min < p && p < max
+		}
+		t1, t2 := (min-p)/d, (max-p)/d
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		if t1 > tmin {
+			tmin = t1
+			normal = n.Scaled(math.Copysign(1, d))
+		}
+		tmax = math.Min(tmax, t2)
+		return true
+	}
+	if !slab(p.X, d.X, r.Min.X, r.Max.X, pixel.V(1, 0)) || !slab(p.Y, d.Y, r.Min.Y, r.Max.Y, pixel.V(0, 1)) {
+		return 0, pixel.ZV, false
+	}
+	if tmin >= tmax || tmin < 0 || tmin > 1 {
+		return 0, pixel.ZV, false
+	}
+	return tmin, normal, true
+}
+
+// sweepCircle returns when point p, moving by d, gets within radius of c
+func sweepCircle(p, d, c pixel.Vec, radius float64) (float64, bool) {
+	f := p.Sub(c)
+	cc := f.Dot(f) - radius*radius
+	if cc < 0 {
+		return 0, true // already inside
+	}
+
+	a := d.Dot(d)
+	b := 2 * f.Dot(d)
+	disc := b*b - 4*a*cc
+	if a == 0 || disc <= 0 {
+		return 0, false // not moving, or missing it
+	}
+
+	t := (-b - math.Sqrt(disc)) / (2 * a)
+	if t < 0 || t > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+// sweepRectRect returns the contact of r1, moving by d, with r2
+func sweepRectRect(r1 pixel.Rect, d pixel.Vec, r2 pixel.Rect) (Contact, bool) {
+	half := radii(r1)
+	grown := pixel.R(r2.Min.X-half.X, r2.Min.Y-half.Y, r2.Max.X+half.X, r2.Max.Y+half.Y)
+
+	t, n, ok := sweepRect(r1.Center(), d, grown)
+	if !ok {
+		return Contact{}, false
+	}
+
+	// the point of r2 closest to the center of r1
+	c := r1.Center().Add(d.Scaled(t))
+	p := pixel.V(math.Max(r2.Min.X, math.Min(c.X, r2.Max.X)), math.Max(r2.Min.Y, math.Min(c.Y, r2.Max.Y)))
+	return Contact{T: t, Point: p, Normal: n}, true
+}
+
+// sweepCircleCircle returns the contact of the circle in r1, moving by d, with the circle in r2
+func sweepCircleCircle(r1 pixel.Rect, d pixel.Vec, r2 pixel.Rect) (Contact, bool) {
+	ra, rb := r1.W()/2, r2.W()/2
+
+	t, ok := sweepCircle(r1.Center(), d, r2.Center(), ra+rb)
+	if !ok {
+		return Contact{}, false
+	}
+
+	c := r1.Center().Add(d.Scaled(t))
+	n := r2.Center().Sub(c)
+	if n.Len() == 0 {
+		n = pixel.V(0, 1) // same center, any way will do
+	}
+	n = n.Unit()
+	return Contact{T: t, Point: c.Add(n.Scaled(ra)), Normal: n}, true
+}
+
+// sweepEllipseRect returns the contact of the ellipse (or circle) in r1, moving by d, with r2.
+// Scaling the world by the ellipse radii turns it into a unit circle, and r2 into another rect.
+func sweepEllipseRect(r1 pixel.Rect, d pixel.Vec, r2 pixel.Rect) (Contact, bool) {
+	s := radii(r1)
+	scale := func(v pixel.Vec) pixel.Vec { return pixel.V(v.X/s.X, v.Y/s.Y) }
+
+	p, sd := scale(r1.Center()), scale(d)
+	r := pixel.Rect{Min: scale(r2.Min), Max: scale(r2.Max)}
+
+	// the unit circle hits the rect grown by 1 on each side, with rounded corners:
+	// the rect grown sideways, the rect grown up and down, and circles around the corners
+	best := Contact{T: math.Inf(1)}
+	for _, g := range []pixel.Rect{
+		pixel.R(r.Min.X-1, r.Min.Y, r.Max.X+1, r.Max.Y),
+		pixel.R(r.Min.X, r.Min.Y-1, r.Max.X, r.Max.Y+1),
+	} {
+		if t, n, ok := sweepRect(p, sd, g); ok && t < best.T {
+			best = Contact{T: t, Normal: n}
+			best.Point = p.Add(sd.Scaled(t)).Add(n)
+		}
+	}
+	for _, corner := range r.Vertices() {
+		if t, ok := sweepCircle(p, sd, corner, 1); ok && t < best.T {
+			n := corner.Sub(p.Add(sd.Scaled(t)))
+			if n.Len() == 0 {
+				n = r.Center().Sub(p) // center on the corner
+			}
+			best = Contact{T: t, Point: corner, Normal: n.Unit()}
+		}
+	}
+	if math.IsInf(best.T, 1) {
+		return Contact{}, false
+	}
+
+	// and back, normals scale the other way round
+	best.Point = pixel.V(best.Point.X*s.X, best.Point.Y*s.Y)
+	best.Normal = pixel.V(best.Normal.X/s.X, best.Normal.Y/s.Y).Unit()
+	return best, true
+}
+
+// sweepEllipseEllipse returns the contact of the ellipse (or circle) in r1, moving by d, with the one in r2.
+// Scaling the world by the radii of the first one turns it into a unit circle, which then advances
+// towards the second one by the distance between them, until they touch.
+func sweepEllipseEllipse(r1 pixel.Rect, d pixel.Vec, r2 pixel.Rect) (Contact, bool) {
+	s := radii(r1)
+	scale := func(v pixel.Vec) pixel.Vec { return pixel.V(v.X/s.X, v.Y/s.Y) }
+
+	p, sd := scale(r1.Center()), scale(d)
+	c, e := scale(r2.Center()), scale(radii(r2)) // the second ellipse
+
+	unscaled := func(t float64, point, normal pixel.Vec) (Contact, bool) {
+		return Contact{
+			T:      t,
+			Point:  pixel.V(point.X*s.X, point.Y*s.Y),
+			Normal: pixel.V(normal.X/s.X, normal.Y/s.Y).Unit(),
+		}, true
+	}
+
+	t := 0.0
+	for i := 0; i < maxContactSteps; i++ {
+		at := p.Add(sd.Scaled(t)).Sub(c) // the center of the circle, relative to the ellipse
+		closest := closestOnEllipse(at, e)
+		toEllipse := closest.Sub(at)
+		gap := toEllipse.Len() - 1
+
+		if t == 0 && (insideEllipse(at, e) || gap <= -contactTolerance) {
+			// already overlapping, push apart along the line between the centers
+			n := at.Scaled(-1)
+			if n.Len() == 0 {
+				n = pixel.V(0, 1)
+			}
+			return unscaled(0, c.Add(at).Add(n.Unit()), n)
+		}
+		if gap < contactTolerance {
+			if t == 0 && sd.Dot(toEllipse) <= 0 {
+				return Contact{}, false // just touching
+			}
+			return unscaled(t, c.Add(closest), toEllipse)
+		}
+		if sd.Len() == 0 {
+			return Contact{}, false
+		}
+		t += gap / sd.Len()
+		if t > 1 {
+			return Contact{}, false
+		}
+	}
+	return Contact{}, false
+}
+
+// insideEllipse returns true if p is inside the ellipse centered at the origin with radii e
+func insideEllipse(p, e pixel.Vec) bool {
+	return (p.X*p.X)/(e.X*e.X)+(p.Y*p.Y)/(e.Y*e.Y) < 1
+}
+
+// closestOnEllipse returns the point on the ellipse centered at the origin with radii e closest to p.
+// Iterates on the point's parameter using the center of curvature of the ellipse, see
+// https://github.com/0xfaded/ellipse_demo/issues/1
+func closestOnEllipse(p, e pixel.Vec) pixel.Vec {
+	px, py := math.Abs(p.X), math.Abs(p.Y)
+	tx, ty := math.Sqrt2/2, math.Sqrt2/2
+
+	for i := 0; i < 4; i++ {
+		x, y := e.X*tx, e.Y*ty
+		ex := (e.X*e.X - e.Y*e.Y) * tx * tx * tx / e.X
+		ey := (e.Y*e.Y - e.X*e.X) * ty * ty * ty / e.Y
+
+		r := math.Hypot(x-ex, y-ey)
+		q := math.Hypot(px-ex, py-ey)
+		if q == 0 {
+			break
+		}
+
+		tx = math.Min(1, math.Max(0, ((px-ex)*r/q+ex)/e.X))
+		ty = math.Min(1, math.Max(0, ((py-ey)*r/q+ey)/e.Y))
+		t := math.Hypot(tx, ty)
+		tx, ty = tx/t, ty/t
+	}
+	return pixel.V(math.Copysign(e.X*tx, p.X), math.Copysign(e.Y*ty, p.Y))
+}
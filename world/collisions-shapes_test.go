@@ -0,0 +1,206 @@
+package world
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+// circleBox returns the bounding box of a circle with center c and radius r
+func circleBox(c pixel.Vec, r float64) pixel.Rect {
+	return pixel.R(c.X-r, c.Y-r, c.X+r, c.Y+r)
+}
+
+// ellipseBox returns the bounding box of an ellipse with center c and radii e
+func ellipseBox(c, e pixel.Vec) pixel.Rect {
+	return pixel.R(c.X-e.X, c.Y-e.Y, c.X+e.X, c.Y+e.Y)
+}
+
+func TestShapeCollision(t *testing.T) {
+	type shape struct {
+		s Shape
+		r pixel.Rect
+		v pixel.Vec
+	}
+	tests := []struct {
+		name   string
+		s1, s2 shape
+		want   bool
+		c      Contact
+	}{
+		{
+			name: "circles, only the boxes overlap",
+			s1:   shape{ShapeCircle, circleBox(pixel.V(0, 0), 10), pixel.ZV},
+			s2:   shape{ShapeCircle, circleBox(pixel.V(17, 17), 10), pixel.ZV},
+			want: false,
+		},
+		{
+			name: "circles, head on",
+			s1:   shape{ShapeCircle, circleBox(pixel.V(0, 0), 10), pixel.V(20, 0)},
+			s2:   shape{ShapeCircle, circleBox(pixel.V(30, 0), 10), pixel.ZV},
+			want: true,
+			c:    Contact{T: 0.5, Point: pixel.V(20, 0), Normal: pixel.V(1, 0)},
+		},
+		{
+			name: "circles, both moving",
+			s1:   shape{ShapeCircle, circleBox(pixel.V(0, 0), 10), pixel.V(10, 0)},
+			s2:   shape{ShapeCircle, circleBox(pixel.V(30, 0), 10), pixel.V(-10, 0)},
+			want: true,
+			c:    Contact{T: 0.5, Point: pixel.V(15, 0), Normal: pixel.V(1, 0)},
+		},
+		{
+			name: "circles, already overlapping",
+			s1:   shape{ShapeCircle, circleBox(pixel.V(0, 0), 10), pixel.ZV},
+			s2:   shape{ShapeCircle, circleBox(pixel.V(0, 15), 10), pixel.ZV},
+			want: true,
+			c:    Contact{T: 0, Point: pixel.V(0, 10), Normal: pixel.V(0, 1)},
+		},
+		{
+			name: "circles, touching and moving apart",
+			s1:   shape{ShapeCircle, circleBox(pixel.V(0, 0), 10), pixel.V(-5, 0)},
+			s2:   shape{ShapeCircle, circleBox(pixel.V(20, 0), 10), pixel.ZV},
+			want: false,
+		},
+		{
+			name: "circle into a rect side",
+			s1:   shape{ShapeCircle, circleBox(pixel.V(0, 0), 10), pixel.V(10, 0)},
+			s2:   shape{ShapeRect, pixel.R(15, -20, 35, 20), pixel.ZV},
+			want: true,
+			c:    Contact{T: 0.5, Point: pixel.V(15, 0), Normal: pixel.V(1, 0)},
+		},
+		{
+			name: "circle next to a rect corner",
+			s1:   shape{ShapeCircle, circleBox(pixel.V(0, 0), 10), pixel.ZV},
+			s2:   shape{ShapeRect, pixel.R(8, 8, 30, 30), pixel.ZV},
+			want: false,
+		},
+		{
+			name: "circle into a rect corner",
+			s1:   shape{ShapeCircle, circleBox(pixel.V(0, 0), 10), pixel.V(10, 10)},
+			s2:   shape{ShapeRect, pixel.R(10, 10, 30, 30), pixel.ZV},
+			want: true,
+			c:    Contact{T: 1 - math.Sqrt2/2, Point: pixel.V(10, 10), Normal: pixel.V(1, 1).Unit()},
+		},
+		{
+			name: "rect into a circle",
+			s1:   shape{ShapeRect, pixel.R(15, -20, 35, 20), pixel.V(-10, 0)},
+			s2:   shape{ShapeCircle, circleBox(pixel.V(0, 0), 10), pixel.ZV},
+			want: true,
+			c:    Contact{T: 0.5, Point: pixel.V(10, 0), Normal: pixel.V(-1, 0)},
+		},
+		{
+			name: "ellipse onto a rect",
+			s1:   shape{ShapeEllipse, ellipseBox(pixel.V(0, 0), pixel.V(20, 10)), pixel.V(0, -10)},
+			s2:   shape{ShapeRect, pixel.R(-50, -25, 50, -15), pixel.ZV},
+			want: true,
+			c:    Contact{T: 0.5, Point: pixel.V(0, -15), Normal: pixel.V(0, -1)},
+		},
+		{
+			name: "rect onto an ellipse",
+			s1:   shape{ShapeRect, pixel.R(-50, -25, 50, -15), pixel.V(0, 10)},
+			s2:   shape{ShapeEllipse, ellipseBox(pixel.V(0, 0), pixel.V(20, 10)), pixel.ZV},
+			want: true,
+			c:    Contact{T: 0.5, Point: pixel.V(0, -10), Normal: pixel.V(0, 1)},
+		},
+		{
+			name: "ellipse next to a rect corner",
+			s1:   shape{ShapeEllipse, ellipseBox(pixel.V(0, 0), pixel.V(20, 10)), pixel.ZV},
+			s2:   shape{ShapeRect, pixel.R(16, 7, 30, 30), pixel.ZV},
+			want: false,
+		},
+		{
+			name: "ellipses, head on",
+			s1:   shape{ShapeEllipse, ellipseBox(pixel.V(0, 0), pixel.V(20, 10)), pixel.V(20, 0)},
+			s2:   shape{ShapeEllipse, ellipseBox(pixel.V(45, 0), pixel.V(10, 20)), pixel.ZV},
+			want: true,
+			c:    Contact{T: 0.75, Point: pixel.V(35, 0), Normal: pixel.V(1, 0)},
+		},
+		{
+			name: "ellipses, only the boxes overlap",
+			s1:   shape{ShapeEllipse, ellipseBox(pixel.V(0, 0), pixel.V(20, 10)), pixel.ZV},
+			s2:   shape{ShapeEllipse, ellipseBox(pixel.V(32, 18), pixel.V(15, 10)), pixel.ZV},
+			want: false,
+		},
+		{
+			name: "circle onto an ellipse",
+			s1:   shape{ShapeCircle, circleBox(pixel.V(0, 40), 10), pixel.V(0, -20)},
+			s2:   shape{ShapeEllipse, ellipseBox(pixel.V(0, 0), pixel.V(30, 15)), pixel.ZV},
+			want: true,
+			c:    Contact{T: 0.75, Point: pixel.V(0, 15), Normal: pixel.V(0, -1)},
+		},
+		{
+			name: "rects",
+			s1:   shape{ShapeRect, pixel.R(0, 0, 10, 10), pixel.V(10, 0)},
+			s2:   shape{ShapeRect, pixel.R(15, 0, 25, 10), pixel.ZV},
+			want: true,
+			c:    Contact{T: 0.5, Point: pixel.V(15, 5), Normal: pixel.V(1, 0)},
+		},
+		{
+			name: "rects, sliding along each other",
+			s1:   shape{ShapeRect, pixel.R(0, 0, 10, 10), pixel.V(5, 0)},
+			s2:   shape{ShapeRect, pixel.R(0, 10, 20, 20), pixel.ZV},
+			want: false,
+		},
+	}
+
+	const tolerance = 1e-4
+	near := func(a, b pixel.Vec) bool { return a.Sub(b).Len() < tolerance }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := ShapeCollision(Body{Shape: tt.s1.s, Rect: tt.s1.r, Vel: tt.s1.v}, Body{Shape: tt.s2.s, Rect: tt.s2.r, Vel: tt.s2.v})
+			if ok != tt.want {
+				t.Fatalf("expected collision: %v, got %v (%+v)", tt.want, ok, c)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(c.T-tt.c.T) > tolerance || !near(c.Point, tt.c.Point) || !near(c.Normal, tt.c.Normal) {
+				t.Errorf("expected contact %+v, got %+v", tt.c, c)
+			}
+		})
+	}
+}
+
+// placeObject adds o to w, spawned with its center at c
+func placeObject(t *testing.T, w *World, o Object, c pixel.Vec) {
+	t.Helper()
+	if err := w.AddObject(o); err != nil {
+		t.Fatalf("failed to add object: %v", err)
+	}
+	phys := NewBaseObjectPhys(o.BoundingBox(c), o)
+	o.SetPhys(phys)
+	o.SetNextPhys(phys.Copy())
+	if err := w.qt.Insert(o); err != nil {
+		t.Fatalf("failed to add object to the quadtree: %v", err)
+	}
+}
+
+func TestBaseObjectPhys_CollisionsAt(t *testing.T) {
+	tests := []struct {
+		name string
+		o    Object
+		want int
+	}{
+		{name: "rect hits the corner", o: NewRectObject("rect", colornames.Red, 0, 1, 20, 20, nil), want: 1},
+		{name: "circle misses the corner", o: NewCircleObject("circle", colornames.Red, 0, 1, 10, nil), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			f := NewFixture("block", colornames.Gray, 40, 40)
+			f.Place(pixel.V(200, 200))
+			if err := w.AddFixture(f); err != nil {
+				t.Fatalf("failed to add fixture: %v", err)
+			}
+			placeObject(t, w, tt.o, pixel.V(185, 185))
+			tt.o.NextPhys().SetVel(pixel.V(6, 6)) // the bounding boxes overlap by a pixel
+
+			if got := tt.o.NextPhys().CollisionsAt(w); len(got) != tt.want {
+				t.Errorf("expected %v collisions, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -1,51 +1,88 @@
 package world
 
 import (
+	"math"
+
 	"github.com/faiface/pixel"
-	"github.com/DanTulovsky/alphaville/utils"
 )
 
+// AABBHit is where a moving axis aligned box first runs into another one, see SweepAABB
+type AABBHit struct {
+	T      float64   // fraction of the move when they touch, in [0, 1], 0 if they already overlap
+	Normal pixel.Vec // unit vector from the first box towards the second one, along the side they touch on
+	Depth  float64   // how far they overlap along Normal, 0 unless they already overlap
+}
+
 // HaveCollisions returns true if r1 and r2 collide
 // v1 and v2 are r1 and r2 velocity vectors
 func HaveCollisions(r1, r2 pixel.Rect, v1, v2 pixel.Vec) bool {
+	_, ok := SweepAABB(r1, r2, v1, v2)
+	return ok
+}
 
-	// do the quick check first, this does not handle movements that "jump over" the object
-	// only returns true if the objects would intersect during movement
-	// if r1.Moved(v1).Intersect(r2.Moved(v2)) != pixel.R(0, 0, 0, 0) {
-	// 	return true
-	// }
-
-	// otherwise do the longer check from
-	// https://gamedev.stackexchange.com/questions/93035/whats-the-fastest-way-checking-if-two-moving-aabbs-intersect
-	r1 = r1.Norm()
-	r2 = r2.Norm()
-
-	// r1 moved and rotated around origin
-	r1r := utils.RotatedAroundOrigin(r1)
-
-	// r2 moved same amount as r1
-	r2m := r2.Moved(pixel.V(-r1.Center().X, -r1.Center().Y))
-
-	ms := utils.MinkowskiSum(r1r, r2m)
-
-	// relative velocity of r1 against r2
-	v := v1.Sub(v2)
+// SweepAABB returns when r1, moving by v1, first overlaps r2, moving by v2, and false if they don't
+// during the move. Boxes just touching don't overlap, so an object can move right up to another one.
+//
+// Relative to r1, r2 stands still and the center of r1 moves along the segment from its center by v1-v2.
+// The boxes overlap while that segment is inside the Minkowski sum of the two: r2 grown by half the size
+// of r1 on each side. The segment enters and leaves the sum through each pair of opposite sides (slabs);
+// it is inside from the last time it enters a slab until the first time it leaves one.
+func SweepAABB(r1, r2 pixel.Rect, v1, v2 pixel.Vec) (AABBHit, bool) {
+	r1, r2 = r1.Norm(), r2.Norm()
+	half := r1.Size().Scaled(0.5)
+	sum := pixel.R(r2.Min.X-half.X, r2.Min.Y-half.Y, r2.Max.X+half.X, r2.Max.Y+half.Y)
+	p := r1.Center()
+	d := v1.Sub(v2)
 
-	// line from origin to v
-	ls := pixel.L(pixel.V(0, 0), pixel.V(v.X, v.Y))
+	// already overlapping, pushed apart along the side they overlap the least on
+	if sum.Min.X < p.X && p.X < sum.Max.X && sum.Min.Y < p.Y && p.Y < sum.Max.Y {
+		hit := AABBHit{Depth: math.Inf(1)}
+		for _, s := range []struct {
+			depth  float64
+			normal pixel.Vec
+		}{
+			{p.X - sum.Min.X, pixel.V(1, 0)},
+			{sum.Max.X - p.X, pixel.V(-1, 0)},
+			{p.Y - sum.Min.Y, pixel.V(0, 1)},
+			{sum.Max.Y - p.Y, pixel.V(0, -1)},
+		} {
+			if s.depth < hit.Depth {
+				hit.Depth, hit.Normal = s.depth, s.normal
+			}
+		}
+		return hit, true
+	}
 
-	// now count how many times ls intersects ms
-	// 0 means movement did not cause collision
-	// 1 means r1 ended up inside r2
-	// 2 means r1 ended up on the other side of r2
-	collisions := 0
+	enter, exit := math.Inf(-1), math.Inf(1)
+	var normal pixel.Vec
+	for _, s := range []struct {
+		p, d, min, max float64
+		up, down       pixel.Vec // normals moving up or down the axis
+	}{
+		{p.X, d.X, sum.Min.X, sum.Max.X, pixel.V(1, 0), pixel.V(-1, 0)},
+		{p.Y, d.Y, sum.Min.Y, sum.Max.Y, pixel.V(0, 1), pixel.V(0, -1)},
+	} {
+		if s.d == 0 {
+			if s.p <= s.min || s.p >= s.max {
+				return AABBHit{}, false // side by side, never entering the slab
+			}
+			continue
+		}
 
-	// broken due to https://github.com/faiface/pixel/issues/175
-	for _, edge := range ms.Edges() {
-		_, isect := edge.Intersect(ls)
-		if isect {
-			collisions++
+		in, out, n := (s.min-s.p)/s.d, (s.max-s.p)/s.d, s.up
+		if s.d < 0 {
+			in, out, n = out, in, s.down
 		}
+		if in > enter {
+			enter = in
+			normal = n
+		}
+		exit = math.Min(exit, out)
+	}
+
+	// enter < 0 only if already inside, handled above
+	if enter >= exit || enter < 0 || enter >= 1 {
+		return AABBHit{}, false
 	}
-	return collisions != 0
+	return AABBHit{T: enter, Normal: normal}, true
 }
@@ -1,9 +1,12 @@
 package world
 
 import (
+	"math"
 	"testing"
 
 	"github.com/faiface/pixel"
+	"github.com/go-test/deep"
+	"golang.org/x/image/colornames"
 )
 
 func TestHaveCollisions(t *testing.T) {
@@ -78,6 +81,16 @@ func TestHaveCollisions(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "already overlapping",
+			args: args{
+				r1: pixel.R(0, 0, 10, 10),
+				r2: pixel.R(8, 8, 20, 20),
+				v1: pixel.V(1, 1),
+				v2: pixel.V(0, 0),
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -87,3 +100,151 @@ func TestHaveCollisions(t *testing.T) {
 		})
 	}
 }
+
+func TestSweepAABB(t *testing.T) {
+	tests := []struct {
+		name   string
+		r1, r2 pixel.Rect
+		v1, v2 pixel.Vec
+		want   bool
+		hit    AABBHit
+	}{
+		{name: "head on", r1: pixel.R(0, 0, 10, 10), r2: pixel.R(15, 0, 25, 10), v1: pixel.V(10, 0), want: true, hit: AABBHit{T: 0.5, Normal: pixel.V(1, 0)}},
+		{name: "both moving", r1: pixel.R(0, 0, 10, 10), r2: pixel.R(15, 0, 25, 10), v1: pixel.V(5, 0), v2: pixel.V(-5, 0), want: true, hit: AABBHit{T: 0.5, Normal: pixel.V(1, 0)}},
+		{name: "from above", r1: pixel.R(0, 20, 10, 30), r2: pixel.R(-50, 0, 50, 10), v1: pixel.V(3, -20), want: true, hit: AABBHit{T: 0.5, Normal: pixel.V(0, -1)}},
+		{name: "corner first in x", r1: pixel.R(0, 0, 10, 10), r2: pixel.R(20, 12, 30, 22), v1: pixel.V(20, 4), want: true, hit: AABBHit{T: 0.5, Normal: pixel.V(1, 0)}},
+		{name: "jumps over", r1: pixel.R(0, 0, 10, 10), r2: pixel.R(11, 0, 21, 20), v1: pixel.V(50, 0), want: true, hit: AABBHit{T: 0.02, Normal: pixel.V(1, 0)}},
+		{name: "ends touching", r1: pixel.R(0, 0, 10, 10), r2: pixel.R(15, 0, 25, 10), v1: pixel.V(5, 0), want: false},
+		{name: "touching, pushing", r1: pixel.R(0, 0, 10, 10), r2: pixel.R(10, 0, 20, 10), v1: pixel.V(1, 0), want: true, hit: AABBHit{T: 0, Normal: pixel.V(1, 0)}},
+		{name: "touching, sliding", r1: pixel.R(0, 0, 10, 10), r2: pixel.R(10, 0, 20, 10), v1: pixel.V(0, 5), want: false},
+		{name: "touching, parting", r1: pixel.R(0, 0, 10, 10), r2: pixel.R(10, 0, 20, 10), v1: pixel.V(-1, 0), want: false},
+		{name: "misses", r1: pixel.R(0, 0, 10, 10), r2: pixel.R(15, 15, 25, 25), v1: pixel.V(10, -1), want: false},
+		{name: "overlapping", r1: pixel.R(0, 0, 10, 10), r2: pixel.R(8, 3, 20, 20), want: true, hit: AABBHit{T: 0, Normal: pixel.V(1, 0), Depth: 2}},
+		{name: "overlapping from below", r1: pixel.R(0, 5, 10, 15), r2: pixel.R(-5, 0, 20, 6), v1: pixel.V(0, 5), want: true, hit: AABBHit{T: 0, Normal: pixel.V(0, -1), Depth: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hit, ok := SweepAABB(tt.r1, tt.r2, tt.v1, tt.v2)
+			if ok != tt.want {
+				t.Fatalf("expected hit: %v, got %v (%+v)", tt.want, ok, hit)
+			}
+			if diff := deep.Equal(hit, tt.hit); ok && diff != nil {
+				t.Errorf("expected %+v, got %+v: %v", tt.hit, hit, diff)
+			}
+		})
+	}
+}
+
+// FuzzSweepAABB checks SweepAABB against moving r1 in small steps and checking for overlaps
+func FuzzSweepAABB(f *testing.F) {
+	f.Add(0.0, 0.0, 10.0, 10.0, 15.0, 0.0, 10.0, 10.0, 10.0, 0.0)
+	f.Add(0.0, 0.0, 10.0, 10.0, 20.0, 12.0, 10.0, 10.0, 20.0, 4.0)
+	f.Add(0.0, 20.0, 10.0, 10.0, -50.0, 0.0, 100.0, 10.0, 3.0, -20.0)
+	f.Add(0.0, 0.0, 10.0, 10.0, 8.0, 3.0, 12.0, 17.0, 1.0, 1.0)
+	f.Add(0.0, 0.0, 10.0, 10.0, 10.0, 0.0, 10.0, 10.0, 0.0, 5.0)
+	f.Add(0.0, 0.0, 4.0, 40.0, 30.0, -30.0, 2.0, 20.0, 60.0, 45.0)
+
+	const steps = 1000
+	const tolerance = 1e-9
+
+	f.Fuzz(func(t *testing.T, x1, y1, w1, h1, x2, y2, w2, h2, vx, vy float64) {
+		for _, v := range []float64{x1, y1, w1, h1, x2, y2, w2, h2, vx, vy} {
+			if math.IsNaN(v) || math.Abs(v) > 1000 {
+				t.Skip()
+			}
+		}
+		if w1 < 1 || h1 < 1 || w2 < 1 || h2 < 1 {
+			t.Skip()
+		}
+		r1, r2 := pixel.R(x1, y1, x1+w1, y1+h1), pixel.R(x2, y2, x2+w2, y2+h2)
+		v := pixel.V(vx, vy)
+
+		overlap := func(a, b pixel.Rect) bool {
+			return a.Min.X < b.Max.X && b.Min.X < a.Max.X && a.Min.Y < b.Max.Y && b.Min.Y < a.Max.Y
+		}
+		first := -1 // first step r1 overlaps r2 at
+		for i := 0; i < steps && first < 0; i++ {
+			if overlap(r1.Moved(v.Scaled(float64(i)/steps)), r2) {
+				first = i
+			}
+		}
+
+		hit, ok := SweepAABB(r1, r2, v, pixel.ZV)
+		switch {
+		case first == 0:
+			if !ok || hit.T != 0 || hit.Depth <= 0 {
+				t.Fatalf("overlapping boxes, got %+v, %v", hit, ok)
+			}
+			if moved := r1.Moved(hit.Normal.Scaled(-hit.Depth - tolerance)); overlap(moved, r2) {
+				t.Errorf("pushing r1 back by the depth (%+v) still overlaps r2", hit)
+			}
+			return
+		case first > 0:
+			if !ok {
+				t.Fatalf("missed an overlap at step %v", first)
+			}
+			if tBefore, tAt := float64(first-1)/steps, float64(first)/steps; hit.T < tBefore-tolerance || hit.T > tAt+tolerance {
+				t.Errorf("expected the hit between %v and %v, got %+v", tBefore, tAt, hit)
+			}
+		case !ok:
+			return
+		}
+
+		// the boxes touch at T, on the side of the normal, and don't overlap before
+		at := r1.Moved(v.Scaled(hit.T))
+		var gap float64
+		switch hit.Normal {
+		case pixel.V(1, 0):
+			gap = r2.Min.X - at.Max.X
+		case pixel.V(-1, 0):
+			gap = at.Min.X - r2.Max.X
+		case pixel.V(0, 1):
+			gap = r2.Min.Y - at.Max.Y
+		case pixel.V(0, -1):
+			gap = at.Min.Y - r2.Max.Y
+		default:
+			t.Fatalf("unexpected normal in %+v", hit)
+		}
+		if math.Abs(gap) > 1e-6 {
+			t.Errorf("expected the boxes touching at %+v, %v apart", hit, gap)
+		}
+		if hit.T > 0 && overlap(r1.Moved(v.Scaled(hit.T*(1-1e-6))), r2) {
+			t.Errorf("boxes overlap before %+v", hit)
+		}
+	})
+}
+
+func TestBaseObjectPhys_MoveToContact(t *testing.T) {
+	tests := []struct {
+		name  string
+		at    pixel.Vec // center of the object
+		vel   pixel.Vec
+		wantX float64 // right side of the object after the move
+	}{
+		{name: "up to the wall", at: pixel.V(168, 300), vel: pixel.V(4, 0), wantX: 180 - contactSkin},
+		{name: "already touching", at: pixel.V(170, 300), vel: pixel.V(4, 0), wantX: 180},
+		{name: "diagonally", at: pixel.V(167, 300), vel: pixel.V(4, 4), wantX: 180 - contactSkin/math.Sqrt2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			f := NewFixture("wall", colornames.Gray, 40, 100)
+			f.Place(pixel.V(180, 250))
+			if err := w.AddFixture(f); err != nil {
+				t.Fatalf("failed to add fixture: %v", err)
+			}
+			o := NewRectObject("o", colornames.Red, 3, 1, 20, 20, nil)
+			placeObject(t, w, o, tt.at)
+			o.NextPhys().SetVel(tt.vel)
+
+			collisions := o.NextPhys().CollisionsAt(w)
+			if len(collisions) != 1 {
+				t.Fatalf("expected a collision, got %v", collisions)
+			}
+			o.NextPhys().MoveToContact(w, collisions)
+			if got := o.NextPhys().Location().Max.X; math.Abs(got-tt.wantX) > 1e-9 {
+				t.Errorf("expected the object's right side at %v, got %v", tt.wantX, got)
+			}
+		})
+	}
+}
@@ -1,10 +1,10 @@
 package world
 
 import (
+	"io"
 	"strconv"
 	"strings"
 
-	"github.com/jroimartin/gocui"
 	"github.com/tevino/abool"
 )
 
@@ -18,10 +18,26 @@ type QuadTreeDebug struct {
 
 // DebugConfig contains variables to turn on debugging
 type DebugConfig struct {
-	QT QuadTreeDebug
+	QT     QuadTreeDebug
+	Zones  *abool.AtomicBool // draws the outline of force field zones
+	Joints *abool.AtomicBool // draws the joints between objects
 }
 
-func (w *World) processDebugQTCommand(tokens []string, out *gocui.View) {
+// NewDebugConfig returns a debug config with everything turned off
+func NewDebugConfig() *DebugConfig {
+	return &DebugConfig{
+		QT: QuadTreeDebug{
+			DrawTree:    abool.NewBool(false),
+			ColorTree:   abool.NewBool(false),
+			DrawText:    abool.NewBool(false),
+			DrawObjects: abool.NewBool(false),
+		},
+		Zones:  abool.NewBool(false),
+		Joints: abool.NewBool(false),
+	}
+}
+
+func (w *World) processDebugQTCommand(tokens []string, out io.Writer) {
 	// variable value
 	v := strings.TrimSpace(tokens[0])
 	b, _ := strconv.ParseBool(strings.TrimSpace(tokens[1]))
@@ -39,7 +55,7 @@ func (w *World) processDebugQTCommand(tokens []string, out *gocui.View) {
 
 }
 
-func (w *World) processWorldDebugCommand(tokens []string, out *gocui.View) {
+func (w *World) processWorldDebugCommand(tokens []string, out io.Writer) {
 	// [type] variable value
 
 	switch tokens[0] {
@@ -47,11 +63,23 @@ func (w *World) processWorldDebugCommand(tokens []string, out *gocui.View) {
 		if len(tokens) == 3 {
 			w.processDebugQTCommand(tokens[1:], out)
 		}
+	case "zones":
+		// zones draw value
+		if len(tokens) == 3 && strings.TrimSpace(tokens[1]) == "draw" {
+			b, _ := strconv.ParseBool(strings.TrimSpace(tokens[2]))
+			w.debug.Zones.SetTo(b)
+		}
+	case "joints":
+		// joints draw value
+		if len(tokens) == 3 && strings.TrimSpace(tokens[1]) == "draw" {
+			b, _ := strconv.ParseBool(strings.TrimSpace(tokens[2]))
+			w.debug.Joints.SetTo(b)
+		}
 	}
 
 }
 
-func (w *World) processDebugCommand(tokens []string, out *gocui.View) {
+func (w *World) processDebugCommand(tokens []string, out io.Writer) {
 	// world [type] variable value
 
 	switch tokens[0] {
@@ -0,0 +1,39 @@
+// Package world is the simulation: objects, fixtures, gates and sinks in a rectangle of the plane,
+// updated one tick at a time.
+//
+// Objects move by forces rather than by setting their velocity: behaviors set the velocity they want
+// (SetDesiredVel) and apply forces (ApplyForce). Every tick objects accelerate towards it, by up to
+// MaxAcceleration, lose speed to drag and to friction against what they rub on, and never go faster
+// than MaxObjectSpeed, see Integrate. Objects also turn: a collision off their center spins them,
+// angular drag and friction slow the spin down, and an object that would turn into something stops
+// spinning instead.
+//
+// Collisions are found in two phases. The quadtree finds the objects worth checking
+// (CollisionObjectsIn), those whose bounds, swept by their velocity, touch where an object is going,
+// so the cost grows with how crowded it is around an object, not with the number of objects.
+// SweepAABB checks their bounding boxes and ShapeCollision their outlines: circles, ellipses and,
+// once turned, oriented polygons (separating axis test). Objects move right up to what they run into
+// and bounce off it with equal and opposite impulses, keeping the Restitution of the less bouncy of
+// the two (ResolveCollisions). Fixtures are too heavy to be moved. Every bounce, and every overlap
+// found, is a CollisionEvent.
+//
+// The rest of the world, each next to its code:
+//
+//   - Zone: wind, water and wells push, float and pull the objects inside them
+//   - SupportUnder: objects stand on the ground, fixtures and each other (terrain.go)
+//   - Motion: kinematic fixtures move on a script, carrying and pushing objects
+//   - Joint: ropes, springs and welds between objects, or an object and a fixture
+//   - Boundaries: solid, bouncing, wrapping or open edges (boundary.go)
+//   - Sink: where objects leave the world, exit seekers head for them
+//   - Lifetime: objects are removed once it's up, RemoveObject removes one at any time
+//   - sleeping: objects with nothing to do are skipped until something wakes them (sleep.go)
+//
+// Objects are updated in parallel (SetWorkers), the result is the same whatever the number of
+// workers, see world-update.go. A world can be saved and loaded again (Save, LoadWorld) and carries on
+// the same way.
+//
+// The benchmarks (benchmark_test.go) run on synthetic headless worlds of 10, 100 and 1000 objects;
+// testdata/bench-baseline.txt is their baseline, on one CPU, measured in one run on the commit it
+// names. BenchmarkWorld_UpdateResting shows what sleeping saves: in the baseline a world of 1000
+// resting objects updates about 12 times faster asleep than awake.
+package world
@@ -0,0 +1,125 @@
+package world
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// MotionKind is how a kinematic fixture moves
+type MotionKind string
+
+// Kinds of motion
+const (
+	MotionLinear    MotionKind = "linear"    // back and forth between where it is placed and one other place
+	MotionWaypoints MotionKind = "waypoints" // through a loop of places, back to where it is placed
+	MotionRotate    MotionKind = "rotate"    // turns around a pivot
+)
+
+// Motion is the scripted motion of a kinematic fixture. Kinematic fixtures follow it whatever runs
+// into them, objects standing on them are carried along.
+type Motion struct {
+	Kind  MotionKind
+	Path  []pixel.Vec // linear and waypoints, offsets from where the fixture is placed
+	Pivot pixel.Vec   // rotate, the point the fixture turns around
+	Speed float64     // pixels per tick, radians per tick (counterclockwise, clockwise if negative) for rotate
+}
+
+// NewLinearMotion returns a motion going back and forth between where the fixture is placed and offset
+// from there, at speed
+func NewLinearMotion(offset pixel.Vec, speed float64) *Motion {
+	return &Motion{Kind: MotionLinear, Path: []pixel.Vec{offset}, Speed: speed}
+}
+
+// NewWaypointMotion returns a motion going through waypoints (offsets from where the fixture is placed)
+// and back to the start, over and over, at speed
+func NewWaypointMotion(waypoints []pixel.Vec, speed float64) *Motion {
+	return &Motion{Kind: MotionWaypoints, Path: waypoints, Speed: speed}
+}
+
+// NewRotateMotion returns a motion turning the fixture around pivot, at speed radians per tick
+func NewRotateMotion(pivot pixel.Vec, speed float64) *Motion {
+	return &Motion{Kind: MotionRotate, Pivot: pivot, Speed: speed}
+}
+
+// Validate returns an error if the motion makes no sense
+func (m *Motion) Validate() error {
+	switch m.Kind {
+	case MotionLinear:
+		if len(m.Path) != 1 || m.Path[0] == pixel.ZV {
+			return fmt.Errorf("linear motion needs one offset to move to, got %v", m.Path)
+		}
+	case MotionWaypoints:
+		if m.length() == 0 {
+			return fmt.Errorf("waypoint motion needs at least one waypoint away from the start, got %v", m.Path)
+		}
+	case MotionRotate:
+		if m.Speed == 0 {
+			return fmt.Errorf("rotate motion needs a speed")
+		}
+		if math.Abs(m.Speed) > maxAngularVel {
+			return fmt.Errorf("rotate motion speed %v faster than %v radians per tick", m.Speed, maxAngularVel)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown motion %q, want linear, waypoints or rotate", m.Kind)
+	}
+
+	if m.Speed <= 0 {
+		return fmt.Errorf("invalid motion speed %v", m.Speed)
+	}
+	return nil
+}
+
+// loop returns the offsets the fixture goes through, starting and ending at the origin
+func (m *Motion) loop() []pixel.Vec {
+	return append(append([]pixel.Vec{pixel.ZV}, m.Path...), pixel.ZV)
+}
+
+// length returns the distance around the loop of a linear or waypoints motion
+func (m *Motion) length() float64 {
+	l := 0.0
+	points := m.loop()
+	for i := 1; i < len(points); i++ {
+		l += points[i].Sub(points[i-1]).Len()
+	}
+	return l
+}
+
+// At returns where a fixture placed at origin is after ticks of motion, and its angle
+func (m *Motion) At(origin pixel.Rect, ticks int64) (pixel.Rect, float64) {
+	if m.Kind == MotionRotate {
+		a := math.Remainder(m.Speed*float64(ticks), 2*math.Pi)
+		c := origin.Center()
+		to := m.Pivot.Add(c.Sub(m.Pivot).Rotated(a))
+		return origin.Moved(to.Sub(c)), a
+	}
+
+	s := math.Mod(m.Speed*float64(ticks), m.length())
+	points := m.loop()
+	for i := 1; i < len(points); i++ {
+		seg := points[i].Sub(points[i-1])
+		l := seg.Len()
+		if s < l {
+			return origin.Moved(points[i-1].Add(seg.Scaled(s / l))), 0
+		}
+		s -= l
+	}
+	return origin, 0
+}
+
+// Bounds returns the box a fixture placed at origin covers from ticks for n more ticks of motion
+func (m *Motion) Bounds(origin pixel.Rect, ticks int64, n int) pixel.Rect {
+	var r pixel.Rect
+	for i := 0; i <= n; i++ {
+		loc, a := m.At(origin, ticks+int64(i))
+		b := Body{Shape: ShapeRect, Rect: loc, Angle: a}.Bounds()
+		if i == 0 {
+			r = b
+		} else {
+			r = r.Union(b)
+		}
+	}
+	return r
+}
@@ -0,0 +1,289 @@
+package world
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+func TestMotion_At(t *testing.T) {
+	origin := pixel.R(100, 100, 140, 120)
+
+	tests := []struct {
+		name      string
+		motion    *Motion
+		ticks     int64
+		wantMin   pixel.Vec
+		wantAngle float64
+	}{
+		{name: "linear start", motion: NewLinearMotion(pixel.V(100, 0), 2), ticks: 0, wantMin: pixel.V(100, 100)},
+		{name: "linear halfway", motion: NewLinearMotion(pixel.V(100, 0), 2), ticks: 25, wantMin: pixel.V(150, 100)},
+		{name: "linear far end", motion: NewLinearMotion(pixel.V(100, 0), 2), ticks: 50, wantMin: pixel.V(200, 100)},
+		{name: "linear on the way back", motion: NewLinearMotion(pixel.V(100, 0), 2), ticks: 75, wantMin: pixel.V(150, 100)},
+		{name: "linear round trip", motion: NewLinearMotion(pixel.V(100, 0), 2), ticks: 100, wantMin: pixel.V(100, 100)},
+		{name: "linear up", motion: NewLinearMotion(pixel.V(0, 50), 1), ticks: 20, wantMin: pixel.V(100, 120)},
+		{
+			name:    "waypoints second leg",
+			motion:  NewWaypointMotion([]pixel.Vec{pixel.V(100, 0), pixel.V(100, 100)}, 4),
+			ticks:   35,
+			wantMin: pixel.V(200, 140),
+		},
+		{
+			name:    "waypoints back to the start",
+			motion:  NewWaypointMotion([]pixel.Vec{pixel.V(100, 0), pixel.V(100, 100)}, 4),
+			ticks:   80, // 100 + 100 + 141.4
+			wantMin: pixel.V(100+100*(1-(320-200)/(100*math.Sqrt2)), 100+100*(1-(320-200)/(100*math.Sqrt2))),
+		},
+		{
+			name:      "rotate about the center",
+			motion:    NewRotateMotion(pixel.V(120, 110), math.Pi/20),
+			ticks:     10,
+			wantMin:   pixel.V(100, 100),
+			wantAngle: math.Pi / 2,
+		},
+		{
+			name:      "rotate about a pivot",
+			motion:    NewRotateMotion(pixel.V(120, 60), math.Pi/20),
+			ticks:     10,
+			wantMin:   pixel.V(50, 50), // the center goes from above the pivot to its left
+			wantAngle: math.Pi / 2,
+		},
+		{
+			name:      "rotate clockwise",
+			motion:    NewRotateMotion(pixel.V(120, 60), -math.Pi/20),
+			ticks:     10,
+			wantMin:   pixel.V(150, 50),
+			wantAngle: -math.Pi / 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, a := tt.motion.At(origin, tt.ticks)
+			if r.Min.Sub(tt.wantMin).Len() > 1e-9 || math.Abs(r.W()-origin.W()) > 1e-9 || math.Abs(r.H()-origin.H()) > 1e-9 {
+				t.Errorf("expected the fixture at %v, got %v", tt.wantMin, r)
+			}
+			if math.Abs(a-tt.wantAngle) > 1e-9 {
+				t.Errorf("expected angle %v, got %v", tt.wantAngle, a)
+			}
+		})
+	}
+}
+
+func TestFixture_SetMotion(t *testing.T) {
+	tests := []struct {
+		name   string
+		motion *Motion
+		err    string
+	}{
+		{name: "linear", motion: NewLinearMotion(pixel.V(0, 100), 1)},
+		{name: "waypoints", motion: NewWaypointMotion([]pixel.Vec{pixel.V(100, 0), pixel.V(0, 100)}, 1)},
+		{name: "rotate", motion: NewRotateMotion(pixel.V(0, 0), -0.1)},
+		{name: "none", motion: nil},
+		{name: "linear nowhere", motion: NewLinearMotion(pixel.ZV, 1), err: "one offset"},
+		{name: "linear two offsets", motion: &Motion{Kind: MotionLinear, Path: []pixel.Vec{pixel.V(1, 0), pixel.V(2, 0)}, Speed: 1}, err: "one offset"},
+		{name: "no waypoints", motion: NewWaypointMotion(nil, 1), err: "at least one waypoint"},
+		{name: "waypoints at the start", motion: NewWaypointMotion([]pixel.Vec{pixel.ZV}, 1), err: "at least one waypoint"},
+		{name: "no speed", motion: NewLinearMotion(pixel.V(0, 100), 0), err: "invalid motion speed"},
+		{name: "backwards", motion: NewWaypointMotion([]pixel.Vec{pixel.V(100, 0)}, -1), err: "invalid motion speed"},
+		{name: "not turning", motion: NewRotateMotion(pixel.V(0, 0), 0), err: "needs a speed"},
+		{name: "spinning", motion: NewRotateMotion(pixel.V(0, 0), -1), err: "faster than"},
+		{name: "unknown", motion: &Motion{Kind: "teleport", Speed: 1}, err: "unknown motion"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFixture("f", colornames.Gray, 40, 20)
+			err := f.SetMotion(tt.motion)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("failed to set motion: %v", err)
+				}
+				if f.Motion() != tt.motion {
+					t.Errorf("expected motion %v, got %v", tt.motion, f.Motion())
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+// addMovingFixture adds a kinematic fixture with its bottom left corner at l to the world
+func addMovingFixture(t *testing.T, w *World, name string, l pixel.Vec, width, height float64, m *Motion) *Fixture {
+	t.Helper()
+	f := addTestFixture(t, w, name, l, width, height)
+	if err := f.SetMotion(m); err != nil {
+		t.Fatalf("failed to set motion: %v", err)
+	}
+	return f
+}
+
+func TestWorld_MovingFixtures(t *testing.T) {
+	w := newTestWorld(1)
+	f := addMovingFixture(t, w, "lift", pixel.V(100, 100), 60, 20, NewLinearMotion(pixel.V(0, 100), 2))
+
+	for i := 1; i <= 75; i++ {
+		w.Step()
+		want, _ := f.Motion().At(pixel.R(100, 100, 160, 120), int64(i-1))
+		if got := f.Phys().Location(); got != want {
+			t.Fatalf("tick %v: expected the fixture at %v, got %v", i, want, got)
+		}
+		if got := w.QuadTree().rects[f.ID()]; got != f.Phys().Bounds() {
+			t.Fatalf("tick %v: the quadtree has the fixture at %v, it is at %v", i, got, f.Phys().Bounds())
+		}
+	}
+	if got := f.Phys().Vel(); got != pixel.V(0, -2) {
+		t.Errorf("expected the fixture going down at 2, got %v", got)
+	}
+}
+
+// onTop returns true if o rests on f
+func onTop(o, f pixel.Rect) bool {
+	return math.Abs(o.Min.Y-f.Max.Y) <= supportGap
+}
+
+func TestWorld_FixturesCarryObjects(t *testing.T) {
+	tests := []struct {
+		name   string
+		motion *Motion
+		at     pixel.Vec // bottom left corner of the fixture
+		center pixel.Vec // of the object
+		ticks  int
+		check  func(o, f pixel.Rect) bool
+		want   string
+	}{
+		{
+			name:   "lift going up",
+			motion: NewLinearMotion(pixel.V(0, 200), 1),
+			at:     pixel.V(250, 80),
+			center: pixel.V(300, 110),
+			ticks:  150,
+			check:  func(o, f pixel.Rect) bool { return onTop(o, f) && o.Min.Y > 200 },
+			want:   "standing on the lift, up with it",
+		},
+		{
+			name:   "lift going down",
+			motion: NewWaypointMotion([]pixel.Vec{pixel.V(0, 200)}, 2),
+			at:     pixel.V(250, 80),
+			center: pixel.V(300, 110),
+			ticks:  150,
+			check:  func(o, f pixel.Rect) bool { return onTop(o, f) && o.Min.Y < 250 },
+			want:   "standing on the lift, down with it",
+		},
+		{
+			name:   "platform going sideways",
+			motion: NewLinearMotion(pixel.V(300, 0), 1),
+			at:     pixel.V(250, 80),
+			center: pixel.V(300, 110),
+			ticks:  150,
+			check:  func(o, f pixel.Rect) bool { return onTop(o, f) && o.Center().X > 420 },
+			want:   "standing on the platform, along with it",
+		},
+		{
+			name:   "pushed by a platform",
+			motion: NewLinearMotion(pixel.V(300, 0), 1),
+			at:     pixel.V(250, 40),
+			center: pixel.V(380, 50),
+			ticks:  150,
+			check:  func(o, f pixel.Rect) bool { return o.Min.X > f.Max.X-1e-9 && o.Min.X > 420 },
+			want:   "in front of the platform",
+		},
+		{
+			name:   "pushed by a fast platform",
+			motion: NewLinearMotion(pixel.V(300, 0), 150),
+			at:     pixel.V(250, 40),
+			center: pixel.V(380, 50),
+			ticks:  3,
+			check:  func(o, f pixel.Rect) bool { return o.Min.X > f.Max.X-1e-9 },
+			want:   "in front of the platform, not run through",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			f := addMovingFixture(t, w, "platform", tt.at, 100, 20, tt.motion)
+			o := NewRectObject("o", colornames.Red, 0, 1, 20, 20, nil)
+			placeObject(t, w, o, tt.center)
+			for _, phys := range []ObjectPhys{o.Phys(), o.NextPhys()} {
+				phys.SetCurrentMass(o.Mass())
+			}
+
+			for i := 0; i < tt.ticks; i++ {
+				w.Step()
+			}
+			if r := o.Phys().Location(); !tt.check(r, f.Phys().Location()) {
+				t.Errorf("expected the object %v, it is at %v (the fixture at %v)", tt.want, r, f.Phys().Location())
+			}
+			if n := w.Stats.Overlaps; n != 0 {
+				t.Errorf("expected no overlaps, got %v", n)
+			}
+		})
+	}
+}
+
+func TestWorld_ObjectsIntersecting(t *testing.T) {
+	w := newTestWorld(1)
+	f := addMovingFixture(t, w, "lift", pixel.V(100, 100), 60, 20, NewLinearMotion(pixel.V(0, 200), 30))
+	w.moving = w.movingFixtures()
+	f.Update(w)
+
+	tests := []struct {
+		name string
+		r    pixel.Rect
+		want bool
+	}{
+		{name: "where it is", r: pixel.R(110, 110, 130, 130), want: true},
+		{name: "where it is going", r: pixel.R(110, 140, 130, 160), want: true},
+		{name: "out of its way", r: pixel.R(110, 160, 130, 180), want: false},
+		{name: "beside it", r: pixel.R(200, 140, 220, 160), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := false
+			for _, o := range w.objectsIntersecting(tt.r) {
+				found = found || o == Object(f)
+			}
+			if found != tt.want {
+				t.Errorf("expected the lift in %v %v, got %v", tt.r, tt.want, found)
+			}
+		})
+	}
+}
+
+func TestTargetSeekerBehavior_PathBlocked(t *testing.T) {
+	w := newTestWorld(1)
+	b := NewTargetSeekerBehavior(&DijkstraPathFinder{})
+	o := NewRectObject("ts", colornames.Red, 2, 1, 20, 20, b)
+	placeObject(t, w, o, pixel.V(100, 300))
+	b.SetTarget(NewSimpleTarget("target", pixel.V(700, 300), 10, "target"))
+	b.recalculateMoveInfo(w, o)
+	if len(b.path) == 0 {
+		t.Fatalf("expected a path to the target")
+	}
+
+	// a wall comes down across the path
+	addMovingFixture(t, w, "wall", pixel.V(300, 250), 40, 100, NewLinearMotion(pixel.V(0, 200), 2))
+	if b.pathBlocked(w, o) {
+		t.Fatalf("expected nothing in the way before the wall moves, path: %v", b.fullpath)
+	}
+	w.moving = w.movingFixtures()
+	if !b.pathBlocked(w, o) {
+		t.Fatalf("expected the wall in the way, path: %v", b.fullpath)
+	}
+
+	b.recalculateMoveInfo(w, o)
+	if len(b.path) == 0 {
+		t.Fatalf("expected a path around the wall")
+	}
+	if b.pathBlocked(w, o) {
+		t.Errorf("expected the new path to go around the wall, path: %v", b.fullpath)
+	}
+}
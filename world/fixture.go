@@ -3,28 +3,61 @@ package world
 import (
 	"image/color"
 	"math"
+	"sort"
 
 	"github.com/faiface/pixel"
 )
 
-// Fixture is a non-moving fixture in the world (walls, etc...)
+// carryStack is how many objects standing on top of each other a kinematic fixture carries
+const carryStack = 4
+
+// Fixture is a fixture in the world (walls, platforms, etc...). Fixtures don't move, unless they are
+// given a Motion to follow: kinematic fixtures, see SetMotion.
 type Fixture struct {
 	RectObject
+
+	motion *Motion
+	origin pixel.Rect // where the fixture was placed, its motion is relative to it
+	ticks  int64      // ticks the fixture has moved for
 }
 
 // NewFixture returns a new world fixture
 func NewFixture(name string, color color.Color, width, height float64) *Fixture {
 
 	f := &Fixture{
-		*(NewRectObject(name, color, 0, math.MaxFloat64, width, height, nil)),
+		RectObject: *(NewRectObject(name, color, 0, math.MaxFloat64, width, height, nil)),
 	}
 
 	return f
 }
 
-// Update updates the fixture for next tick
+// Update updates the fixture for next tick, kinematic fixtures move on along their motion
 func (f *Fixture) Update(w *World) {
-	// Nothing to update for fixtures right now.
+	if f.motion == nil {
+		return
+	}
+
+	r, a := f.motion.At(f.origin, f.ticks+1)
+	next := f.Phys().Copy()
+	next.SetLocation(r)
+	next.SetAngle(a)
+
+	// the velocity is how far it moves this tick, objects check collisions against it
+	v := r.Center().Sub(f.Phys().Location().Center())
+	av := math.Remainder(a-f.Phys().Angle(), 2*math.Pi)
+	for _, phys := range []ObjectPhys{f.Phys(), next} {
+		phys.SetVel(v)
+		phys.SetAngularVel(av)
+	}
+	f.SetNextPhys(next)
+}
+
+// SwapNextState swaps the current state for next state of the fixture
+func (f *Fixture) SwapNextState() {
+	f.RectObject.SwapNextState()
+	if f.motion != nil {
+		f.ticks++
+	}
 }
 
 // Place places the fixture in the world, l is the bottom left corner
@@ -32,4 +65,195 @@ func (f *Fixture) Place(l pixel.Vec) {
 	phys := NewBaseObjectPhys(pixel.R(l.X, l.Y, l.X+f.width, l.Y+f.height), f)
 	f.SetPhys(phys)
 	f.SetNextPhys(f.Phys().Copy())
+	f.origin = phys.Location()
+	f.ticks = 0
+}
+
+// Motion returns the motion the fixture follows, nil if it doesn't move
+func (f *Fixture) Motion() *Motion {
+	return f.motion
+}
+
+// SetMotion makes the fixture follow m from where it is placed, nil stops it
+func (f *Fixture) SetMotion(m *Motion) error {
+	if m != nil {
+		if err := m.Validate(); err != nil {
+			return err
+		}
+	}
+	f.motion = m
+	return nil
+}
+
+// Carries returns true if the fixture moves and r rests right on top of it, see World.carry
+func (f *Fixture) Carries(r pixel.Rect) bool {
+	if f.motion == nil {
+		return false
+	}
+	b := f.Phys().Bounds()
+	return r.Max.X > b.Min.X && r.Min.X < b.Max.X && math.Abs(r.Min.Y-topOver(f, b, r.Min.X, r.Max.X)) <= supportGap
+}
+
+// sweeps returns true if the fixture runs into b this tick, on its way or where it ends up
+func (f *Fixture) sweeps(b Body) bool {
+	b.Vel = pixel.ZV
+	if ShapesOverlap(f.NextPhys().Body(), b) {
+		return true
+	}
+	from := f.Phys().Body()
+	from.Vel = f.NextPhys().Vel()
+	_, ok := ShapeCollision(from, b)
+	return ok
+}
+
+// sweptBounds returns the box the fixture covers this tick, from where it is to where it is going
+func (f *Fixture) sweptBounds() pixel.Rect {
+	return f.Phys().Bounds().Union(f.NextPhys().Bounds())
+}
+
+// displacement returns how far the point p, fixed to the fixture, moves this tick
+func (f *Fixture) displacement(p pixel.Vec) pixel.Vec {
+	from, to := f.Phys(), f.NextPhys()
+	c0, c1 := from.Location().Center(), to.Location().Center()
+	return c1.Add(p.Sub(c0).Rotated(to.Angle() - from.Angle())).Sub(p)
+}
+
+// carry moves o (its next state) along with the kinematic fixture it stands on, directly or on top of
+// other objects, or pushes it out of the way of a kinematic fixture running into it, however fast.
+// o stays behind if that would take it out of the world.
+func (w *World) carry(o Object) {
+	if len(w.moving) == 0 {
+		return
+	}
+	p := o.NextPhys()
+	r := p.Bounds()
+
+	if f, dy := w.carrier(o, r, carryStack); f != nil {
+		d := f.displacement(pixel.V(r.Center().X, r.Min.Y))
+		d.Y = dy
+		if w.inside(r.Moved(d)) {
+			p.SetLocation(p.Location().Moved(d))
+		}
+		return
+	}
+
+	for _, f := range w.moving {
+		if !f.sweeps(p.Body()) {
+			continue
+		}
+		fb, ahead := f.NextPhys().Bounds(), pixel.ZV
+		if !ShapesOverlap(f.NextPhys().Body(), p.Body()) {
+			fb, ahead = f.sweptBounds(), f.NextPhys().Vel() // it went right past o, o goes on ahead of it
+		}
+		if d, ok := w.pushOut(o, r, fb, ahead); ok {
+			p.SetLocation(p.Location().Moved(d))
+		}
+		return
+	}
+}
+
+// pushOut returns the shortest move, up, down, left or right, that takes r (the bounds of o) out of fb,
+// only the moves ahead (forward along ahead) unless it is zero.
+// Moves into other objects are the last resort, moves out of the world are not possible.
+func (w *World) pushOut(o Object, r, fb pixel.Rect, ahead pixel.Vec) (pixel.Vec, bool) {
+	moves := []pixel.Vec{
+		pixel.V(0, fb.Max.Y-r.Min.Y+contactSkin),
+		pixel.V(0, fb.Min.Y-r.Max.Y-contactSkin),
+		pixel.V(fb.Min.X-r.Max.X-contactSkin, 0),
+		pixel.V(fb.Max.X-r.Min.X+contactSkin, 0),
+	}
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].Len() < moves[j].Len() })
+
+	var last *pixel.Vec
+	for i, d := range moves {
+		to := r.Moved(d)
+		if ahead != pixel.ZV && d.Dot(ahead) <= 0 || !w.inside(to) {
+			continue
+		}
+		if w.isFree(o, to) {
+			return d, true
+		}
+		if last == nil {
+			last = &moves[i]
+		}
+	}
+	if last == nil {
+		return pixel.ZV, false
+	}
+	return *last, true
+}
+
+// inside returns true if r is in the world, above the ground
+func (w *World) inside(r pixel.Rect) bool {
+	return r.Min.X >= 0 && r.Max.X <= w.X && r.Max.Y <= w.Y && r.Min.Y >= w.groundTop()
+}
+
+// isFree returns true if no object but o is in r, where they are now
+func (w *World) isFree(o Object, r pixel.Rect) bool {
+	free := true
+	w.qt.forEachIn(r, func(other Object, b pixel.Rect) {
+		if _, ok := other.(*Fixture); ok || other.ID() == o.ID() {
+			return
+		}
+		if b.Intersect(r).Area() > 0 {
+			free = false
+		}
+	})
+	return free
+}
+
+// carrier returns the kinematic fixture r (the bounds of o) rests on, directly or on a stack of at most
+// depth objects, and how far r moves up with it to stay on top. Only current states (Phys) are read,
+// others may be updating.
+func (w *World) carrier(o Object, r pixel.Rect, depth int) (*Fixture, float64) {
+	for _, f := range w.moving {
+		if !f.Carries(r) {
+			continue
+		}
+		if f.NextPhys().Angle() == 0 {
+			return f, f.NextPhys().Bounds().Max.Y + contactSkin - r.Min.Y // on top, not a rounding error into it
+		}
+		return f, f.displacement(pixel.V(r.Center().X, r.Min.Y)).Y
+	}
+	if depth == 0 {
+		return nil, 0
+	}
+
+	var carrier *Fixture
+	dy := 0.0
+	w.qt.forEachIn(pixel.R(r.Min.X, r.Min.Y-supportGap, r.Max.X, r.Min.Y+supportGap), func(other Object, b pixel.Rect) {
+		if _, ok := other.(*Fixture); carrier != nil || ok || other.ID() == o.ID() {
+			return
+		}
+		if math.Abs(r.Min.Y-b.Max.Y) > supportGap || b.Max.X <= r.Min.X || b.Min.X >= r.Max.X {
+			return
+		}
+		if f, below := w.carrier(other, b, depth-1); f != nil {
+			carrier, dy = f, b.Max.Y+below-r.Min.Y
+		}
+	})
+	return carrier, dy
+}
+
+// objectsIntersecting returns the objects in r, as the quadtree has them, and the kinematic fixtures
+// moving into it this tick: the quadtree has those where they are now
+func (w *World) objectsIntersecting(r pixel.Rect) []Object {
+	objects := w.qt.ObjectsIn(r)
+	for _, f := range w.moving {
+		if !touches(f.Phys().Bounds(), r) && touches(f.sweptBounds(), r) {
+			objects = append(objects, f)
+		}
+	}
+	return objects
+}
+
+// movingFixtures returns the kinematic fixtures in the world
+func (w *World) movingFixtures() []*Fixture {
+	var moving []*Fixture
+	for _, o := range w.fixtures {
+		if f, ok := o.(*Fixture); ok && f.motion != nil {
+			moving = append(moving, f)
+		}
+	}
+	return moving
 }
@@ -5,15 +5,10 @@ import (
 	"image/color"
 	"time"
 
-	"github.com/faiface/pixel/pixelgl"
-	"github.com/faiface/pixel/text"
-	"github.com/google/uuid"
 	"github.com/DanTulovsky/alphaville/observer"
-	"github.com/DanTulovsky/alphaville/utils"
+	"github.com/google/uuid"
 	"golang.org/x/image/colornames"
 
-	"github.com/faiface/pixel/imdraw"
-
 	"github.com/faiface/pixel"
 )
 
@@ -58,6 +53,7 @@ type Gate struct {
 	// Wait this long before allowing a new spawn
 	SpawnCoolDown time.Duration
 	LastSpawn     time.Time
+	clock         Clock // set to the world clock when the gate is added to the world
 
 	Radius float64 // size
 
@@ -77,6 +73,60 @@ var DefaultGateFilter = func(Object) bool {
 	return true
 }
 
+// ManualOnlyGateFilter allows only manually controlled objects
+var ManualOnlyGateFilter = func(o Object) bool {
+	_, ok := o.Behavior().(*ManualBehavior)
+	return ok
+}
+
+// TargetSeekerOnlyGateFilter allows only target seekers
+var TargetSeekerOnlyGateFilter = func(o Object) bool {
+	_, ok := o.Behavior().(*TargetSeekerBehavior)
+	return ok
+}
+
+// ExitSeekerOnlyGateFilter allows only exit seekers
+var ExitSeekerOnlyGateFilter = func(o Object) bool {
+	b, ok := o.Behavior().(*TargetSeekerBehavior)
+	return ok && b.exits
+}
+
+// GateFilters are the gate filters known by name.
+// Only gates using these can be saved; register custom filters here to save them as well.
+var GateFilters = map[string]GateFilter{
+	"default":            DefaultGateFilter,
+	"manual_only":        ManualOnlyGateFilter,
+	"target_seeker_only": TargetSeekerOnlyGateFilter,
+	"exit_seeker_only":   ExitSeekerOnlyGateFilter,
+}
+
+// FilterNames returns the names of the gate's filters, as registered in GateFilters
+func (g *Gate) FilterNames() ([]string, error) {
+	names, err := filterNames(g.filters)
+	if err != nil {
+		return nil, fmt.Errorf("gate [%v] %v", g.name, err)
+	}
+	return names, nil
+}
+
+// filterNames returns the names of filters, as registered in GateFilters
+func filterNames(filters []GateFilter) ([]string, error) {
+	names := []string{}
+
+FILTERS:
+	for _, f := range filters {
+		// functions are not comparable, but the registered ones are distinct functions
+		for name, known := range GateFilters {
+			if fmt.Sprintf("%p", f) == fmt.Sprintf("%p", known) {
+				names = append(names, name)
+				continue FILTERS
+			}
+		}
+		return nil, fmt.Errorf("has a filter not registered in GateFilters")
+	}
+	return names, nil
+}
+
 // NewGate creates a new gate in the world
 func NewGate(n string, l pixel.Vec, s GateStatus, coolDown time.Duration, radius float64, filters ...GateFilter) *Gate {
 
@@ -89,6 +139,7 @@ func NewGate(n string, l pixel.Vec, s GateStatus, coolDown time.Duration, radius
 		SpawnCoolDown: coolDown,
 		Radius:        radius,
 		filters:       filters,
+		clock:         NewWallClock(DefaultTickDuration),
 	}
 
 	return g
@@ -99,6 +150,11 @@ func (g *Gate) String() string {
 	return fmt.Sprintf("[%v] L: %v, S: %v, R: %v, C: %v (%v)", g.name, g.Location, g.Status, g.Reserved, g.SpawnCoolDown, g.LastSpawn)
 }
 
+// SetClock sets the clock used for the spawn cool down
+func (g *Gate) SetClock(c Clock) {
+	g.clock = c
+}
+
 // CanSpawn returns true if the gate can spawn
 func (g *Gate) CanSpawn() bool {
 	switch {
@@ -106,7 +162,7 @@ func (g *Gate) CanSpawn() bool {
 		return false
 	case g.Reserved:
 		return false
-	case time.Since(g.LastSpawn) < g.SpawnCoolDown:
+	case !g.LastSpawn.IsZero() && g.clock.Since(g.LastSpawn) < g.SpawnCoolDown:
 		return false
 	}
 	return true
@@ -123,7 +179,7 @@ func (g *Gate) Reserve(o Object) error {
 	if g.CanSpawn() {
 		g.Reserved = true
 		g.ReservedBy = o.ID()
-		g.Notify(NewGateEvent(fmt.Sprintf("gate [%v] reserved for [%v]", g, o.ID()), time.Now()))
+		g.Notify(NewGateEvent(fmt.Sprintf("gate [%v] reserved for [%v]", g, o.ID()), g.clock.Now()))
 
 		return nil
 	}
@@ -132,42 +188,41 @@ func (g *Gate) Reserve(o Object) error {
 
 // Release removes a gates reservation
 func (g *Gate) Release() {
-	g.Notify(NewGateEvent(fmt.Sprintf("gate [%v] reservation released", g), time.Now()))
+	g.Notify(NewGateEvent(fmt.Sprintf("gate [%v] reservation released", g), g.clock.Now()))
 	g.Reserved = false
-	g.LastSpawn = time.Now()
+	g.LastSpawn = g.clock.Now()
 }
 
-// Draw draws the gate on the screen
-func (g *Gate) Draw(win *pixelgl.Window) {
-	imd := imdraw.New(nil)
-
-	imd.Color = g.Color()
+// CancelReservation removes any reservation made by o, without starting the spawn cool down
+func (g *Gate) CancelReservation(o Object) {
+	if g.ReservedBy != o.ID() {
+		return
+	}
+	if g.Reserved {
+		g.Notify(NewGateEvent(fmt.Sprintf("gate [%v] reservation cancelled", g), g.clock.Now()))
+		g.Reserved = false
+	}
+	g.ReservedBy = uuid.Nil
+}
 
-	imd.Push(g.Location)
-	imd.Circle(g.Radius, 2)
-	imd.Draw(win)
+// Draw draws the gate on the screen
+func (g *Gate) Draw(r Renderer, alpha float64) {
+	r.Circle(g.Location, g.Radius, g.Color(), 2)
 
 	// remaining time until next spawn
-	txt := text.New(g.Location, utils.Atlas())
-	txt.Color = colornames.Yellow
-
 	label := ""
 
 	switch {
 	case g.Status == GateClosed:
 		label = "inf"
 	case !g.CanSpawn():
-		label = fmt.Sprintf("%v", g.SpawnCoolDown-time.Since(g.LastSpawn).Truncate(time.Second))
+		label = fmt.Sprintf("%v", g.SpawnCoolDown-g.clock.Since(g.LastSpawn).Truncate(time.Second))
 	default:
 		label = "inf"
 
 	}
 
-	// center the text
-	txt.Dot.X -= txt.BoundsOf(label).W() / 2
-
-	fmt.Fprintf(txt, label)
-	txt.Draw(win, pixel.IM)
+	r.Text(g.Location, colornames.Yellow, label)
 }
 
 // Implement the observer.EventNotifier interface
@@ -228,6 +283,43 @@ func (g *Gate) Mass() float64 {
 	return -1
 }
 
+// Lifetime always returns 0
+func (g *Gate) Lifetime() time.Duration {
+	return 0
+}
+
+// SetLifetime does nothing
+func (g *Gate) SetLifetime(time.Duration) {}
+
+// Restitution always returns 0
+func (g *Gate) Restitution() float64 {
+	return 0
+}
+
+// SetRestitution does nothing
+func (g *Gate) SetRestitution(float64) {}
+
+// GravityScale always returns 0
+func (g *Gate) GravityScale() float64 {
+	return 0
+}
+
+// SetGravityScale does nothing
+func (g *Gate) SetGravityScale(float64) {}
+
+// Shape always returns ShapeRect
+func (g *Gate) Shape() Shape {
+	return ShapeRect
+}
+
+// SpawnTime always returns the zero time
+func (g *Gate) SpawnTime() time.Time {
+	return time.Time{}
+}
+
+// SetSpawnTime does nothing
+func (g *Gate) SetSpawnTime(time.Time) {}
+
 // NextPhys always returns nil
 func (g *Gate) NextPhys() ObjectPhys {
 	return nil
@@ -27,7 +27,7 @@ func TestNewGate(t *testing.T) {
 			args: args{
 				l: pixel.V(10, 10),
 				s: GateOpen,
-				w: NewWorld(768, 1024, nil, 2, 2, &DebugConfig{}, nil),
+				w: NewWorld(768, 1024, nil, 2, 2, 1, &DebugConfig{}, nil),
 				c: time.Minute * 1,
 				r: 20,
 			},
@@ -38,7 +38,7 @@ func TestNewGate(t *testing.T) {
 			args: args{
 				l: pixel.V(100, 100),
 				s: GateOpen,
-				w: NewWorld(68, 1024, nil, 2, 2, &DebugConfig{}, nil),
+				w: NewWorld(68, 1024, nil, 2, 2, 1, &DebugConfig{}, nil),
 				c: time.Minute * 1,
 				r: 20,
 			},
@@ -153,3 +153,53 @@ func TestGate_UnReserve(t *testing.T) {
 		})
 	}
 }
+
+func TestGate_CoolDownUsesClock(t *testing.T) {
+	c := NewSimClock(time.Second)
+	g := NewGate("", pixel.V(100, 100), GateOpen, time.Second*3, 10)
+	g.SetClock(c)
+	o := NewBaseObject("", colornames.Red, 0, 0)
+
+	if err := g.Reserve(&o); err != nil {
+		t.Fatalf("failed to reserve gate: %v", err)
+	}
+	g.Release()
+
+	for i := 0; i < 3; i++ {
+		if g.CanSpawn() {
+			t.Errorf("gate can spawn after %v ticks, cool down is 3", i)
+		}
+		c.Tick()
+	}
+	if !g.CanSpawn() {
+		t.Errorf("gate cannot spawn after cool down")
+	}
+}
+
+func TestGate_EventsUseClock(t *testing.T) {
+	c := NewSimClock(time.Second)
+	c.ticks = 42
+	g := NewGate("", pixel.V(100, 100), GateOpen, 0, 10)
+	g.SetClock(c)
+	events := &eventLog{}
+	g.Register(events)
+	o := NewBaseObject("", colornames.Red, 0, 0)
+
+	if err := g.Reserve(&o); err != nil {
+		t.Fatalf("failed to reserve gate: %v", err)
+	}
+	g.Release()
+	if err := g.Reserve(&o); err != nil {
+		t.Fatalf("failed to reserve gate: %v", err)
+	}
+	g.CancelReservation(&o)
+
+	if len(events.events) == 0 {
+		t.Fatalf("expected gate events")
+	}
+	for _, e := range events.events {
+		if !e.Time().Equal(c.Now()) {
+			t.Errorf("expected %q at %v, world clock time, got %v", e.Description(), c.Now(), e.Time())
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package world
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/DanTulovsky/alphaville/observer"
+	"github.com/faiface/pixel"
+)
+
+// InputEvent is sent for any input the world gets from the outside: manual control and console commands
+type InputEvent struct {
+	observer.BaseEvent
+}
+
+// NewInputEvent create a new input event
+func NewInputEvent(d string, t time.Time, data ...observer.EventData) observer.Event {
+	e := &InputEvent{}
+	e.SetData(data)
+	e.SetDescription(d)
+	e.SetTime(t)
+
+	return e
+}
+
+// SetManualVelocity passes v on to the manually controlled object, if there is one in the world
+func (w *World) SetManualVelocity(v pixel.Vec) {
+	mo := w.ManualControl
+	if !mo.IsSpawned() {
+		return
+	}
+
+	w.Notify(NewInputEvent(fmt.Sprintf("manual velocity %v", v), w.clock.Now(),
+		observer.EventData{Key: "manual_velocity", Value: FormatVec(v)}))
+	mo.SetManualVelocity(v)
+}
+
+// command is a console command waiting for the main loop, see QueueCommand
+type command struct {
+	in    string
+	reply func(string) // gets the output of the command
+}
+
+// QueueCommand queues a console command for ProcessCommands, reply gets its output.
+// It is safe to call from any goroutine, like the console's.
+func (w *World) QueueCommand(in string, reply func(string)) {
+	w.commandMu.Lock()
+	defer w.commandMu.Unlock()
+	w.commands = append(w.commands, command{in: in, reply: reply})
+}
+
+// ProcessCommands runs the queued console commands, in the order they came in.
+// Call it from the main loop between steps, like other input, so commands are recorded at the tick they
+// run and replay the same way.
+func (w *World) ProcessCommands() {
+	w.commandMu.Lock()
+	commands := w.commands
+	w.commands = nil
+	w.commandMu.Unlock()
+
+	for _, c := range commands {
+		var out bytes.Buffer
+		w.ProcessCommand(c.in, &out)
+		c.reply(out.String())
+	}
+}
+
+// ProcessCommand runs a console command, output goes to out
+func (w *World) ProcessCommand(in string, out io.Writer) {
+	w.Notify(NewInputEvent(fmt.Sprintf("command %q", in), w.clock.Now(),
+		observer.EventData{Key: "command", Value: in}))
+	w.processConsoleInput(in, out)
+}
+
+// FormatVec returns v as "x,y", without losing precision
+func FormatVec(v pixel.Vec) string {
+	return fmt.Sprintf("%v,%v", v.X, v.Y)
+}
+
+// ParseVec parses a vector formatted by FormatVec
+func ParseVec(s string) (pixel.Vec, error) {
+	var v pixel.Vec
+	if _, err := fmt.Sscanf(s, "%g,%g", &v.X, &v.Y); err != nil {
+		return pixel.ZV, fmt.Errorf("invalid vector %q: %v", s, err)
+	}
+	return v, nil
+}
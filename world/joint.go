@@ -0,0 +1,416 @@
+package world
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/DanTulovsky/alphaville/observer"
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+// Joints link two objects, or an object and a fixture, by their centers. A fixture is an anchor: it
+// doesn't give, it stays put or follows its Motion and the object hangs on to it.
+// Joints are solved once all objects are updated, like collisions: they change the velocities of the
+// next state of the objects, so the objects move the way the joints let them on the next tick, and
+// still run into whatever is in their way. Joined objects collide with each other as usual.
+// A joint takes hold once both its objects are in the world, and goes away with either of them.
+
+// JointKind is how a joint holds on to its objects
+type JointKind string
+
+// Kinds of joints
+const (
+	JointDistance JointKind = "distance" // a rope, the objects can't get further than Length apart
+	JointSpring   JointKind = "spring"   // pulls (or pushes) the objects towards Length apart, damped
+	JointWeld     JointKind = "weld"     // the objects stay where they are to each other, and turn together
+)
+
+const (
+	// jointIterations is how many times a tick joints are solved, chains need a few rounds to settle
+	jointIterations = 8
+	// jointBias is the fraction of how far it is out of shape a joint corrects every tick
+	jointBias = 0.2
+	// jointSlop is how far (in pixels, or radians) a joint may be out of shape without being corrected
+	jointSlop = 0.01
+	// minJointVel is the smallest change of velocity a joint bothers with, so joints at rest stay at rest
+	minJointVel = 1e-6
+)
+
+// Joint links objects A and B, see NewDistanceJoint, NewSpringJoint and NewWeldJoint
+type Joint struct {
+	Name       string
+	Kind       JointKind
+	A, B       Object
+	Length     float64 // distance and spring, how far apart to keep the centers, set to how far they are once both are in the world if 0
+	Stiffness  float64 // spring, force per pixel it is stretched (or squeezed) by
+	Damping    float64 // spring, force per pixel per tick the objects move apart (or together) at
+	BreakForce float64 // the joint breaks once it pulls (or pushes) harder than this in a tick, never if 0
+	Color      color.Color
+
+	active  bool      // both objects are in the world, the joint holds on to them
+	offset  pixel.Vec // weld, where B is from A, before A turned
+	angle   float64   // weld, the angle of B relative to A
+	impulse pixel.Vec // on B in the last tick, how hard the joint pulled or pushed
+}
+
+// JointEvent is sent when a joint breaks. Its data has:
+//
+//	broken: the name of the joint
+//	kind: the kind of joint
+//	object, other: the ids of A and B
+//	force: how hard the joint pulled (or pushed) when it broke
+//	tick: the world tick it broke at
+type JointEvent struct {
+	observer.BaseEvent
+}
+
+// NewJointEvent create a new joint event
+func NewJointEvent(d string, t time.Time, data ...observer.EventData) observer.Event {
+	e := &JointEvent{}
+	e.SetData(data)
+	e.SetDescription(d)
+	e.SetTime(t)
+
+	return e
+}
+
+// NewDistanceJoint returns a rope between a and b, they can't get further than length apart
+func NewDistanceJoint(name string, a, b Object, length float64) *Joint {
+	return &Joint{Name: name, Kind: JointDistance, A: a, B: b, Length: length, Color: colornames.Saddlebrown}
+}
+
+// NewSpringJoint returns a spring between a and b, length apart when at rest.
+// Stiff springs between light objects overshoot, stiffness / mass much above 1 goes wild.
+func NewSpringJoint(name string, a, b Object, length, stiffness, damping float64) *Joint {
+	return &Joint{
+		Name:      name,
+		Kind:      JointSpring,
+		A:         a,
+		B:         b,
+		Length:    length,
+		Stiffness: stiffness,
+		Damping:   damping,
+		Color:     colornames.Orange,
+	}
+}
+
+// NewWeldJoint returns a weld between a and b, they keep where they are to each other when it takes hold
+func NewWeldJoint(name string, a, b Object) *Joint {
+	return &Joint{Name: name, Kind: JointWeld, A: a, B: b, Color: colornames.Slategray}
+}
+
+// Validate returns an error if the joint makes no sense
+func (j *Joint) Validate() error {
+	if j.A == nil || j.B == nil {
+		return fmt.Errorf("joint [%v]: needs two objects", j.Name)
+	}
+	if j.A.ID() == j.B.ID() {
+		return fmt.Errorf("joint [%v]: joins [%v] to itself", j.Name, j.A.Name())
+	}
+	if inverseMass(j.A)+inverseMass(j.B) == 0 {
+		return fmt.Errorf("joint [%v]: joins two fixtures, [%v] and [%v]", j.Name, j.A.Name(), j.B.Name())
+	}
+
+	switch j.Kind {
+	case JointDistance, JointWeld:
+	case JointSpring:
+		if j.Stiffness <= 0 {
+			return fmt.Errorf("joint [%v]: invalid stiffness %v", j.Name, j.Stiffness)
+		}
+		if j.Damping < 0 {
+			return fmt.Errorf("joint [%v]: negative damping %v", j.Name, j.Damping)
+		}
+	default:
+		return fmt.Errorf("joint [%v]: unknown kind %q, want distance, spring or weld", j.Name, j.Kind)
+	}
+
+	if j.Length < 0 {
+		return fmt.Errorf("joint [%v]: negative length %v", j.Name, j.Length)
+	}
+	if j.BreakForce < 0 {
+		return fmt.Errorf("joint [%v]: negative break force %v", j.Name, j.BreakForce)
+	}
+	return nil
+}
+
+// Active returns true if the joint holds on to its objects, both are in the world
+func (j *Joint) Active() bool {
+	return j.active
+}
+
+// Force returns how hard the joint pulled (or pushed) in the last tick
+func (j *Joint) Force() float64 {
+	return j.impulse.Len()
+}
+
+// Draw draws the joint between the centers of its objects, springs as a zigzag
+func (j *Joint) Draw(r Renderer, alpha float64) {
+	if !j.active {
+		return
+	}
+	a, b := Interpolate(j.A, alpha).Center(), Interpolate(j.B, alpha).Center()
+
+	switch j.Kind {
+	case JointSpring:
+		const coils = 8
+		side := b.Sub(a).Normal()
+		if side != pixel.ZV {
+			side = side.Unit().Scaled(4)
+		}
+		points := []pixel.Vec{a}
+		for i := 1; i < 2*coils; i++ {
+			p := pixel.Lerp(a, b, float64(i)/(2*coils))
+			if i%2 == 0 {
+				points = append(points, p.Sub(side))
+			} else {
+				points = append(points, p.Add(side))
+			}
+		}
+		r.Line(append(points, b), j.Color, 1)
+	case JointWeld:
+		r.Line([]pixel.Vec{a, b}, j.Color, 3)
+	default:
+		r.Line([]pixel.Vec{a, b}, j.Color, 1)
+	}
+}
+
+// activate takes hold of the objects, where they are now
+func (j *Joint) activate() {
+	pa, pb := j.A.NextPhys(), j.B.NextPhys()
+	d := pb.Location().Center().Sub(pa.Location().Center())
+
+	if j.Length == 0 && j.Kind != JointWeld {
+		j.Length = d.Len()
+	}
+	j.offset = d.Rotated(-pa.Angle())
+	j.angle = math.Remainder(pb.Angle()-pa.Angle(), 2*math.Pi)
+	j.active = true
+}
+
+// Joints returns the joints in the world
+func (w *World) Joints() []*Joint {
+	return w.joints
+}
+
+// AddJoint adds a joint to the world, its objects (or fixtures) have to be in the world already.
+// It takes hold once both are spawned.
+func (w *World) AddJoint(j *Joint) error {
+	if err := j.Validate(); err != nil {
+		return err
+	}
+	for _, o := range []Object{j.A, j.B} {
+		if !w.contains(o) {
+			return fmt.Errorf("joint [%v]: [%v] is not in the world", j.Name, o.Name())
+		}
+	}
+	for _, other := range w.joints {
+		if j.Name == other.Name {
+			return fmt.Errorf("joint [%v] already exists", j.Name)
+		}
+	}
+	w.joints = append(w.joints, j)
+	return nil
+}
+
+// RemoveJoint removes j from the world, the objects are free again
+func (w *World) RemoveJoint(j *Joint) error {
+	for i, other := range w.joints {
+		if other == j {
+			w.joints = append(w.joints[:i], w.joints[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("joint [%v] is not in the world", j.Name)
+}
+
+// removeJoints removes the joints holding on to o, it is leaving the world
+func (w *World) removeJoints(o Object) {
+	joints := w.joints[:0]
+	for _, j := range w.joints {
+		if j.A.ID() != o.ID() && j.B.ID() != o.ID() {
+			joints = append(joints, j)
+		}
+	}
+	w.joints = joints
+}
+
+// contains returns true if o is an object or a fixture of the world
+func (w *World) contains(o Object) bool {
+	for _, other := range w.Objects {
+		if other.ID() == o.ID() {
+			return true
+		}
+	}
+	for _, f := range w.fixtures {
+		if f.ID() == o.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+// solveJoints changes the velocities of the next state of joined objects so they move the way their
+// joints let them, once all objects are updated. Joints pulling harder than their BreakForce break.
+func (w *World) solveJoints() {
+	var joints []*Joint
+	for _, j := range w.joints {
+		if !j.A.IsSpawned() || !j.B.IsSpawned() {
+			continue
+		}
+		if !j.active {
+			j.activate()
+		}
+		j.impulse = pixel.ZV
+		joints = append(joints, j)
+	}
+	if len(joints) == 0 {
+		return
+	}
+
+	// springs push and pull the same whatever the others do, ropes and welds settle together
+	for _, j := range joints {
+		if j.Kind == JointSpring {
+			w.solveSpring(j)
+		}
+	}
+	for i := 0; i < jointIterations; i++ {
+		for _, j := range joints {
+			switch j.Kind {
+			case JointDistance:
+				w.solveDistance(j)
+			case JointWeld:
+				w.solveWeld(j)
+			}
+		}
+	}
+
+	for _, j := range joints {
+		if j.BreakForce > 0 && j.Force() > j.BreakForce {
+			w.breakJoint(j)
+		}
+	}
+}
+
+// solveDistance stops the objects of a rope from getting further apart than its length, and pulls
+// them back together if they are
+func (w *World) solveDistance(j *Joint) {
+	pa, pb := j.A.NextPhys(), j.B.NextPhys()
+	d := pb.Location().Center().Sub(pa.Location().Center())
+	dist := d.Len()
+	if dist == 0 {
+		return
+	}
+	n := d.Scaled(1 / dist)
+
+	// how fast B may move away from A: up to the slack left, or back towards A if stretched
+	allowed := j.Length - dist
+	if allowed < 0 {
+		allowed = -w.jointBias(-allowed)
+	}
+	excess := pb.Vel().Sub(pa.Vel()).Dot(n) - allowed
+	if excess <= minJointVel {
+		return // slack
+	}
+	w.pushJoint(j, n.Scaled(-excess/(inverseMass(j.A)+inverseMass(j.B))))
+}
+
+// solveSpring pulls (or pushes) the objects of a spring towards its length, and damps how fast
+// they move apart or together
+func (w *World) solveSpring(j *Joint) {
+	pa, pb := j.A.NextPhys(), j.B.NextPhys()
+	d := pb.Location().Center().Sub(pa.Location().Center())
+	dist := d.Len()
+	if dist == 0 {
+		return
+	}
+	n := d.Scaled(1 / dist)
+
+	f := j.Stiffness*(dist-j.Length) + j.Damping*pb.Vel().Sub(pa.Vel()).Dot(n)
+	if math.Abs(f) <= minJointVel {
+		return
+	}
+	w.pushJoint(j, n.Scaled(-f))
+}
+
+// solveWeld keeps B where it was to A when the weld took hold, turning along with A, and makes them
+// spin together
+func (w *World) solveWeld(j *Joint) {
+	pa, pb := j.A.NextPhys(), j.B.NextPhys()
+
+	r := j.offset.Rotated(pa.Angle())
+	off := pb.Location().Center().Sub(pa.Location().Center().Add(r))
+	fix := pb.Vel().Sub(pointVel(pa.Vel(), pa.AngularVel(), r))
+	if e := off.Len(); e > 0 {
+		fix = fix.Add(off.Scaled(w.jointBias(e) / e))
+	}
+	if fix.Len() > minJointVel {
+		w.pushJoint(j, fix.Scaled(-1/(inverseMass(j.A)+inverseMass(j.B))))
+	}
+
+	iia, iib := inverseInertia(pa), inverseInertia(pb)
+	if iia+iib == 0 {
+		return
+	}
+	spin := pb.AngularVel() - pa.AngularVel()
+	if e := math.Remainder(pb.Angle()-pa.Angle()-j.angle, 2*math.Pi); math.Abs(e) > jointSlop {
+		spin += jointBias * e
+	}
+	if math.Abs(spin) <= minJointVel {
+		return
+	}
+	l := spin / (iia + iib)
+	pa.SetAngularVel(limitAngularVel(pa.AngularVel() + l*iia))
+	pb.SetAngularVel(limitAngularVel(pb.AngularVel() - l*iib))
+	w.wakeJoined(j)
+}
+
+// jointBias returns how fast a joint e out of shape pulls back into shape, by up to MaxAcceleration
+// per tick, so joints stretched out of shape (e.g. by a collision) don't yank their objects
+func (w *World) jointBias(e float64) float64 {
+	if e <= jointSlop {
+		return 0
+	}
+	return math.Min(jointBias*(e-jointSlop), w.MaxAcceleration)
+}
+
+// pushJoint applies the impulse p to B, and -p to A
+func (w *World) pushJoint(j *Joint, p pixel.Vec) {
+	pa, pb := j.A.NextPhys(), j.B.NextPhys()
+	if ia := inverseMass(j.A); ia > 0 {
+		pa.SetVel(w.limitSpeed(pa.Vel().Sub(p.Scaled(ia))))
+	}
+	if ib := inverseMass(j.B); ib > 0 {
+		pb.SetVel(w.limitSpeed(pb.Vel().Add(p.Scaled(ib))))
+	}
+	j.impulse = j.impulse.Add(p)
+	w.wakeJoined(j)
+}
+
+// wakeJoined wakes up the objects of j if they are asleep, the joint moves them
+func (w *World) wakeJoined(j *Joint) {
+	for _, o := range []Object{j.A, j.B} {
+		if w.Asleep(o) {
+			w.Wake(o)
+		}
+	}
+}
+
+// breakJoint removes j from the world and sends a JointEvent about it
+func (w *World) breakJoint(j *Joint) {
+	if err := w.RemoveJoint(j); err != nil {
+		return
+	}
+	j.active = false
+
+	w.Notify(NewJointEvent(fmt.Sprintf("joint [%v] between [%v] and [%v] broke", j.Name, j.A.Name(), j.B.Name()), w.clock.Now(),
+		observer.EventData{Key: "broken", Value: j.Name},
+		observer.EventData{Key: "kind", Value: string(j.Kind)},
+		observer.EventData{Key: "object", Value: j.A.ID().String()},
+		observer.EventData{Key: "other", Value: j.B.ID().String()},
+		observer.EventData{Key: "force", Value: strconv.FormatFloat(j.Force(), 'f', 3, 64)},
+		observer.EventData{Key: "tick", Value: strconv.FormatInt(w.clock.Ticks(), 10)}))
+}
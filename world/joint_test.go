@@ -0,0 +1,339 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/DanTulovsky/alphaville/observer"
+	"github.com/faiface/pixel"
+	"github.com/go-test/deep"
+	"golang.org/x/image/colornames"
+)
+
+func TestJoint_Validate(t *testing.T) {
+	a := NewRectObject("a", colornames.Red, 0, 1, 20, 20, nil)
+	b := NewRectObject("b", colornames.Blue, 0, 1, 20, 20, nil)
+	f := NewFixture("f", colornames.Gray, 20, 20)
+	g := NewFixture("g", colornames.Gray, 20, 20)
+
+	tests := []struct {
+		name  string
+		joint *Joint
+		err   string
+	}{
+		{name: "rope", joint: NewDistanceJoint("j", a, b, 50)},
+		{name: "rope as long as it gets", joint: NewDistanceJoint("j", a, b, 0)},
+		{name: "hanging from a fixture", joint: NewDistanceJoint("j", f, b, 50)},
+		{name: "spring", joint: NewSpringJoint("j", a, b, 50, 0.1, 0)},
+		{name: "weld", joint: NewWeldJoint("j", b, f)},
+		{name: "one object", joint: NewDistanceJoint("j", a, nil, 50), err: "needs two objects"},
+		{name: "to itself", joint: NewWeldJoint("j", a, a), err: "to itself"},
+		{name: "two fixtures", joint: NewDistanceJoint("j", f, g, 50), err: "two fixtures"},
+		{name: "negative length", joint: NewDistanceJoint("j", a, b, -1), err: "negative length"},
+		{name: "limp spring", joint: NewSpringJoint("j", a, b, 50, 0, 0), err: "invalid stiffness"},
+		{name: "negative damping", joint: NewSpringJoint("j", a, b, 50, 0.1, -1), err: "negative damping"},
+		{name: "negative break force", joint: &Joint{Name: "j", Kind: JointDistance, A: a, B: b, BreakForce: -1}, err: "negative break force"},
+		{name: "unknown", joint: &Joint{Name: "j", Kind: "glue", A: a, B: b}, err: "unknown kind"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.joint.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("expected a valid joint, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestWorld_AddJoint(t *testing.T) {
+	w := newTestWorld(1)
+	a := NewRectObject("a", colornames.Red, 0, 1, 20, 20, nil)
+	b := NewRectObject("b", colornames.Blue, 0, 1, 20, 20, nil)
+	if err := w.AddObject(a); err != nil {
+		t.Fatalf("failed to add object: %v", err)
+	}
+
+	if err := w.AddJoint(NewDistanceJoint("rope", a, b, 50)); err == nil || !strings.Contains(err.Error(), "not in the world") {
+		t.Errorf("expected an error joining an object not in the world, got: %v", err)
+	}
+	if err := w.AddObject(b); err != nil {
+		t.Fatalf("failed to add object: %v", err)
+	}
+	j := NewDistanceJoint("rope", a, b, 50)
+	if err := w.AddJoint(j); err != nil {
+		t.Fatalf("failed to add joint: %v", err)
+	}
+	if err := w.AddJoint(NewWeldJoint("rope", a, b)); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected an error adding a joint twice, got: %v", err)
+	}
+
+	// not spawned yet
+	w.Step()
+	if j.Active() {
+		t.Errorf("expected the joint to wait for its objects to spawn")
+	}
+
+	if err := w.RemoveJoint(j); err != nil {
+		t.Fatalf("failed to remove joint: %v", err)
+	}
+	if err := w.RemoveJoint(j); err == nil {
+		t.Errorf("expected an error removing a joint not in the world")
+	}
+}
+
+// addChain adds n objects hanging from a pivot fixture at (400, 500), each one on a rope from
+// the one before, laid out to the right. It returns the pivot and the links.
+func addChain(t *testing.T, w *World, n int, breakForce float64) (*Fixture, []Object) {
+	t.Helper()
+	pivot := NewFixture("pivot", colornames.Gray, 20, 20)
+	pivot.Place(pixel.V(390, 490))
+	if err := w.AddFixture(pivot); err != nil {
+		t.Fatalf("failed to add fixture: %v", err)
+	}
+
+	var links []Object
+	var prev Object = pivot
+	for i := 0; i < n; i++ {
+		l := NewRectObject(fmt.Sprintf("link-%v", i), colornames.Red, 0, 1, 20, 20, nil)
+		addRestingObject(t, w, l, pixel.V(float64(430+30*i), 500))
+		j := NewDistanceJoint(fmt.Sprintf("rope-%v", i), prev, l, 0)
+		j.BreakForce = breakForce
+		if err := w.AddJoint(j); err != nil {
+			t.Fatalf("failed to add joint: %v", err)
+		}
+		links = append(links, l)
+		prev = l
+	}
+	return pivot, links
+}
+
+// addCar adds a car driving right along the ground, centered at c
+func addCar(t *testing.T, w *World, c pixel.Vec) Object {
+	t.Helper()
+	car := NewRectObject("car", colornames.Red, 2, 1, 20, 20, nil)
+	addRestingObject(t, w, car, c)
+	for _, phys := range []ObjectPhys{car.Phys(), car.NextPhys()} {
+		phys.SetDesiredVel(pixel.V(car.Speed(), 0))
+	}
+	return car
+}
+
+// apart returns how far apart the centers of a and b are
+func apart(a, b Object) float64 {
+	return a.Phys().Location().Center().To(b.Phys().Location().Center()).Len()
+}
+
+func TestWorld_Joints(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, w *World) func() bool
+		ticks int
+		want  string
+	}{
+		{
+			name: "chain",
+			setup: func(t *testing.T, w *World) func() bool {
+				pivot, links := addChain(t, w, 3, 0)
+				return func() bool {
+					var prev Object = pivot
+					for _, l := range links {
+						below := prev.Phys().Location().Center().Y - l.Phys().Location().Center().Y
+						if apart(prev, l) > 30+0.5 || below < 29 {
+							return false
+						}
+						prev = l
+					}
+					return true
+				}
+			},
+			ticks: 300,
+			want:  "hanging below the pivot, one link under the other",
+		},
+		{
+			name: "slack rope",
+			setup: func(t *testing.T, w *World) func() bool {
+				a := NewRectObject("a", colornames.Red, 0, 1, 20, 20, nil)
+				b := NewRectObject("b", colornames.Blue, 0, 1, 20, 20, nil)
+				addRestingObject(t, w, a, pixel.V(200, 50))
+				addRestingObject(t, w, b, pixel.V(240, 50))
+				j := NewDistanceJoint("rope", a, b, 100)
+				if err := w.AddJoint(j); err != nil {
+					t.Fatalf("failed to add joint: %v", err)
+				}
+				return func() bool {
+					return j.Force() == 0 && a.Phys().Location().Center() == pixel.V(200, 50) &&
+						b.Phys().Location().Center() == pixel.V(240, 50)
+				}
+			},
+			ticks: 50,
+			want:  "left where they are",
+		},
+		{
+			name: "towed trailer",
+			setup: func(t *testing.T, w *World) func() bool {
+				car := addCar(t, w, pixel.V(200, 50))
+				trailer := NewRectObject("trailer", colornames.Blue, 0, 1, 20, 20, nil)
+				addRestingObject(t, w, trailer, pixel.V(160, 50))
+				if err := w.AddJoint(NewDistanceJoint("tow", car, trailer, 0)); err != nil {
+					t.Fatalf("failed to add joint: %v", err)
+				}
+				return func() bool {
+					return trailer.Phys().Location().Center().X > 260 && apart(car, trailer) < 40+0.5
+				}
+			},
+			ticks: 200,
+			want:  "pulled along behind the car",
+		},
+		{
+			name: "spring",
+			setup: func(t *testing.T, w *World) func() bool {
+				a := NewRectObject("a", colornames.Red, 0, 1, 20, 20, nil)
+				b := NewRectObject("b", colornames.Blue, 0, 1, 20, 20, nil)
+				addRestingObject(t, w, a, pixel.V(200, 50))
+				addRestingObject(t, w, b, pixel.V(400, 50))
+				if err := w.AddJoint(NewSpringJoint("spring", a, b, 60, 0.05, 0.2)); err != nil {
+					t.Fatalf("failed to add joint: %v", err)
+				}
+				return func() bool { return math.Abs(apart(a, b)-60) < 0.5 }
+			},
+			ticks: 200,
+			want:  "pulled together, to the length of the spring",
+		},
+		{
+			name: "welded",
+			setup: func(t *testing.T, w *World) func() bool {
+				car := addCar(t, w, pixel.V(200, 50))
+				box := NewRectObject("box", colornames.Blue, 0, 1, 20, 20, nil)
+				addRestingObject(t, w, box, pixel.V(175, 50))
+				if err := w.AddJoint(NewWeldJoint("weld", car, box)); err != nil {
+					t.Fatalf("failed to add joint: %v", err)
+				}
+				return func() bool {
+					d := box.Phys().Location().Center().Sub(car.Phys().Location().Center())
+					return car.Phys().Location().Center().X > 260 && d.Sub(pixel.V(-25, 0)).Len() < 0.5
+				}
+			},
+			ticks: 200,
+			want:  "moving with the car, where it was to it",
+		},
+		{
+			name: "welded to a turning fixture",
+			setup: func(t *testing.T, w *World) func() bool {
+				arm := addMovingFixture(t, w, "arm", pixel.V(350, 290), 100, 20, NewRotateMotion(pixel.V(400, 300), 0.02))
+				box := NewRectObject("box", colornames.Blue, 0, 1, 20, 20, nil)
+				addRestingObject(t, w, box, pixel.V(400, 340))
+				if err := w.AddJoint(NewWeldJoint("weld", arm, box)); err != nil {
+					t.Fatalf("failed to add joint: %v", err)
+				}
+				return func() bool {
+					// a quarter turn, from above the pivot to its left
+					want := pixel.V(400, 300).Add(pixel.V(0, 40).Rotated(arm.Phys().Angle()))
+					return box.Phys().Location().Center().Sub(want).Len() < 1 && want.X < 361 &&
+						math.Abs(box.Phys().Angle()-arm.Phys().Angle()) < 0.05
+				}
+			},
+			ticks: 79, // pi/2 / 0.02
+			want:  "turning around the pivot with the arm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			check := tt.setup(t, w)
+			for i := 0; i < tt.ticks; i++ {
+				w.Step()
+			}
+			if !check() {
+				var at []pixel.Vec
+				for _, o := range w.Objects {
+					at = append(at, o.Phys().Location().Center())
+				}
+				t.Errorf("expected the objects %v, they are at %v", tt.want, at)
+			}
+			if n := w.Stats.Overlaps; n != 0 {
+				t.Errorf("expected no overlaps, got %v", n)
+			}
+		})
+	}
+}
+
+func TestWorld_JointBreaks(t *testing.T) {
+	tests := []struct {
+		name       string
+		breakForce float64
+		want       bool // broken
+	}{
+		{name: "strong enough", breakForce: 2},
+		{name: "too weak", breakForce: 0.5, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			events := &eventLog{}
+			w.Register(events)
+			_, links := addChain(t, w, 1, tt.breakForce)
+			j := w.Joints()[0]
+
+			for i := 0; i < 300; i++ {
+				w.Step()
+			}
+
+			var got [][]observer.EventData
+			for _, e := range events.events {
+				if e, ok := e.(*JointEvent); ok {
+					got = append(got, e.Data())
+				}
+			}
+			if !tt.want {
+				if len(got) != 0 || len(w.Joints()) != 1 || w.Stats.JointsBroken != 0 {
+					t.Errorf("expected the joint to hold, got events %v", got)
+				}
+				return
+			}
+
+			want := [][]observer.EventData{{
+				{Key: "broken", Value: "rope-0"},
+				{Key: "kind", Value: string(JointDistance)},
+				{Key: "object", Value: j.A.ID().String()},
+				{Key: "other", Value: links[0].ID().String()},
+				{Key: "force", Value: "0.620"},
+				{Key: "tick", Value: "6"},
+			}}
+			if diff := deep.Equal(got, want); diff != nil {
+				t.Errorf("expected joint events %v, got %v: %v", want, got, diff)
+			}
+			if len(w.Joints()) != 0 || j.Active() {
+				t.Errorf("expected the joint gone, got %v", w.Joints())
+			}
+			if w.Stats.JointsBroken != 1 {
+				t.Errorf("expected 1 joint broken in the stats, got %v", w.Stats.JointsBroken)
+			}
+			if l := links[0].Phys().Location(); l.Min.Y > w.groundTop()+1 {
+				t.Errorf("expected the link to fall to the ground, it is at %v", l)
+			}
+		})
+	}
+}
+
+func TestWorld_RemoveObjectRemovesJoints(t *testing.T) {
+	w := newTestWorld(1)
+	_, links := addChain(t, w, 3, 0)
+
+	if err := w.RemoveObject(links[1]); err != nil {
+		t.Fatalf("failed to remove object: %v", err)
+	}
+	if got := len(w.Joints()); got != 1 || w.Joints()[0].B.ID() != links[0].ID() {
+		t.Errorf("expected only the rope to the first link left, got %v", w.Joints())
+	}
+}
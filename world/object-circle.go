@@ -7,12 +7,9 @@ import (
 	"image/color"
 	"log"
 
-	"github.com/DanTulovsky/alphaville/utils"
 	"golang.org/x/image/colornames"
 
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
-	"github.com/faiface/pixel/text"
 )
 
 // CircleObject is a rectangular object
@@ -74,29 +71,20 @@ func (o *CircleObject) BoundingBox(c pixel.Vec) pixel.Rect {
 	return pixel.R(min.X, min.Y, max.X, max.Y)
 }
 
+// Shape returns ShapeCircle
+func (o *CircleObject) Shape() Shape {
+	return ShapeCircle
+}
+
 // Draw a rectangle of size width, height inside bounding box set in Phys()
-func (o *CircleObject) Draw(win *pixelgl.Window) {
+func (o *CircleObject) Draw(r Renderer, alpha float64) {
 	if !o.IsSpawned() {
 		return
 	}
 
-	o.imd.Clear()
-	o.imd.Reset()
-	o.imd.Color = o.color
-
-	center := o.Phys().Location().Center()
-
-	o.imd.Push(center)
-	o.imd.Circle(o.radius, 0)
-	o.imd.Draw(win)
+	center := Interpolate(o, alpha).Center()
+	r.Circle(center, o.radius, o.color, 0)
 
 	// draw name of the object
-	txt := text.New(pixel.V(o.Phys().Location().Center().XY()), utils.Atlas())
-	txt.Color = colornames.Black
-
-	// center the text
-	txt.Dot.X -= txt.BoundsOf(o.name).W() / 2
-
-	fmt.Fprintf(txt, "%v", o.name)
-	txt.Draw(win, pixel.IM)
+	r.Text(center, colornames.Black, o.name)
 }
@@ -7,12 +7,9 @@ import (
 	"image/color"
 	"log"
 
-	"github.com/DanTulovsky/alphaville/utils"
 	"golang.org/x/image/colornames"
 
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
-	"github.com/faiface/pixel/text"
 )
 
 // EllipseObject is a rectangular object
@@ -76,32 +73,20 @@ func (o *EllipseObject) BoundingBox(c pixel.Vec) pixel.Rect {
 	return pixel.R(min.X, min.Y, max.X, max.Y)
 }
 
+// Shape returns ShapeEllipse
+func (o *EllipseObject) Shape() Shape {
+	return ShapeEllipse
+}
+
 // Draw an ellipse of size width, height inside bounding box set in Phys()
-func (o *EllipseObject) Draw(win *pixelgl.Window) {
+func (o *EllipseObject) Draw(r Renderer, alpha float64) {
 	if !o.IsSpawned() {
 		return
 	}
 
-	o.imd.Clear()
-	o.imd.Reset()
-	o.imd.Color = o.color
-
-	center := o.Phys().Location().Center()
-
-	// mat := pixel.IM
-
-	// o.imd.SetMatrix(mat)
-	o.imd.Push(center)
-	o.imd.Ellipse(pixel.V(o.b, o.a), 0)
-	o.imd.Draw(win)
+	center := Interpolate(o, alpha).Center()
+	r.Ellipse(center, pixel.V(o.b, o.a), InterpolateAngle(o, alpha), o.color, 0)
 
 	// draw name of the object
-	txt := text.New(pixel.V(o.Phys().Location().Center().XY()), utils.Atlas())
-	txt.Color = colornames.Black
-
-	// center the text
-	txt.Dot.X -= txt.BoundsOf(o.name).W() / 2
-
-	fmt.Fprintf(txt, "%v", o.name)
-	txt.Draw(win, pixel.IM)
+	r.Text(center, colornames.Black, o.name)
 }
@@ -2,11 +2,11 @@ package world
 
 import (
 	"image/color"
+	"time"
 
+	"github.com/DanTulovsky/alphaville/observer"
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
 	"github.com/google/uuid"
-	"github.com/DanTulovsky/alphaville/observer"
 	"golang.org/x/image/colornames"
 )
 
@@ -34,7 +34,7 @@ func (o *NullObject) Size() pixel.Rect {
 }
 
 // Draw does nothing
-func (o *NullObject) Draw(*pixelgl.Window) {}
+func (o *NullObject) Draw(Renderer, float64) {}
 
 // Behavior always returns nil
 func (o *NullObject) Behavior() Behavior {
@@ -61,6 +61,43 @@ func (o *NullObject) Mass() float64 {
 	return -1
 }
 
+// Lifetime always returns 0
+func (o *NullObject) Lifetime() time.Duration {
+	return 0
+}
+
+// SetLifetime does nothing
+func (o *NullObject) SetLifetime(time.Duration) {}
+
+// Restitution always returns 0
+func (o *NullObject) Restitution() float64 {
+	return 0
+}
+
+// SetRestitution does nothing
+func (o *NullObject) SetRestitution(float64) {}
+
+// GravityScale always returns 0
+func (o *NullObject) GravityScale() float64 {
+	return 0
+}
+
+// SetGravityScale does nothing
+func (o *NullObject) SetGravityScale(float64) {}
+
+// Shape always returns ShapeRect
+func (o *NullObject) Shape() Shape {
+	return ShapeRect
+}
+
+// SpawnTime always returns the zero time
+func (o *NullObject) SpawnTime() time.Time {
+	return time.Time{}
+}
+
+// SetSpawnTime does nothing
+func (o *NullObject) SetSpawnTime(time.Time) {}
+
 // NextPhys always returns nil
 func (o *NullObject) NextPhys() ObjectPhys {
 	return nil
@@ -7,12 +7,9 @@ import (
 	"image/color"
 	"log"
 
-	"github.com/DanTulovsky/alphaville/utils"
 	"golang.org/x/image/colornames"
 
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
-	"github.com/faiface/pixel/text"
 )
 
 // RectObject is a rectangular object
@@ -89,32 +86,21 @@ func (o *RectObject) BoundingBox(c pixel.Vec) pixel.Rect {
 }
 
 // Draw a rectangle of size width, height inside bounding box set in Phys()
-func (o *RectObject) Draw(win *pixelgl.Window) {
+func (o *RectObject) Draw(r Renderer, alpha float64) {
 
 	if !o.IsSpawned() {
 		return
 	}
 
-	o.imd.Clear()
-	o.imd.Reset()
-	o.imd.Color = o.color
-
-	box := o.BoundingBox(o.Phys().Location().Center())
-
-	// matrix to manipulate shape
-	mat := pixel.IM
-
-	o.imd.SetMatrix(mat)
-	o.imd.Push(box.Min)
-	o.imd.Push(box.Max)
-	o.imd.Rectangle(0)
-	o.imd.Draw(win)
+	center := Interpolate(o, alpha).Center()
+	box := o.BoundingBox(center)
+	if angle := InterpolateAngle(o, alpha); angle != 0 {
+		r.Polygon(Body{Shape: ShapeRect, Rect: box, Angle: angle}.Polygon(), o.color, 0)
+	} else {
+		r.Rect(box, o.color, 0)
+	}
 
 	// draw name of the object
-	txt := text.New(pixel.V(o.Phys().Location().Center().XY()), utils.Atlas())
-	txt.Color = colornames.Black
-
-	// center the text
 	label := []string{o.name}
 
 	switch b := o.behavior.(type) {
@@ -122,10 +108,5 @@ func (o *RectObject) Draw(win *pixelgl.Window) {
 		label = append(label, fmt.Sprintf("%v, %v", b.TargetsCaught(), b.TurnsBlocked()))
 	}
 
-	for _, l := range label {
-		txt.Dot.X -= txt.BoundsOf(l).W() / 2
-		fmt.Fprintf(txt, "%v\n", l)
-	}
-
-	txt.Draw(win, pixel.IM)
+	r.Text(center, colornames.Black, label...)
 }
@@ -1,10 +1,10 @@
 package world
 
 import (
-	"fmt"
 	"image/color"
 	"io"
 	"log"
+	"math"
 	"strings"
 	"time"
 
@@ -14,12 +14,8 @@ import (
 	clr "github.com/fatih/color"
 
 	"github.com/DanTulovsky/alphaville/observer"
-	"github.com/DanTulovsky/alphaville/utils"
 
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/imdraw"
-	"github.com/faiface/pixel/pixelgl"
-	"github.com/faiface/pixel/text"
 	"github.com/google/uuid"
 )
 
@@ -31,22 +27,31 @@ type Object interface {
 	BoundingBox(pixel.Vec) pixel.Rect
 	CheckIntersect(*World)
 	Color() color.Color
-	Draw(*pixelgl.Window)
+	Draw(Renderer, float64) // float64 is the interpolation factor between Phys() and NextPhys()
+	GravityScale() float64  // how strongly gravity (and gravity wells) pull the object, 1 is normal
 	ID() uuid.UUID
 	IsSpawned() bool
+	Lifetime() time.Duration // how long the object stays in the world once spawned, forever if 0
 	Mass() float64
 	NextPhys() ObjectPhys // returns the NextPhys object
 	Name() string
-	Phys() ObjectPhys // returns the Phys object
-	Size() pixel.Rect // size of bounding box
+	Phys() ObjectPhys     // returns the Phys object
+	Restitution() float64 // how bouncy the object is, 0 (not at all) to 1 (elastic collisions)
+	Shape() Shape         // outline of the object inside its bounding box
+	Size() pixel.Rect     // size of bounding box
 	Speed() float64
+	SpawnTime() time.Time // world clock time the object spawned at
 	SwapNextState()
-	Update(*World) // Updates the object for the next iteration
+	Update(*World) // Updates the object for the next iteration, see world-update.go for what it may touch
 
+	SetLifetime(time.Duration)
 	SetName(string)
 	SetManualVelocity(pixel.Vec)
+	SetGravityScale(float64)
 	SetNextPhys(ObjectPhys)
 	SetPhys(ObjectPhys)
+	SetRestitution(float64)
+	SetSpawnTime(time.Time)
 }
 
 // ObjectEvent implements the observer.Event interface to send events to other components
@@ -72,15 +77,17 @@ type BaseObject struct {
 	color       color.Color
 
 	// initial Speed and Mass of BaseObject
-	speed float64 // horizontal Speed (negative means move left)
-	mass  float64
-
-	// draws the BaseObject
-	imd *imdraw.IMDraw
+	speed        float64 // horizontal Speed (negative means move left)
+	mass         float64
+	restitution  float64 // fraction of the closing speed objects part with after a collision
+	gravityScale float64 // multiplies the world gravity for this object
 
 	// initial location of the BaseObject (bottom left corner)
 	IX, IY float64
 
+	lifetime  time.Duration // despawn this long after spawning, never if 0
+	spawnTime time.Time     // world clock time of the spawn
+
 	// physics properties of the BaseObject
 	phys     ObjectPhys
 	nextPhys ObjectPhys // State of the object in the next round
@@ -102,7 +109,8 @@ func NewBaseObject(name string, color color.Color, speed, mass float64) BaseObje
 	o.color = color
 	o.speed = speed
 	o.mass = mass
-	o.imd = imdraw.New(nil)
+	o.restitution = DefaultRestitution
+	o.gravityScale = 1
 	o.phys = nil
 
 	return o
@@ -114,6 +122,11 @@ func (o *BaseObject) BoundingBox(v pixel.Vec) pixel.Rect {
 	return pixel.R(0, 0, 0, 0)
 }
 
+// Shape returns ShapeRect, objects with other shapes override it
+func (o *BaseObject) Shape() Shape {
+	return ShapeRect
+}
+
 // Size returns the object's bounding box
 func (o *BaseObject) Size() pixel.Rect {
 	if o.Phys() != nil {
@@ -152,11 +165,51 @@ func (o *BaseObject) Mass() float64 {
 	return o.mass
 }
 
+// Restitution returns how bouncy the object is, from 0 (not at all) to 1 (elastic collisions)
+func (o *BaseObject) Restitution() float64 {
+	return o.restitution
+}
+
+// SetRestitution sets how bouncy the object is, from 0 (not at all) to 1 (elastic collisions)
+func (o *BaseObject) SetRestitution(e float64) {
+	o.restitution = e
+}
+
+// GravityScale returns how strongly gravity pulls the object, 1 is normal and 0 not at all
+func (o *BaseObject) GravityScale() float64 {
+	return o.gravityScale
+}
+
+// SetGravityScale sets how strongly gravity pulls the object, 1 is normal and 0 not at all
+func (o *BaseObject) SetGravityScale(g float64) {
+	o.gravityScale = g
+}
+
 // ID returns the object's ID
 func (o *BaseObject) ID() uuid.UUID {
 	return o.id
 }
 
+// Lifetime returns how long the object stays in the world once spawned, 0 means forever
+func (o *BaseObject) Lifetime() time.Duration {
+	return o.lifetime
+}
+
+// SetLifetime sets how long the object stays in the world once spawned, 0 means forever
+func (o *BaseObject) SetLifetime(l time.Duration) {
+	o.lifetime = l
+}
+
+// SpawnTime returns the world clock time the object spawned at
+func (o *BaseObject) SpawnTime() time.Time {
+	return o.spawnTime
+}
+
+// SetSpawnTime sets the world clock time the object spawned at
+func (o *BaseObject) SetSpawnTime(t time.Time) {
+	o.spawnTime = t
+}
+
 // Behavior return the behavior object
 func (o *BaseObject) Behavior() Behavior {
 	return o.behavior
@@ -195,6 +248,11 @@ func (o *BaseObject) IsSpawned() bool {
 // Update the Object every frame
 func (o *BaseObject) Update(w *World) {
 	o.Behavior().Update(w, o)
+	w.carry(o)
+	o.NextPhys().Rotate(w)
+	w.applyZones(o.NextPhys())
+	// the velocity for the next tick, others check collisions against it
+	o.NextPhys().Integrate(w)
 	o.CheckIntersect(w)
 }
 
@@ -205,28 +263,61 @@ func (o *BaseObject) SwapNextState() {
 	}
 }
 
-// CheckIntersect prints out an error if this object intersects with another one
+// CheckIntersect prints out an error, and sends a CollisionOverlap event, if this object intersects with another one
 func (o *BaseObject) CheckIntersect(w *World) {
-	cobjects, _ := w.CollisionObjects()
-	for _, other := range cobjects {
+	// others are checked where they are now, as the quadtree has them, fixtures where they are going
+	for _, other := range w.objectsIntersecting(o.NextPhys().Bounds()) {
 		if o.ID() == other.ID() {
 			continue // skip yourself
 		}
-		if o.NextPhys().Location().Intersect(other.Phys().Location()) != pixel.R(0, 0, 0, 0) {
-			log.Printf("%#+v (%v) intersects with %#v (%v)", o.name, o.NextPhys(), other.Name(), other.Phys())
+		op := other.Phys()
+		if _, ok := other.(*Fixture); ok {
+			op = other.NextPhys()
+		}
+		if o.NextPhys().Bounds().Intersect(op.Bounds()) != pixel.R(0, 0, 0, 0) &&
+			ShapesOverlap(o.NextPhys().Body(), op.Body()) {
+			other := other
+			// the other object may be updating right now
+			w.Defer(o, func() {
+				log.Printf("%#+v (%v) intersects with %#v (%v)", o.name, o.NextPhys(), other.Name(), other.Phys())
+				p := o.NextPhys()
+				n := other.Phys().Location().Center().Sub(p.Location().Center())
+				if n != pixel.ZV {
+					n = n.Unit()
+				}
+				w.notifyCollision(CollisionOverlap, o, other, p.LocationOf(other), n, p.Vel().Sub(other.Phys().Vel()))
+			})
 			// log.Fatal("broken")
 		}
 	}
 }
 
 // Draw must be implemented by concrete objects
-func (o *BaseObject) Draw(win *pixelgl.Window) {
+func (o *BaseObject) Draw(r Renderer, alpha float64) {
+	r.Text(Interpolate(o, alpha).Center(), colornames.Red, "IMPLEMENT ME!")
+}
+
+// Interpolate returns the location of o alpha (in [0, 1]) of the way from its current
+// location (Phys) to its next one (NextPhys). Used to draw smooth movement between ticks.
+// An object wrapping around the world goes the short way, off one edge, rather than across the world.
+func Interpolate(o Object, alpha float64) pixel.Rect {
+	current := o.Phys().Location()
+	if o.NextPhys() == nil {
+		return current
+	}
+	next := nearest(current.Min, o.NextPhys().Location().Min, o.NextPhys().WrapSize())
 
-	// draw name of the object
-	txt := text.New(pixel.V(o.Phys().Location().Center().XY()), utils.Atlas())
-	txt.Color = colornames.Red
-	fmt.Fprintf(txt, "IMPLEMENT ME!")
-	txt.Draw(win, pixel.IM)
+	return current.Moved(next.Sub(current.Min).Scaled(alpha))
+}
+
+// InterpolateAngle returns the angle of o alpha (in [0, 1]) of the way from its current angle to its next one,
+// turning the short way round, see Interpolate
+func InterpolateAngle(o Object, alpha float64) float64 {
+	current := o.Phys().Angle()
+	if o.NextPhys() == nil {
+		return current
+	}
+	return current + math.Remainder(o.NextPhys().Angle()-current, 2*math.Pi)*alpha
 }
 
 // SetManualVelocity sets the velocity of the manually controlled object
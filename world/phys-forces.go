@@ -0,0 +1,110 @@
+package world
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+const (
+	// DefaultDrag is the fraction of its velocity an object loses every tick
+	DefaultDrag = 0.02
+	// DefaultFriction is the speed an object sliding along the ground or a fixture loses every tick
+	DefaultFriction = 0.2
+	// DefaultMaxAcceleration is the most an object can change its own velocity by in a tick
+	DefaultMaxAcceleration = 1.0
+
+	// contactGap is how close an object has to be to a surface to rub against it
+	contactGap = 1.0
+)
+
+// DesiredVel returns the velocity the object wants to move at
+func (o *BaseObjectPhys) DesiredVel() pixel.Vec {
+	return o.desiredVel
+}
+
+// SetDesiredVel sets the velocity the object wants to move at.
+// The object accelerates towards it, as fast as the world allows, see Integrate.
+func (o *BaseObjectPhys) SetDesiredVel(v pixel.Vec) {
+	o.desiredVel = v
+}
+
+// Force returns the sum of the forces applied to the object since the last Integrate
+func (o *BaseObjectPhys) Force() pixel.Vec {
+	return o.force
+}
+
+// ApplyForce applies f to the object, it changes the velocity on the next Integrate
+func (o *BaseObjectPhys) ApplyForce(f pixel.Vec) {
+	o.force = o.force.Add(f)
+}
+
+// Integrate advances the velocity of the object by one tick:
+// drag and friction slow it down, the applied forces accelerate it (by force / mass), and the object
+// accelerates towards its desired velocity, by up to w.MaxAcceleration.
+// The speed is clamped to w.MaxObjectSpeed and the applied forces are cleared.
+// The angular velocity changes the same way, by the applied torques.
+func (o *BaseObjectPhys) Integrate(w *World) {
+	v := o.vel.Scaled(1 - w.Drag)
+
+	below, side := o.contacts(w)
+	if below {
+		v.X = slowDown(v.X, w.Friction)
+	}
+	if side {
+		v.Y = slowDown(v.Y, w.Friction)
+	}
+
+	mass := o.parentObject.Mass()
+	if mass <= 0 {
+		mass = 1
+	}
+	v = v.Add(o.force.Scaled(1 / mass))
+
+	drive := o.desiredVel.Sub(v)
+	if drive.Len() > w.MaxAcceleration {
+		drive = drive.Unit().Scaled(w.MaxAcceleration)
+	}
+	v = v.Add(drive)
+
+	o.vel = w.limitSpeed(v)
+	o.force = pixel.ZV
+
+	o.integrateAngular(w, below)
+}
+
+// slowDown returns v reduced by up to by, without changing its sign
+func slowDown(v, by float64) float64 {
+	if math.Abs(v) <= by {
+		return 0
+	}
+	return v - math.Copysign(by, v)
+}
+
+// contacts returns whether the object rests on the ground or a fixture (below), and whether it
+// touches the side of a fixture (side)
+func (o *BaseObjectPhys) contacts(w *World) (below, side bool) {
+	r := o.Bounds()
+	if w.Ground != nil && w.Ground.Phys() != nil && gap(w.Ground.Phys().Location().Max.Y, r.Min.Y) {
+		below = true
+	}
+
+	for _, f := range w.Fixtures() {
+		fr := f.Phys().Location()
+		overlapX := r.Min.X < fr.Max.X && fr.Min.X < r.Max.X
+		overlapY := r.Min.Y < fr.Max.Y && fr.Min.Y < r.Max.Y
+
+		if overlapX && gap(fr.Max.Y, r.Min.Y) {
+			below = true
+		}
+		if overlapY && (gap(fr.Max.X, r.Min.X) || gap(r.Max.X, fr.Min.X)) {
+			side = true
+		}
+	}
+	return below, side
+}
+
+// gap returns true if to is just above (or right of) from, within contactGap
+func gap(from, to float64) bool {
+	return to >= from && to-from <= contactGap
+}
@@ -0,0 +1,127 @@
+package world
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/go-test/deep"
+	"golang.org/x/image/colornames"
+)
+
+func TestBaseObjectPhys_Integrate(t *testing.T) {
+	inAir := pixel.R(100, 300, 120, 320)
+	onGround := pixel.R(100, 40, 120, 60)
+
+	tests := []struct {
+		name     string
+		mass     float64
+		rect     pixel.Rect
+		vel      pixel.Vec
+		desired  pixel.Vec
+		force    pixel.Vec
+		drag     float64
+		friction float64
+		accel    float64
+		want     pixel.Vec
+	}{
+		{name: "reaches desired velocity", mass: 1, rect: inAir, desired: pixel.V(0.5, 0), accel: 1, want: pixel.V(0.5, 0)},
+		{name: "acceleration is limited", mass: 1, rect: inAir, desired: pixel.V(3, 0), accel: 1, want: pixel.V(1, 0)},
+		{name: "slows down to desired velocity", mass: 1, rect: inAir, vel: pixel.V(0, -3), accel: 1, want: pixel.V(0, -2)},
+		{name: "force over mass", mass: 2, rect: inAir, force: pixel.V(4, 0), want: pixel.V(2, 0)},
+		{name: "heavy objects accelerate less", mass: 4, rect: inAir, force: pixel.V(4, 0), want: pixel.V(1, 0)},
+		{name: "drive works against forces", mass: 1, rect: inAir, force: pixel.V(3, 0), accel: 1, want: pixel.V(2, 0)},
+		{name: "drag", mass: 1, rect: inAir, vel: pixel.V(2, -2), drag: 0.5, want: pixel.V(1, -1)},
+		{name: "friction on the ground", mass: 1, rect: onGround, vel: pixel.V(3, 0), friction: 0.5, want: pixel.V(2.5, 0)},
+		{name: "friction stops, does not reverse", mass: 1, rect: onGround, vel: pixel.V(-0.2, 0), friction: 0.5, want: pixel.V(0, 0)},
+		{name: "no friction in the air", mass: 1, rect: inAir, vel: pixel.V(3, 0), friction: 0.5, want: pixel.V(3, 0)},
+		{name: "friction against a fixture side", mass: 1, rect: pixel.R(100, 200, 120, 220), vel: pixel.V(0, 2), friction: 0.5, want: pixel.V(0, 1.5)},
+		{name: "friction on a fixture top", mass: 1, rect: pixel.R(125, 300.5, 145, 320.5), vel: pixel.V(2, 0), friction: 0.5, want: pixel.V(1.5, 0)},
+		{name: "clamped to max speed", mass: 1, rect: inAir, vel: pixel.V(0, 4), desired: pixel.V(0, 10), accel: 2, want: pixel.V(0, 4)},
+		{name: "clamped to max speed diagonally", mass: 1, rect: inAir, force: pixel.V(30, 40), want: pixel.V(2.4, 3.2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			w.Drag, w.Friction, w.MaxAcceleration = tt.drag, tt.friction, tt.accel
+
+			f := NewFixture("wall", colornames.Gray, 40, 100)
+			f.Place(pixel.V(120, 200)) // up to 300 high
+			if err := w.AddFixture(f); err != nil {
+				t.Fatalf("failed to add fixture: %v", err)
+			}
+
+			o := NewRectObject("o", colornames.Red, 3, tt.mass, 20, 20, nil)
+			phys := NewBaseObjectPhys(tt.rect, o)
+			phys.SetVel(tt.vel)
+			phys.SetDesiredVel(tt.desired)
+			phys.ApplyForce(tt.force)
+
+			phys.Integrate(w)
+			if diff := deep.Equal(phys.Vel(), tt.want); diff != nil {
+				t.Errorf("expected velocity %v, got %v: %v", tt.want, phys.Vel(), diff)
+			}
+			if phys.Force() != pixel.ZV {
+				t.Errorf("expected forces cleared, got %v", phys.Force())
+			}
+		})
+	}
+}
+
+func TestWorld_ManualObjectAccelerates(t *testing.T) {
+	w := newTestWorld(1)
+	w.Drag = 0
+	o := NewRectObject("manual", colornames.Red, 3, 1, 20, 20, NewManualBehavior())
+	if err := w.AddObject(o); err != nil {
+		t.Fatalf("failed to add object: %v", err)
+	}
+	w.ManualControl = o
+	if err := w.AddGate(NewGate("gate", pixel.V(400, 300), GateOpen, 0, 20)); err != nil {
+		t.Fatalf("failed to add gate: %v", err)
+	}
+	w.Step()
+	if !o.IsSpawned() {
+		t.Fatalf("object not spawned")
+	}
+
+	w.SetManualVelocity(pixel.V(1, 0))
+	got := []float64{}
+	for i := 0; i < 5; i++ {
+		w.Step()
+		got = append(got, o.NextPhys().Vel().X) // the velocity for the next tick
+	}
+	want := []float64{1, 2, 3, 3, 3}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("expected speeds %v, got %v: %v", want, got, diff)
+	}
+}
+
+func TestWorld_MaxObjectSpeed(t *testing.T) {
+	w := newTestWorld(1)
+	for i := 0; i < 6; i++ {
+		o := NewRectObject(fmt.Sprintf("%v", i), colornames.Red, 10, 1, 20, 20, nil) // faster than allowed
+		if err := w.AddObject(o); err != nil {
+			t.Fatalf("failed to add object: %v", err)
+		}
+	}
+	if err := w.AddObject(NewRectObject("ts", colornames.Red, 10, 1, 30, 30, NewTargetSeekerBehavior(&DijkstraPathFinder{}))); err != nil {
+		t.Fatalf("failed to add object: %v", err)
+	}
+	for i, x := range []float64{100, 400, 700} {
+		if err := w.AddGate(NewGate(fmt.Sprintf("%v", i), pixel.V(x, 100), GateOpen, 0, 10)); err != nil {
+			t.Fatalf("failed to add gate: %v", err)
+		}
+	}
+	if err := w.AddTarget(NewSimpleTarget("target", pixel.V(600, 500), 10, "target")); err != nil {
+		t.Fatalf("failed to add target: %v", err)
+	}
+
+	for i := 0; i < 300; i++ {
+		w.Step()
+		for _, o := range w.SpawnedObjects() {
+			if speed := o.Phys().Vel().Len(); speed > w.MaxObjectSpeed+1e-9 {
+				t.Fatalf("tick %v: [%v] moving at %v, max is %v", i, o.Name(), speed, w.MaxObjectSpeed)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package world
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// DefaultRestitution is how bouncy objects are unless set otherwise, see Object.Restitution
+const DefaultRestitution = 0.5
+
+// Collision is a collision an object runs into if it keeps moving as it is
+type Collision struct {
+	Other    Object
+	Location string  // where Other is: above, below, left, right, or "" if they already overlap
+	Contact  Contact // where they touch, see ShapeCollision
+}
+
+// Normal returns the unit vector from o towards c.Other, along which they push each other
+func (c Collision) Normal(o Object) pixel.Vec {
+	if c.Contact.Normal != pixel.ZV {
+		return c.Contact.Normal
+	}
+
+	switch c.Location {
+	case "above":
+		return pixel.V(0, 1)
+	case "below":
+		return pixel.V(0, -1)
+	case "left":
+		return pixel.V(-1, 0)
+	case "right":
+		return pixel.V(1, 0)
+	}
+
+	// overlapping, push apart along the axis the centers are furthest apart on
+	d := c.Other.Phys().Location().Center().Sub(o.Phys().Location().Center())
+	if math.Abs(d.X) > math.Abs(d.Y) {
+		return pixel.V(math.Copysign(1, d.X), 0)
+	}
+	return pixel.V(0, math.Copysign(1, d.Y))
+}
+
+// Arms returns the vectors from the centers of o and c.Other to the contact point, at the time they touch.
+// Both are zero if there is no contact point.
+func (c Collision) Arms(o Object) (ra, rb pixel.Vec) {
+	if c.Contact.Normal == pixel.ZV {
+		return pixel.ZV, pixel.ZV
+	}
+	at := func(p ObjectPhys) pixel.Vec { return p.Location().Center().Add(p.Vel().Scaled(c.Contact.T)) }
+	return c.Contact.Point.Sub(at(o.NextPhys())), c.Contact.Point.Sub(at(c.Other.Phys()))
+}
+
+// ResolveCollisions bounces o off the objects it collides with, once all objects are updated.
+// Both objects of a collision get an impulse, so it doesn't matter which of them reports it.
+// Each bounce is sent to the observers of the world as a CollisionEvent.
+func (w *World) ResolveCollisions(o Object, collisions []Collision) {
+	for _, c := range collisions {
+		c := c
+		ra, rb := c.Arms(o)
+		w.Defer(o, func() {
+			n := c.Normal(o)
+			rel := o.NextPhys().Vel().Sub(c.Other.NextPhys().Vel())
+			if w.resolveCollision(o, c.Other, n, ra, rb) {
+				w.notifyCollision(CollisionContact, o, c.Other, c.Location, n, rel)
+			}
+		})
+	}
+}
+
+// resolveCollision applies equal and opposite impulses to a and b, n is the normal from a to b.
+// ra and rb are the vectors from the centers of a and b to where they touch, an impulse off the center
+// spins them too.
+// The impulse conserves momentum and leaves the contact points parting at their closing speed times the
+// restitution of the less bouncy one. Fixtures don't move, they are too heavy.
+// It returns false if there is nothing to resolve.
+func (w *World) resolveCollision(a, b Object, n, ra, rb pixel.Vec) bool {
+	ia, ib := inverseMass(a), inverseMass(b)
+	if ia+ib == 0 {
+		return false
+	}
+	pa, pb := a.NextPhys(), b.NextPhys()
+	iia, iib := inverseInertia(pa), inverseInertia(pb)
+	va, vb := pa.Vel(), pb.Vel()
+	closing := pointVel(va, pa.AngularVel(), ra).Sub(pointVel(vb, pb.AngularVel(), rb)).Dot(n)
+	if closing <= 0 {
+		return false // already parting, resolved from the other side
+	}
+
+	rna, rnb := cross(ra, n), cross(rb, n)
+	e := math.Min(a.Restitution(), b.Restitution())
+	j := (1 + e) * closing / (ia + ib + iia*rna*rna + iib*rnb*rnb)
+
+	pa.SetVel(w.limitSpeed(va.Sub(n.Scaled(j * ia))))
+	pa.SetAngularVel(limitAngularVel(pa.AngularVel() - iia*rna*j))
+	if ib > 0 {
+		pb.SetVel(w.limitSpeed(vb.Add(n.Scaled(j * ib))))
+		pb.SetAngularVel(limitAngularVel(pb.AngularVel() + iib*rnb*j))
+	}
+	return true
+}
+
+// pointVel returns the velocity of the point at r from the center of an object moving at v, spinning at av
+func pointVel(v pixel.Vec, av float64, r pixel.Vec) pixel.Vec {
+	return v.Add(pixel.V(-av*r.Y, av*r.X))
+}
+
+// cross returns the z component of the cross product of a and b
+func cross(a, b pixel.Vec) float64 {
+	return a.X*b.Y - a.Y*b.X
+}
+
+// inverseMass returns 1 / mass of o, 0 for fixtures that can't be moved
+func inverseMass(o Object) float64 {
+	m := o.Mass()
+	switch {
+	case m == math.MaxFloat64:
+		return 0
+	case m <= 0:
+		return 1 // like Integrate
+	}
+	return 1 / m
+}
+
+// limitSpeed returns v, clamped to w.MaxObjectSpeed
+func (w *World) limitSpeed(v pixel.Vec) pixel.Vec {
+	if w.MaxObjectSpeed > 0 && v.Len() > w.MaxObjectSpeed {
+		return v.Unit().Scaled(w.MaxObjectSpeed)
+	}
+	return v
+}
@@ -0,0 +1,127 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/go-test/deep"
+	"golang.org/x/image/colornames"
+)
+
+func TestWorld_resolveCollision(t *testing.T) {
+	tests := []struct {
+		name       string
+		massA      float64
+		massB      float64
+		eA, eB     float64
+		velA, velB pixel.Vec
+		fixture    bool // b is a fixture
+		wantA      pixel.Vec
+		wantB      pixel.Vec
+	}{
+		{name: "elastic, velocities exchange", massA: 1, massB: 1, eA: 1, eB: 1, velA: pixel.V(2, 0), velB: pixel.V(-1, 0), wantA: pixel.V(-1, 0), wantB: pixel.V(2, 0)},
+		{name: "elastic, moving into one at rest", massA: 1, massB: 1, eA: 1, eB: 1, velA: pixel.V(2, 0), wantA: pixel.V(0, 0), wantB: pixel.V(2, 0)},
+		{name: "inelastic, move together", massA: 1, massB: 1, velA: pixel.V(2, 0), wantA: pixel.V(1, 0), wantB: pixel.V(1, 0)},
+		{name: "heavy pushes light", massA: 3, massB: 1, eA: 1, eB: 1, velA: pixel.V(2, 0), wantA: pixel.V(1, 0), wantB: pixel.V(3, 0)},
+		{name: "light bounces off heavy", massA: 1, massB: 3, eA: 1, eB: 1, velA: pixel.V(2, 0), wantA: pixel.V(-1, 0), wantB: pixel.V(1, 0)},
+		{name: "least bouncy restitution", massA: 1, massB: 1, eA: 1, eB: 0, velA: pixel.V(2, 0), wantA: pixel.V(1, 0), wantB: pixel.V(1, 0)},
+		{name: "bounce off a fixture", massA: 1, eA: 0.5, eB: 0.5, velA: pixel.V(2, 1), fixture: true, wantA: pixel.V(-1, 1), wantB: pixel.V(0, 0)},
+		{name: "already parting", massA: 1, massB: 1, eA: 1, eB: 1, velA: pixel.V(-1, 0), velB: pixel.V(1, 0), wantA: pixel.V(-1, 0), wantB: pixel.V(1, 0)},
+		{name: "clamped to max speed", massA: 4, massB: 1, eA: 1, eB: 1, velA: pixel.V(4, 0), wantA: pixel.V(2.4, 0), wantB: pixel.V(4, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+
+			a := NewRectObject("a", colornames.Red, 0, tt.massA, 20, 20, nil)
+			a.SetRestitution(tt.eA)
+			a.SetNextPhys(NewBaseObjectPhys(pixel.R(100, 100, 120, 120), a))
+			a.NextPhys().SetVel(tt.velA)
+
+			var b Object
+			if tt.fixture {
+				f := NewFixture("b", colornames.Gray, 20, 20)
+				f.Place(pixel.V(121, 100))
+				b = f
+			} else {
+				b = NewRectObject("b", colornames.Blue, 0, tt.massB, 20, 20, nil)
+				b.SetNextPhys(NewBaseObjectPhys(pixel.R(121, 100, 141, 120), b))
+			}
+			b.SetRestitution(tt.eB)
+			b.NextPhys().SetVel(tt.velB)
+
+			w.resolveCollision(a, b, pixel.V(1, 0), pixel.ZV, pixel.ZV)
+
+			got := []pixel.Vec{a.NextPhys().Vel(), b.NextPhys().Vel()}
+			want := []pixel.Vec{tt.wantA, tt.wantB}
+			if diff := deep.Equal(got, want); diff != nil {
+				t.Errorf("expected velocities %v, got %v: %v", want, got, diff)
+			}
+		})
+	}
+}
+
+func TestWorld_ResolveCollisionsBothSides(t *testing.T) {
+	w := newTestWorld(1)
+	a := NewRectObject("a", colornames.Red, 0, 1, 20, 20, nil)
+	a.SetNextPhys(NewBaseObjectPhys(pixel.R(100, 100, 120, 120), a))
+	a.NextPhys().SetVel(pixel.V(2, 0))
+	b := NewRectObject("b", colornames.Blue, 0, 1, 20, 20, nil)
+	b.SetNextPhys(NewBaseObjectPhys(pixel.R(121, 100, 141, 120), b))
+	a.SetPhys(a.NextPhys().Copy())
+	b.SetPhys(b.NextPhys().Copy())
+
+	// both objects see the collision, they bounce once
+	w.ResolveCollisions(a, []Collision{{Other: b, Location: "right"}})
+	w.ResolveCollisions(b, []Collision{{Other: a, Location: "left"}})
+
+	got := []pixel.Vec{a.NextPhys().Vel(), b.NextPhys().Vel()}
+	want := []pixel.Vec{pixel.V(0.5, 0), pixel.V(1.5, 0)}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("expected velocities %v, got %v: %v", want, got, diff)
+	}
+}
+
+func TestWorld_CollisionMomentum(t *testing.T) {
+	tests := []struct {
+		name          string
+		mover, target float64 // masses
+	}{
+		{name: "heavy pushes light", mover: 10, target: 1},
+		{name: "light bounces off heavy", mover: 1, target: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			w.Drag, w.Friction, w.MaxAcceleration = 0, 0, 0 // just the collision
+
+			mover := NewRectObject("mover", colornames.Red, 0, tt.mover, 20, 20, NewManualBehavior())
+			target := NewRectObject("target", colornames.Blue, 0, tt.target, 20, 20, NewManualBehavior())
+			placeObject(t, w, mover, pixel.V(100, 200))
+			placeObject(t, w, target, pixel.V(200, 200))
+			mover.NextPhys().SetVel(pixel.V(2, 0))
+
+			for i := 0; i < 100; i++ {
+				w.Update()
+				w.NextTick()
+			}
+
+			moverX, targetX := mover.Phys().Location().Center().X, target.Phys().Location().Center().X
+			if targetX <= 200 {
+				t.Errorf("target didn't move, at %v", targetX)
+			}
+			if targetX-moverX < 20 {
+				t.Errorf("objects overlap: mover at %v, target at %v", moverX, targetX)
+			}
+			momentum := mover.Phys().Vel().Scaled(tt.mover).Add(target.Phys().Vel().Scaled(tt.target))
+			if want := pixel.V(2*tt.mover, 0); momentum.Sub(want).Len() > 1e-9 {
+				t.Errorf("expected momentum %v, got %v", want, momentum)
+			}
+			// the heavy one keeps going, the light one bounces back
+			wantForward := tt.mover > tt.target
+			if forward := mover.Phys().Vel().X > 0; forward != wantForward {
+				t.Errorf("expected mover moving forward: %v, velocity %v", wantForward, mover.Phys().Vel())
+			}
+		})
+	}
+}
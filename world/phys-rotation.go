@@ -0,0 +1,153 @@
+package world
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+const (
+	// DefaultAngularDrag is the fraction of its angular velocity an object loses every tick
+	DefaultAngularDrag = 0.05
+
+	// maxAngularVel is the fastest an object can spin, in radians per tick
+	maxAngularVel = 0.3
+	// minAngularVel is the slowest an object spins, anything slower stops
+	minAngularVel = 1e-4
+)
+
+// Angle returns the orientation of the object, in radians counterclockwise around its center
+func (o *BaseObjectPhys) Angle() float64 {
+	return o.angle
+}
+
+// SetAngle sets the orientation of the object
+func (o *BaseObjectPhys) SetAngle(a float64) {
+	o.angle = math.Remainder(a, 2*math.Pi)
+}
+
+// AngularVel returns how fast the object spins, in radians per tick, counterclockwise
+func (o *BaseObjectPhys) AngularVel() float64 {
+	return o.angularVel
+}
+
+// SetAngularVel sets how fast the object spins
+func (o *BaseObjectPhys) SetAngularVel(v float64) {
+	o.angularVel = v
+}
+
+// Torque returns the sum of the torques applied to the object since the last Integrate
+func (o *BaseObjectPhys) Torque() float64 {
+	return o.torque
+}
+
+// ApplyTorque applies t to the object, it changes the angular velocity on the next Integrate
+func (o *BaseObjectPhys) ApplyTorque(t float64) {
+	o.torque += t
+}
+
+// Body returns the shape of the object where it is, see ShapeCollision
+func (o *BaseObjectPhys) Body() Body {
+	return Body{Shape: o.parentObject.Shape(), Rect: o.rect, Angle: o.angle, Vel: o.vel}
+}
+
+// Bounds returns the bounding box of the object as rotated, Location is the one before rotating
+func (o *BaseObjectPhys) Bounds() pixel.Rect {
+	if o.angle == 0 {
+		return o.rect
+	}
+	return o.Body().Bounds()
+}
+
+// Rotate turns the object by its angular velocity. Objects that would turn into another object,
+// the ground or the world borders stop spinning instead.
+func (o *BaseObjectPhys) Rotate(w *World) {
+	if o.angularVel == 0 {
+		return
+	}
+
+	next := o.Body()
+	next.Angle += o.angularVel
+	next.Vel = pixel.ZV
+	if !o.canRotate(w, next) {
+		o.angularVel = 0
+		return
+	}
+	o.SetAngle(next.Angle)
+}
+
+// canRotate returns true if b fits into the world, not overlapping anything where it is or where
+// it is going
+func (o *BaseObjectPhys) canRotate(w *World, b Body) bool {
+	bounds := b.Bounds()
+	if bounds.Min.X < 0 || bounds.Max.X > w.X || bounds.Max.Y > w.Y {
+		return false
+	}
+	if bounds.Min.Y < w.groundTop() {
+		return false
+	}
+
+	for _, other := range w.CollisionObjectsIn(bounds) {
+		if other.ID() == o.parentObject.ID() {
+			continue
+		}
+		ob := other.Phys().Body()
+		if !HaveCollisions(bounds, ob.Bounds(), pixel.ZV, ob.Vel) {
+			continue
+		}
+		if _, ok := ShapeCollision(b, ob); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// integrateAngular advances the angular velocity of the object by one tick, see Integrate.
+// Angular drag slows it down, resting on something slows it down by the friction at its rim,
+// the applied torques accelerate it (by torque / moment of inertia).
+func (o *BaseObjectPhys) integrateAngular(w *World, resting bool) {
+	av := o.angularVel * (1 - w.AngularDrag)
+	if resting {
+		if r := radii(o.rect); r.Len() > 0 {
+			av = slowDown(av, w.Friction/r.Len())
+		}
+	}
+	if o.torque != 0 {
+		av += o.torque * inverseInertia(o)
+	}
+
+	o.angularVel = limitAngularVel(av)
+	o.torque = 0
+}
+
+// limitAngularVel returns av clamped to maxAngularVel, and 0 if it is too slow to notice
+func limitAngularVel(av float64) float64 {
+	if math.Abs(av) < minAngularVel {
+		return 0
+	}
+	return math.Max(-maxAngularVel, math.Min(av, maxAngularVel))
+}
+
+// inverseInertia returns 1 / the moment of inertia around its center of the object p is the physics of,
+// 0 for fixtures
+func inverseInertia(p ObjectPhys) float64 {
+	im := inverseMass(p.ParentObject())
+	if im == 0 {
+		return 0
+	}
+
+	w, h := p.Location().W(), p.Location().H()
+	var i float64
+	switch p.ParentObject().Shape() {
+	case ShapeCircle:
+		i = w * w / 8 // m r² / 2
+	case ShapeEllipse:
+		i = (w*w + h*h) / 16 // m (a² + b²) / 4
+	default:
+		i = (w*w + h*h) / 12
+	}
+	if i == 0 {
+		return 0
+	}
+	return im / i
+}
@@ -0,0 +1,120 @@
+package world
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+func TestBaseObjectPhys_IntegrateAngular(t *testing.T) {
+	inAir := pixel.R(100, 300, 120, 320)
+	onGround := pixel.R(100, 40, 120, 60)
+
+	tests := []struct {
+		name     string
+		rect     pixel.Rect
+		av       float64
+		torque   float64
+		drag     float64
+		friction float64
+		want     float64
+	}{
+		{name: "keeps spinning", rect: inAir, av: 0.1, want: 0.1},
+		{name: "angular drag", rect: inAir, av: 0.1, drag: 0.5, want: 0.05},
+		{name: "torque over moment of inertia", rect: inAir, torque: 20.0 / 3, want: 0.1}, // 20x20, mass 1: 800 / 12
+		{name: "friction on the ground", rect: onGround, av: 0.1, friction: 0.5, want: 0.1 - 0.5/math.Hypot(10, 10)},
+		{name: "no friction in the air", rect: inAir, av: 0.1, friction: 0.5, want: 0.1},
+		{name: "clamped", rect: inAir, av: -1, want: -maxAngularVel},
+		{name: "too slow stops", rect: inAir, av: minAngularVel / 2, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			w.Drag, w.Friction, w.MaxAcceleration, w.AngularDrag = 0, tt.friction, 0, tt.drag
+
+			o := NewRectObject("o", colornames.Red, 3, 1, 20, 20, nil)
+			phys := NewBaseObjectPhys(tt.rect, o)
+			phys.SetAngularVel(tt.av)
+			phys.ApplyTorque(tt.torque)
+
+			phys.Integrate(w)
+			if got := phys.AngularVel(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("expected angular velocity %v, got %v", tt.want, got)
+			}
+			if phys.Torque() != 0 {
+				t.Errorf("expected torques cleared, got %v", phys.Torque())
+			}
+		})
+	}
+}
+
+func TestBaseObjectPhys_Rotate(t *testing.T) {
+	tests := []struct {
+		name      string
+		center    pixel.Vec
+		neighbour bool // a fixture next to the object
+		want      float64
+	}{
+		{name: "free", center: pixel.V(300, 300), want: 0.2},
+		{name: "blocked by a neighbour", center: pixel.V(300, 300), neighbour: true, want: 0},
+		{name: "blocked by the ground", center: pixel.V(300, 50), want: 0},
+		{name: "blocked by the border", center: pixel.V(10, 300), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			if tt.neighbour {
+				f := NewFixture("wall", colornames.Gray, 20, 100)
+				f.Place(pixel.V(311, 250)) // just clear of the object's right side
+				if err := w.AddFixture(f); err != nil {
+					t.Fatalf("failed to add fixture: %v", err)
+				}
+			}
+			o := NewRectObject("o", colornames.Red, 3, 1, 20, 20, nil)
+			placeObject(t, w, o, tt.center)
+			o.NextPhys().SetAngularVel(0.2)
+
+			o.NextPhys().Rotate(w)
+			if got := o.NextPhys().Angle(); got != tt.want {
+				t.Errorf("expected angle %v, got %v", tt.want, got)
+			}
+			if spinning := o.NextPhys().AngularVel() != 0; spinning != (tt.want != 0) {
+				t.Errorf("expected spinning: %v, got angular velocity %v", tt.want != 0, o.NextPhys().AngularVel())
+			}
+		})
+	}
+}
+
+func TestWorld_resolveCollisionSpin(t *testing.T) {
+	w := newTestWorld(1)
+	a := NewRectObject("a", colornames.Red, 0, 1, 20, 20, nil)
+	a.SetRestitution(1)
+	a.SetNextPhys(NewBaseObjectPhys(pixel.R(100, 100, 120, 120), a))
+	a.NextPhys().SetVel(pixel.V(2, 0))
+	b := NewRectObject("b", colornames.Blue, 0, 1, 20, 20, nil)
+	b.SetRestitution(1)
+	b.SetNextPhys(NewBaseObjectPhys(pixel.R(120, 108, 140, 128), b))
+
+	// a hits b with its top right corner, the bottom left one of b
+	ra, rb := pixel.V(10, 8), pixel.V(-10, -10)
+	n := pixel.V(1, 0)
+	w.resolveCollision(a, b, n, ra, rb)
+
+	pa, pb := a.NextPhys(), b.NextPhys()
+	if pa.AngularVel() <= 0 {
+		t.Errorf("expected a spinning counterclockwise, got %v", pa.AngularVel())
+	}
+	if pb.AngularVel() <= 0 {
+		t.Errorf("expected b, pushed below its center, spinning counterclockwise, got %v", pb.AngularVel())
+	}
+	if momentum := pa.Vel().Add(pb.Vel()); momentum.Sub(pixel.V(2, 0)).Len() > 1e-9 {
+		t.Errorf("expected momentum %v, got %v", pixel.V(2, 0), momentum)
+	}
+	// elastic, the corners part as fast as they closed
+	parting := pointVel(pb.Vel(), pb.AngularVel(), rb).Sub(pointVel(pa.Vel(), pa.AngularVel(), ra)).Dot(n)
+	if math.Abs(parting-2) > 1e-9 {
+		t.Errorf("expected the contact points parting at 2, got %v", parting)
+	}
+}
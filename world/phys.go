@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"html/template"
 	"log"
+	"math"
 
 	"github.com/faiface/pixel"
 )
@@ -12,9 +13,28 @@ import (
 type ObjectPhys interface {
 	Copy() ObjectPhys
 
+	ApplyForce(pixel.Vec)
+	DesiredVel() pixel.Vec
+	Force() pixel.Vec
+	Integrate(*World)
+	SetDesiredVel(pixel.Vec)
+
+	Angle() float64
+	AngularVel() float64
+	ApplyTorque(float64)
+	Body() Body
+	Bounds() pixel.Rect
+	Rotate(*World)
+	SetAngle(float64)
+	SetAngularVel(float64)
+	Torque() float64
+
 	CollisionBordersVector(*World, pixel.Vec) pixel.Vec
+	WrapSize() pixel.Vec
 	CurrentMass() float64
+	CollisionsAt(*World) []Collision
 	HaveCollisionsAt(*World) []string
+	MoveToContact(*World, []Collision)
 	IsAboveGround(w *World) bool
 	IsZeroMass() bool
 	Location() pixel.Rect
@@ -50,12 +70,30 @@ type BaseObjectPhys struct {
 	// previous horizontal and vertical Speed of Object
 	previousVel pixel.Vec
 
+	// velocity the object wants to move at, it accelerates towards it, see Integrate
+	desiredVel pixel.Vec
+
+	// sum of the forces applied to the object since the last Integrate
+	force pixel.Vec
+
 	// currentMass of the Object
 	currentMass float64
 
-	// this is the bounding rectangle in the world
+	// this is the bounding rectangle in the world, before rotating the object
 	rect pixel.Rect
 
+	// orientation of the object, radians counterclockwise around the center of rect
+	angle float64
+
+	// how fast the object spins, radians per tick
+	angularVel float64
+
+	// sum of the torques applied to the object since the last Integrate
+	torque float64
+
+	// how far the object goes to come around the world back to where it was, see wrapSize
+	wrap pixel.Vec
+
 	parentObject Object
 }
 
@@ -67,8 +105,13 @@ func (o *BaseObjectPhys) String() string {
 Phys
   Vel: {{.Vel}}	
   PreviousVel: {{.PreviousVel}}	
+  DesiredVel: {{.DesiredVel}}	
+  Force: {{.Force}}	
   CurrentMass: {{.CurrentMass}}	
   Rect: {{.Location}}
+  Angle: {{.Angle}}
+  AngularVel: {{.AngularVel}}
+  Torque: {{.Torque}}
   ParentObject: {{.ParentObject.Name}}
 `)
 
@@ -138,7 +181,13 @@ func (o *BaseObjectPhys) Copy() ObjectPhys {
 	op := NewBaseObjectPhys(o.Location(), o.parentObject)
 	op.SetVel(pixel.V(o.Vel().X, o.Vel().Y))
 	op.SetPreviousVel(pixel.V(o.PreviousVel().X, o.PreviousVel().Y))
+	op.SetDesiredVel(o.DesiredVel())
+	op.ApplyForce(o.Force())
 	op.SetCurrentMass(o.CurrentMass())
+	op.SetAngle(o.Angle())
+	op.SetAngularVel(o.AngularVel())
+	op.ApplyTorque(o.Torque())
+	op.(*BaseObjectPhys).wrap = o.wrap
 	return op
 }
 
@@ -152,9 +201,9 @@ func (o *BaseObjectPhys) SetLocation(r pixel.Rect) {
 	o.rect = r
 }
 
-// OnGround returns true if object is on the ground
+// OnGround returns true if object rests on the ground, or on anything else that supports it, see SupportUnder
 func (o *BaseObjectPhys) OnGround(w *World) bool {
-	return o.Location().Min.Y == w.Ground.Phys().Location().Max.Y
+	return w.restsOn(o.parentObject, o.Bounds(), supportGap)
 }
 
 // Stopped returns true if object is stopped in both directions
@@ -172,9 +221,9 @@ func (o *BaseObjectPhys) StoppedY() bool {
 	return o.Vel().Y == 0
 }
 
-// IsAboveGround checks if object is above ground
+// IsAboveGround checks if object is above ground, or above anything else that could support it
 func (o *BaseObjectPhys) IsAboveGround(w *World) bool {
-	return o.Location().Min.Y > w.Ground.Phys().Location().Max.Y
+	return !o.OnGround(w)
 }
 
 // IsZeroMass checks if object has no mass
@@ -205,10 +254,10 @@ func (o *BaseObjectPhys) MovingRight() bool {
 // LocationOf returns the location of other (above, below, left, right)
 func (o *BaseObjectPhys) LocationOf(other Object) string {
 
-	oMin := o.Location().Min
-	oMax := o.Location().Max
-	otherMin := other.Phys().Location().Min
-	otherMax := other.Phys().Location().Max
+	oMin := o.Bounds().Min
+	oMax := o.Bounds().Max
+	otherMin := other.Phys().Bounds().Min
+	otherMax := other.Phys().Bounds().Max
 
 	switch {
 	case oMin.Y > otherMax.Y:
@@ -228,14 +277,18 @@ func (o *BaseObjectPhys) LocationOf(other Object) string {
 // is a collision, otherwise ""
 func (o *BaseObjectPhys) HaveCollisionsAt(w *World) []string {
 	collisions := []string{}
-	collisionObjects, err := w.CollisionObjects()
-	// collisionObjects, err := w.CollisionObjectsWith(o.parentObject)
-	if err != nil {
-		log.Fatalf("%v is not in the world qt", o.parentObject.Name())
+	for _, c := range o.CollisionsAt(w) {
+		collisions = append(collisions, c.Location)
 	}
+	return collisions
+}
+
+// CollisionsAt returns the objects o runs into if it keeps moving as it is, one Collision per object
+func (o *BaseObjectPhys) CollisionsAt(w *World) []Collision {
+	collisions := []Collision{}
+	// only objects that may get where o is going
+	collisionObjects := w.CollisionObjectsIn(sweep(o.Bounds(), o.Vel()))
 
-	// log.Printf("[%v] Checking collisions with %v objects", o.parentObject.Name(), len(collisionObjects))
-	// Check collisions only with objects that intersect the same quadrant that fully contains o
 	for _, other := range collisionObjects {
 		// for _, co := range collisionObjects {
 		// 	log.Printf("  %v", co.Name())
@@ -243,6 +296,9 @@ func (o *BaseObjectPhys) HaveCollisionsAt(w *World) []string {
 		if o.parentObject.ID() == other.ID() {
 			continue // skip yourself
 		}
+		if f, ok := other.(*Fixture); ok && f.Carries(o.Bounds()) {
+			continue // it carries o along, see World.carry
+		}
 
 		// location of other compared to o (above, below, right, left)
 		l := o.LocationOf(other)
@@ -270,36 +326,87 @@ func (o *BaseObjectPhys) HaveCollisionsAt(w *World) []string {
 			}
 		}
 
-		// other moves as planned based on current velocity
-		if HaveCollisions(o.Location(), other.Phys().Location(), o.Vel(), other.Phys().Vel()) {
-			collisions = append(collisions, l)
+		// other moves as planned based on current velocity, or other doesn't move, whichever
+		// o runs into first. The bounding boxes first, then the actual shapes.
+		var first *Contact
+		for _, v := range []pixel.Vec{other.Phys().Vel(), pixel.ZV} {
+			ob := other.Phys().Body()
+			ob.Vel = v
+			if _, ok := SweepAABB(o.Bounds(), ob.Bounds(), o.Vel(), v); !ok {
+				continue
+			}
+			if c, ok := ShapeCollision(o.Body(), ob); ok && (first == nil || c.T < first.T) {
+				first = &c
+			}
+			if v == pixel.ZV || other.Phys().Vel() == pixel.ZV {
+				break
+			}
 		}
-		// other doesn't move
-		if HaveCollisions(o.Location(), other.Phys().Location(), o.Vel(), pixel.V(0, 0)) {
-			collisions = append(collisions, l)
+		if first != nil {
+			if l == "" {
+				l = sideOf(first.Normal) // the bounding boxes overlap, but not the shapes
+			}
+			collisions = append(collisions, Collision{Other: other, Location: l, Contact: *first})
 		}
 	}
 
 	return collisions
 }
 
+// MoveToContact moves the object along its velocity up to where it first touches one of collisions,
+// instead of stopping a tick short of it. It stays put if that takes it out of the world.
+func (o *BaseObjectPhys) MoveToContact(w *World, collisions []Collision) {
+	if len(collisions) == 0 {
+		return
+	}
+	t := 1.0
+	for _, c := range collisions {
+		t = math.Min(t, c.Contact.T)
+	}
+
+	// stop just short, so rounding doesn't leave them overlapping
+	mv := o.Vel().Scaled(t)
+	if mv.Len() <= contactSkin {
+		return
+	}
+	mv = mv.Sub(mv.Unit().Scaled(contactSkin))
+
+	b := o.Bounds().Moved(mv)
+	if b.Min.X < 0 || b.Max.X > w.X || b.Max.Y > w.Y || b.Min.Y < w.groundTop() {
+		return
+	}
+	o.SetLocation(o.Location().Moved(mv))
+}
+
+// WrapSize returns how far the object goes to come around the world back to where it was, as of the
+// last time it moved up to the edges of the world, see CollisionBordersVector
+func (o *BaseObjectPhys) WrapSize() pixel.Vec {
+	return o.wrap
+}
+
 // CollisionBordersVector returns a movement vector that avoids collision with outside world border given vel vector
-// If no collisions detected, vel is returned as is
+// If no collisions detected, vel is returned as is. Objects go through edges that wrap around or are open,
+// see boundary.go.
 func (o *BaseObjectPhys) CollisionBordersVector(w *World, vel pixel.Vec) pixel.Vec {
+	b := o.Bounds()
+	bs := w.Boundaries
+	o.wrap = w.wrapSize(b.Size())
 
 	switch {
-	case o.MovingLeft() && o.Location().Min.X+vel.X <= 0:
+	case o.MovingLeft() && b.Min.X+vel.X <= 0:
 		// left border
-		return pixel.V(0-o.Location().Min.X, 0)
-	case o.MovingRight() && o.Location().Max.X+vel.X >= w.X:
+		return w.crossBorder(o.parentObject, b, vel, bs.Left, pixel.V(0-b.Min.X, 0), pixel.V(w.X-b.W(), 0))
+	case o.MovingRight() && b.Max.X+vel.X >= w.X:
 		// right border
-		return pixel.V(w.X-o.Location().Max.X, 0)
-	case o.MovingDown() && o.Location().Min.Y+o.Vel().Y < w.Ground.Phys().Location().Max.Y:
-		// stop at ground level
-		return pixel.V(0, w.Ground.Phys().Location().Max.Y-o.Location().Min.Y)
-	case o.MovingUp() && o.Location().Max.Y+o.Vel().Y >= w.Y:
+		return w.crossBorder(o.parentObject, b, vel, bs.Right, pixel.V(w.X-b.Max.X, 0), pixel.V(b.W()-w.X, 0))
+	case o.MovingDown() && b.Min.Y+o.Vel().Y < w.groundTop():
+		// stop at ground level, fixtures and objects below are collisions
+		return w.crossBorder(o.parentObject, b, vel, bs.Bottom, pixel.V(0, w.groundTop()-b.Min.Y),
+			pixel.V(0, w.Y-w.groundTop()-b.H()))
+	case o.MovingUp() && b.Max.Y+o.Vel().Y >= w.Y:
 		// stop at ceiling if going up
-		return pixel.V(0, w.Y-o.Location().Max.Y)
+		return w.crossBorder(o.parentObject, b, vel, bs.Top, pixel.V(0, w.Y-b.Max.Y),
+			pixel.V(0, w.groundTop()+b.H()-w.Y))
 	}
 	return vel
 }
@@ -312,7 +419,7 @@ func (o *BaseObjectPhys) Stop() {
 	o.SetVel(v)
 }
 
-// SetManualVelocity sets the manual object's velocity
+// SetManualVelocity sets the velocity the object wants to move at, in one direction at a time at Speed()
 func (o *BaseObjectPhys) SetManualVelocity(v pixel.Vec) {
 
 	switch {
@@ -330,10 +437,10 @@ func (o *BaseObjectPhys) SetManualVelocity(v pixel.Vec) {
 		v.X = 0
 	}
 
-	o.SetVel(v)
+	o.SetDesiredVel(v)
 }
 
-// SetManualVelocityXY sets the manual object's velocity
+// SetManualVelocityXY sets the velocity the object wants to move at, at Speed() in each direction
 func (o *BaseObjectPhys) SetManualVelocityXY(v pixel.Vec) {
 
 	switch {
@@ -349,5 +456,5 @@ func (o *BaseObjectPhys) SetManualVelocityXY(v pixel.Vec) {
 		v.Y = o.ParentObject().Speed() * -1
 	}
 
-	o.SetVel(v)
+	o.SetDesiredVel(v)
 }
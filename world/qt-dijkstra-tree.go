@@ -78,13 +78,13 @@ func (d *DijkstraPathFinder) Path(t *Tree, start, target pixel.Vec) (path NodeLi
 
 		// loop all the neighboring nodes
 		// for _, nKey := range g.edges[*n.key] {
-		for _, nKey := range n.key.Neighbors() {
+		for _, nKey := range t.Neighbors(n.key) {
 			// skip already-explored nodes
 			if explored[nKey] {
 				continue
 			}
 			// cost to get to this node is the length of the line
-			nCost := int(nKey.bounds.Center().Sub(n.key.bounds.Center()).Len())
+			nCost := int(t.Delta(n.key.bounds.Center(), nKey.bounds.Center()).Len())
 			// nCost := nKey.cost
 
 			// if the node is not yet in the frontier add it with the cost
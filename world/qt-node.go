@@ -19,11 +19,23 @@ import (
 
 	"github.com/faiface/pixel"
 	"golang.org/x/image/colornames"
+
+	"github.com/DanTulovsky/alphaville/utils"
 )
 
 // NodeList is a slice of Node pointers
 type NodeList []*Node
 
+// contains returns true if n is in the list
+func (nl NodeList) contains(n *Node) bool {
+	for _, m := range nl {
+		if m == n {
+			return true
+		}
+	}
+	return false
+}
+
 // Node is a node in the tree
 type Node struct {
 	bounds pixel.Rect // physical bounds of this node
@@ -51,6 +63,26 @@ func (n *Node) RectObjects() []pixel.Rect {
 	return n.rectObjects
 }
 
+// covers returns true if the object o belongs in this node
+func (n *Node) covers(o pixel.Rect) bool {
+	// This is a point posing as a rectangle
+	if o.Area() == 0 {
+		// If the intersection is on the top or right edge, do not count
+		right := pixel.L(pixel.V(n.bounds.Max.X, n.bounds.Min.Y),
+			pixel.V(n.bounds.Max.X, n.bounds.Max.Y))
+		top := pixel.L(pixel.V(n.bounds.Min.X, n.bounds.Max.Y),
+			pixel.V(n.bounds.Max.X, n.bounds.Max.Y))
+
+		if right.Contains(o.Center()) || top.Contains(o.Center()) {
+			// the neighbor will claim this point
+			return false
+		}
+	}
+
+	// This is an actual rectangle
+	return utils.Intersect(n.bounds, o) || n.bounds.Contains(o.Center())
+}
+
 // IsEmpty returns true if the node has no objects in it
 func (n *Node) IsEmpty() bool {
 	return len(n.objects) == 0
@@ -155,10 +187,10 @@ func (n *Node) updateSouthWest() {
 		// nothing to update as this quadrant lies on the west border
 		return
 	}
-	if n.cn[North] != nil {
-		if n.cn[North].bounds.H() < n.bounds.H() {
+	if n.cn[West] != nil {
+		if n.cn[West].bounds.H() < n.bounds.H() {
 			c0 := n.c[Northwest]
-			c0.cn[North] = n.cn[North]
+			c0.cn[West] = n.cn[West]
 			// to update C2, we perform a north-south traversal
 			// recording the cumulative size of traversed nodes
 			cur := c0.cn[West]
@@ -172,6 +204,48 @@ func (n *Node) updateSouthWest() {
 	}
 }
 
+// updateEast updates the eastern neighbour of the NE child, when the
+// eastern neighbours are smaller than n.
+func (n *Node) updateEast() {
+	if n.parent == nil || n.cn[East] == nil {
+		// nothing to update as this quadrant lies on the east border
+		return
+	}
+	if n.cn[East].bounds.H() < n.bounds.H() {
+		c3 := n.c[Southeast]
+		// to update C1, we perform a south-north traversal past C3
+		// recording the cumulative size of traversed nodes
+		cur := c3.cn[East]
+		cumsize := cur.bounds.H()
+		for cumsize < c3.bounds.H() {
+			cur = cur.cn[North]
+			cumsize += cur.bounds.H()
+		}
+		n.c[Northeast].cn[East] = cur.cn[North]
+	}
+}
+
+// updateSouth updates the southern neighbour of the SW child, when the
+// southern neighbours are smaller than n.
+func (n *Node) updateSouth() {
+	if n.parent == nil || n.cn[South] == nil {
+		// nothing to update as this quadrant lies on the south border
+		return
+	}
+	if n.cn[South].bounds.W() < n.bounds.W() {
+		c3 := n.c[Southeast]
+		// to update C2, we perform an east-west traversal past C3
+		// recording the cumulative size of traversed nodes
+		cur := c3.cn[South]
+		cumsize := cur.bounds.W()
+		for cumsize < c3.bounds.W() {
+			cur = cur.cn[West]
+			cumsize += cur.bounds.W()
+		}
+		n.c[Southwest].cn[South] = cur.cn[West]
+	}
+}
+
 // updateNeighbours updates all neighbours according to the current
 // decomposition.
 func (n *Node) updateNeighbours() {
@@ -217,20 +291,38 @@ func (n *Node) updateNeighbours() {
 		})
 	}
 
+	// When the tree is built all at once, eastern and southern neighbours are
+	// never smaller than the quadrant being decomposed, and only the CN
+	// itself points back at it (Q.CN2.CN0=Q.Ch[NE], Q.CN3.CN1=Q.Ch[SW]).
+	// A leaf split later on can have several of them.
 	if n.cn[East] != nil {
-		if n.cn[East] != nil && n.cn[East].cn[West] == n {
-			// To update the eastern CN of a quadrant Q that is being
-			// decomposed: Q.CN2.CN0=Q.Ch[NE]
-			n.cn[East].cn[West] = n.c[Northeast]
-		}
+		n.forEachNeighbourInDirection(East, func(qn *Node) {
+			eastern := qn
+			if eastern.cn[West] == n {
+				if eastern.bounds.Min.Y < n.c[Northeast].bounds.Max.Y {
+					// choose NE
+					eastern.cn[West] = n.c[Northeast]
+				} else {
+					// choose SE
+					eastern.cn[West] = n.c[Southeast]
+				}
+			}
+		})
 	}
 
 	if n.cn[South] != nil {
-		// To update the southern CN of a quadrant Q that is being
-		// decomposed: Q.CN3.CN1=Q.Ch[SW]
-		if n.cn[South] != nil && n.cn[South].cn[North] == n {
-			n.cn[South].cn[North] = n.c[Southwest]
-		}
+		n.forEachNeighbourInDirection(South, func(qn *Node) {
+			southern := qn
+			if southern.cn[North] == n {
+				if southern.bounds.Min.X < n.c[Southwest].bounds.Max.X {
+					// choose SW
+					southern.cn[North] = n.c[Southwest]
+				} else {
+					// choose SE
+					southern.cn[North] = n.c[Southeast]
+				}
+			}
+		})
 	}
 }
 
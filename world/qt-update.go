@@ -0,0 +1,237 @@
+package world
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+// Insert adds o, at its current location, to the tree
+func (qt *Tree) Insert(o Object) error {
+	if _, ok := qt.rects[o.ID()]; ok {
+		return fmt.Errorf("%v is already in the tree", o.Name())
+	}
+
+	to := qt.rect(o.Phys().Bounds())
+	qt.rects[o.ID()] = to
+	qt.update(qt.root, o, nil, &to)
+	return nil
+}
+
+// Remove removes o from the tree
+func (qt *Tree) Remove(o Object) error {
+	from, ok := qt.rects[o.ID()]
+	if !ok {
+		return fmt.Errorf("%v is not in the tree", o.Name())
+	}
+
+	delete(qt.rects, o.ID())
+	qt.update(qt.root, o, &from, nil)
+	return nil
+}
+
+// Move moves o to its current location in the tree
+func (qt *Tree) Move(o Object) error {
+	from, ok := qt.rects[o.ID()]
+	if !ok {
+		return fmt.Errorf("%v is not in the tree", o.Name())
+	}
+
+	to := qt.rect(o.Phys().Bounds())
+	if to == from {
+		return nil
+	}
+	qt.rects[o.ID()] = to
+	qt.update(qt.root, o, &from, &to)
+	return nil
+}
+
+// rect returns the rectangle r is kept in the tree as, augmented by the tree scale
+func (qt *Tree) rect(r pixel.Rect) pixel.Rect {
+	if qt.scale != pixel.ZV && r.Area() != 0 {
+		r = r.Resized(r.Center(), pixel.V(r.W()+qt.scale.X, r.H()+qt.scale.Y))
+	}
+	return r
+}
+
+// update moves o from the from rectangle to the to one in the subtree rooted at n.
+// from is nil when inserting, to is nil when removing.
+// Leaves that become partially full are split, and nodes that become empty (or full) are merged back
+// into a leaf, so the tree ends up the same as NewTree would build it.
+func (qt *Tree) update(n *Node, o Object, from, to *pixel.Rect) {
+	// the root holds all objects, wherever they are
+	in := from != nil && (n == qt.root || n.covers(*from))
+	out := to != nil && (n == qt.root || n.covers(*to))
+
+	switch {
+	case in && out:
+		n.rectObjects[n.index(o)] = *to
+	case in:
+		i := n.index(o)
+		n.objects = append(n.objects[:i], n.objects[i+1:]...)
+		n.rectObjects = append(n.rectObjects[:i], n.rectObjects[i+1:]...)
+	case out:
+		n.objects = append(n.objects, o)
+		n.rectObjects = append(n.rectObjects, *to)
+	default:
+		return // o was not, and is not, in this node
+	}
+
+	if n.color == colornames.Gray {
+		for _, c := range n.c {
+			qt.update(c, o, from, to)
+		}
+	}
+
+	if n == qt.root {
+		return // the root is always split
+	}
+
+	c := n.CalculateColor(qt.minSize)
+	switch {
+	case n.color != colornames.Gray && c == colornames.Gray:
+		qt.split(n)
+	case n.color == colornames.Gray && c != colornames.Gray:
+		qt.merge(n, c)
+	default:
+		n.color = c
+	}
+}
+
+// index returns the index of o in the node's objects
+func (n *Node) index(o Object) int {
+	for i := range n.objects {
+		if n.objects[i].ID() == o.ID() {
+			return i
+		}
+	}
+	log.Fatalf("%v is missing from quadtree node %v", o.Name(), n.bounds)
+	return -1
+}
+
+// split turns the leaf n into a Gray node and subdivides it
+func (qt *Tree) split(n *Node) {
+	qt.removeLeaves(map[*Node]bool{n: true})
+
+	n.color = colornames.Gray
+	qt.subdivide(n)
+	if qt.wrap != pixel.ZV {
+		qt.findEdges()
+	}
+}
+
+// merge turns the Gray node n back into a leaf of the given color
+func (qt *Tree) merge(n *Node, c color.Color) {
+	below := make(map[*Node]bool)
+	n.forEachLeafBelow(func(l *Node) {
+		below[l] = true
+	})
+	qt.removeLeaves(below)
+
+	n.c = nil
+	n.color = c
+	qt.Leaves = append(qt.Leaves, n)
+
+	// n and all the leaves around it may point at the removed leaves
+	qt.relink(n)
+	qt.forEachAdjacentLeaf(qt.root, n, qt.relink)
+	if qt.wrap != pixel.ZV {
+		qt.findEdges()
+	}
+}
+
+// removeLeaves removes the given nodes from the leaves list
+func (qt *Tree) removeLeaves(remove map[*Node]bool) {
+	leaves := qt.Leaves[:0]
+	for _, l := range qt.Leaves {
+		if !remove[l] {
+			leaves = append(leaves, l)
+		}
+	}
+	qt.Leaves = leaves
+}
+
+// forEachLeafBelow calls fn for each leaf in the subtree rooted at n
+func (n *Node) forEachLeafBelow(fn func(*Node)) {
+	if n.color != colornames.Gray {
+		fn(n)
+		return
+	}
+	for _, c := range n.c {
+		c.forEachLeafBelow(fn)
+	}
+}
+
+// forEachAdjacentLeaf calls fn for each leaf in the subtree rooted at p that shares an edge with n
+func (qt *Tree) forEachAdjacentLeaf(p, n *Node, fn func(*Node)) {
+	pb, nb := p.bounds, n.bounds
+	if p == n || pb.Min.X > nb.Max.X || nb.Min.X > pb.Max.X || pb.Min.Y > nb.Max.Y || nb.Min.Y > pb.Max.Y {
+		return // p does not even touch n
+	}
+
+	if p.color == colornames.Gray {
+		for _, c := range p.c {
+			qt.forEachAdjacentLeaf(c, n, fn)
+		}
+		return
+	}
+
+	overlapX := pb.Min.X < nb.Max.X && nb.Min.X < pb.Max.X
+	overlapY := pb.Min.Y < nb.Max.Y && nb.Min.Y < pb.Max.Y
+	if (overlapX && (pb.Max.Y == nb.Min.Y || nb.Max.Y == pb.Min.Y)) ||
+		(overlapY && (pb.Max.X == nb.Min.X || nb.Max.X == pb.Min.X)) {
+		fn(p)
+	}
+}
+
+// relink sets the cardinal neighbours of the leaf n from the shape of the tree
+func (qt *Tree) relink(n *Node) {
+	b, root := n.bounds, qt.root.bounds
+
+	n.cn = [4]*Node{}
+	if b.Min.X > root.Min.X {
+		// top-most of the western neighbours
+		n.cn[West] = qt.locateBeside(b.Min, true, false)
+	}
+	if b.Min.Y > root.Min.Y {
+		// left-most of the northern neighbours
+		n.cn[North] = qt.locateBeside(b.Min, false, true)
+	}
+	if b.Max.X < root.Max.X {
+		// bottom-most of the eastern neighbours
+		n.cn[East] = qt.locateBeside(b.Max, false, true)
+	}
+	if b.Max.Y < root.Max.Y {
+		// right-most of the southern neighbours
+		n.cn[South] = qt.locateBeside(b.Max, true, false)
+	}
+}
+
+// locateBeside returns the leaf just west (or east) and just north (or south) of pt.
+// Unlike Locate, it is exact for points on node edges.
+func (qt *Tree) locateBeside(pt pixel.Vec, west, north bool) *Node {
+	n := qt.root
+	for n.color == colornames.Gray {
+		// same arithmetic as subdivide, so edges compare equal
+		x1 := n.bounds.Min.X + n.bounds.W()/2
+		y1 := n.bounds.Min.Y + n.bounds.H()/2
+
+		inWest := pt.X < x1 || (west && pt.X == x1)
+		inNorth := pt.Y < y1 || (north && pt.Y == y1)
+
+		switch {
+		case inWest && inNorth:
+			n = n.c[Northwest]
+		case inNorth:
+			n = n.c[Northeast]
+		case inWest:
+			n = n.c[Southwest]
+		default:
+			n = n.c[Southeast]
+		}
+	}
+	return n
+}
@@ -0,0 +1,185 @@
+package world
+
+import (
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/go-test/deep"
+	"golang.org/x/image/colornames"
+)
+
+// leafShape is what a leaf looks like, independent of how the tree was built
+type leafShape struct {
+	Color      color.Color
+	Objects    int
+	Neighbours [4]pixel.Rect // bounds of the cardinal neighbours, zero if none
+}
+
+// treeShape returns the shape of all the leaves of qt by their bounds
+func treeShape(t *testing.T, qt *Tree) map[pixel.Rect]leafShape {
+	shape := make(map[pixel.Rect]leafShape)
+	qt.Root().forEachLeafBelow(func(n *Node) {
+		s := leafShape{Color: n.Color(), Objects: len(n.Objects())}
+		for i, cn := range n.cn {
+			if cn != nil {
+				s.Neighbours[i] = cn.Bounds()
+			}
+		}
+		shape[n.Bounds()] = s
+	})
+
+	if len(shape) != len(qt.Leaves) {
+		t.Fatalf("tree has %v leaves, Leaves has %v", len(shape), len(qt.Leaves))
+	}
+	for _, l := range qt.Leaves {
+		if _, ok := shape[l.Bounds()]; !ok {
+			t.Fatalf("%v is in Leaves but not in the tree", l.Bounds())
+		}
+	}
+	return shape
+}
+
+func TestTree_InsertRemoveMove(t *testing.T) {
+	bounds := pixel.R(0, 0, 800, 600)
+	r := rand.New(rand.NewSource(1))
+
+	place := func(o Object) {
+		x, y := r.Float64()*750, r.Float64()*550
+		o.SetPhys(NewBaseObjectPhys(o.BoundingBox(pixel.V(x, y)), o))
+	}
+
+	qt, err := NewTree(bounds, []Object{}, 20, pixel.ZV)
+	if err != nil {
+		t.Fatalf("failed to create tree: %v", err)
+	}
+
+	var objects []Object
+	for i := 0; i < 500; i++ {
+		op := "insert"
+		switch n := r.Intn(10); {
+		case len(objects) > 0 && n < 2:
+			op = "remove"
+			j := r.Intn(len(objects))
+			if err := qt.Remove(objects[j]); err != nil {
+				t.Fatalf("failed to remove: %v", err)
+			}
+			objects = append(objects[:j], objects[j+1:]...)
+		case len(objects) > 0 && n < 8:
+			op = "move"
+			o := objects[r.Intn(len(objects))]
+			if r.Intn(2) == 0 {
+				place(o) // jump
+			} else {
+				o.Phys().SetLocation(o.Phys().Location().Moved(pixel.V(r.Float64()*10-5, r.Float64()*10-5)))
+			}
+			if err := qt.Move(o); err != nil {
+				t.Fatalf("failed to move: %v", err)
+			}
+		case len(objects) < 40:
+			o := NewRectObject("o", colornames.Red, 0, 1, 20+r.Float64()*30, 20+r.Float64()*30, nil)
+			place(o)
+			if err := qt.Insert(o); err != nil {
+				t.Fatalf("failed to insert: %v", err)
+			}
+			objects = append(objects, o)
+		default:
+			continue
+		}
+
+		want, err := NewTree(bounds, objects, 20, pixel.ZV)
+		if err != nil {
+			t.Fatalf("failed to create tree: %v", err)
+		}
+		if diff := deep.Equal(treeShape(t, qt), treeShape(t, want)); len(diff) != 0 {
+			t.Fatalf("after %v %v: tree differs from a new one: %v", i, op, diff)
+		}
+	}
+}
+
+func TestTree_InsertRemoveErrors(t *testing.T) {
+	qt, err := NewTree(pixel.R(0, 0, 800, 600), []Object{}, 20, pixel.ZV)
+	if err != nil {
+		t.Fatalf("failed to create tree: %v", err)
+	}
+	o := NewRectObject("o", colornames.Red, 0, 1, 20, 20, nil)
+	o.SetPhys(NewBaseObjectPhys(o.BoundingBox(pixel.V(100, 100)), o))
+
+	if err := qt.Move(o); err == nil {
+		t.Errorf("expected error moving an object not in the tree")
+	}
+	if err := qt.Insert(o); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	if err := qt.Insert(o); err == nil {
+		t.Errorf("expected error inserting an object twice")
+	}
+	if err := qt.Remove(o); err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+	if err := qt.Remove(o); err == nil {
+		t.Errorf("expected error removing an object not in the tree")
+	}
+}
+
+func TestTree_InsertScaled(t *testing.T) {
+	bounds := pixel.R(0, 0, 800, 600)
+
+	tests := []struct {
+		name  string
+		scale pixel.Vec
+	}{
+		{name: "not scaled", scale: pixel.ZV},
+		{name: "scaled", scale: pixel.V(15, 25)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			var objects []Object
+			for i := 0; i < 30; i++ {
+				o := NewRectObject("o", colornames.Red, 0, 1, 20+r.Float64()*30, 20+r.Float64()*30, nil)
+				o.SetPhys(NewBaseObjectPhys(o.BoundingBox(pixel.V(r.Float64()*750, r.Float64()*550)), o))
+				objects = append(objects, o)
+			}
+			point := NewBaseObject("point", colornames.Yellow, 0, 1000)
+			point.SetPhys(NewBaseObjectPhys(pixel.R(400, 300, 400, 300), &point))
+			objects = append(objects, &point)
+
+			want, err := NewTree(bounds, objects, 20, tt.scale)
+			if err != nil {
+				t.Fatalf("failed to create tree: %v", err)
+			}
+			qt, err := NewTree(bounds, []Object{}, 20, tt.scale)
+			if err != nil {
+				t.Fatalf("failed to create tree: %v", err)
+			}
+			for _, o := range objects {
+				if err := qt.Insert(o); err != nil {
+					t.Fatalf("failed to insert: %v", err)
+				}
+			}
+
+			if diff := deep.Equal(qt.rects, want.rects); len(diff) != 0 {
+				t.Errorf("objects are in the trees as different rectangles: %v", diff)
+			}
+			if diff := deep.Equal(treeShape(t, qt), treeShape(t, want)); len(diff) != 0 {
+				t.Errorf("tree differs from a new one: %v", diff)
+			}
+		})
+	}
+}
+
+func TestWorld_QuadTreeFollowsObjects(t *testing.T) {
+	w := newSnapshotTestWorld(t)
+
+	cobjects, _ := w.CollisionObjects()
+	want, err := NewTree(pixel.R(0, 0, w.X, w.Y), cobjects, w.MinObjectSide, pixel.ZV)
+	if err != nil {
+		t.Fatalf("failed to create tree: %v", err)
+	}
+	if diff := deep.Equal(treeShape(t, w.QuadTree()), treeShape(t, want)); len(diff) != 0 {
+		t.Errorf("world quadtree differs from a new one: %v", diff)
+	}
+}
@@ -6,13 +6,10 @@ import (
 	"image/color"
 	"math"
 
-	"github.com/faiface/pixel/imdraw"
-	"github.com/faiface/pixel/pixelgl"
-	"github.com/faiface/pixel/text"
 	"github.com/tevino/abool"
 
 	"github.com/faiface/pixel"
-	"github.com/DanTulovsky/alphaville/utils"
+	"github.com/google/uuid"
 	"golang.org/x/image/colornames"
 )
 
@@ -21,24 +18,35 @@ type Tree struct {
 	root   *Node
 	Leaves NodeList
 
-	minSize float64 // minimum size of a side of a square
-	nLevels uint    // maximum number of levels of the quadtree
+	minSize float64   // minimum size of a side of a square
+	nLevels uint      // maximum number of levels of the quadtree
+	scale   pixel.Vec // objects are augmented by this much for path finding
+	wrap    pixel.Vec // how far it is around the tree, if it wraps around, see Wrap
+
+	edges [4]NodeList // the leaves along the left, right, bottom and top edges, if it wraps around
+
+	rects map[uuid.UUID]pixel.Rect // the rectangle each object was put in the tree as
 }
 
 // NewTree returns a new quadtree populated with the objects
-// Inserting new objects into the tree is not currently supported
+// Use Insert, Remove and Move to keep it up to date as objects come, go and move around
 func NewTree(bounds pixel.Rect, objects []Object, minSize float64, scale pixel.Vec) (*Tree, error) {
 
 	rectObjects := make([]pixel.Rect, len(objects))
 
 	for i := 0; i < len(objects); i++ {
-		rectObjects[i] = objects[i].Phys().Location()
+		rectObjects[i] = objects[i].Phys().Bounds()
 	}
+	return newTreeOf(bounds, objects, rectObjects, minSize, scale)
+}
 
+// newTreeOf returns a new quadtree populated with the objects, each one in the tree as its rectangle
+// in rectObjects rather than where it is
+func newTreeOf(bounds pixel.Rect, objects []Object, rectObjects []pixel.Rect, minSize float64, scale pixel.Vec) (*Tree, error) {
 	root := &Node{
 		bounds:      bounds.Norm(),
 		color:       colornames.Gray,
-		objects:     objects,
+		objects:     append([]Object{}, objects...),
 		rectObjects: rectObjects,
 		c:           make([]*Node, 4),
 		level:       0,
@@ -47,22 +55,16 @@ func NewTree(bounds pixel.Rect, objects []Object, minSize float64, scale pixel.V
 	qt := &Tree{
 		root:    root,
 		minSize: minSize,
+		scale:   scale,
+		rects:   make(map[uuid.UUID]pixel.Rect),
 	}
 
-	// scale objects for path finding
-	if scale != pixel.ZV {
-		for i := 0; i < len(qt.root.rectObjects)-2; i++ {
-			o := qt.root.rectObjects[i]
-			if o.Area() == 0 {
-				continue
-			}
-			c := o.Center()
-			// log.Printf("%#+v", o)
-			// log.Printf("%v", o.Phys())
-			size := pixel.V(o.W()+scale.X, o.H()+scale.Y)
-			qt.root.rectObjects[i] = o.Resized(c, size)
-		}
+	// scale objects for path finding, points (like the start and target of a path) stay as they are
+	for i, o := range objects {
+		qt.root.rectObjects[i] = qt.rect(qt.root.rectObjects[i])
+		qt.rects[o.ID()] = qt.root.rectObjects[i]
 	}
+
 	qt.subdivide(qt.root)
 	return qt, nil
 }
@@ -84,23 +86,7 @@ func (qt *Tree) newNode(bounds pixel.Rect, parent *Node, location Quadrant) *Nod
 
 	// populate the objects of this node from the parent
 	for i := 0; i < len(parent.RectObjects()); i++ {
-		o := parent.RectObjects()[i]
-		// This is a point posing as a rectangle
-		if o.Area() == 0 {
-			// If the intersection is on the top or right edge, do not count
-			right := pixel.L(pixel.V(n.bounds.Max.X, n.bounds.Min.Y),
-				pixel.V(n.bounds.Max.X, n.bounds.Max.Y))
-			top := pixel.L(pixel.V(n.bounds.Min.X, n.bounds.Max.Y),
-				pixel.V(n.bounds.Max.X, n.bounds.Max.Y))
-
-			if right.Contains(o.Center()) || top.Contains(o.Center()) {
-				// the neighbor will claim this point
-				continue
-			}
-		}
-
-		// This is an actual rectangle
-		if utils.Intersect(n.bounds, o) || n.bounds.Contains(o.Center()) {
+		if n.covers(parent.RectObjects()[i]) {
 			n.objects = append(n.objects, parent.Objects()[i])
 			n.rectObjects = append(n.rectObjects, parent.RectObjects()[i])
 		}
@@ -191,6 +177,8 @@ func (qt *Tree) subdivide(p *Node) {
 
 	p.updateNorthEast()
 	p.updateSouthWest()
+	p.updateEast()
+	p.updateSouth()
 
 	// update all neighbours accordingly. After the decomposition
 	// of a quadrant, all its neighbors in the four directions
@@ -213,6 +201,76 @@ func (qt *Tree) subdivide(p *Node) {
 	}
 }
 
+// Wrap makes the tree wrap around, like a world whose edges do (see boundary.go): horizontally if x,
+// vertically if y. Leaves on opposite edges are then neighbours, see Neighbors.
+func (qt *Tree) Wrap(x, y bool) {
+	qt.wrap = pixel.ZV
+	if x {
+		qt.wrap.X = qt.root.bounds.W()
+	}
+	if y {
+		qt.wrap.Y = qt.root.bounds.H()
+	}
+	qt.findEdges()
+}
+
+// findEdges finds the leaves along the edges the tree wraps around at
+func (qt *Tree) findEdges() {
+	qt.edges = [4]NodeList{}
+	root := qt.root.bounds
+	for _, l := range qt.Leaves {
+		b := l.bounds
+		for i, on := range []bool{
+			qt.wrap.X > 0 && b.Min.X == root.Min.X,
+			qt.wrap.X > 0 && b.Max.X == root.Max.X,
+			qt.wrap.Y > 0 && b.Min.Y == root.Min.Y,
+			qt.wrap.Y > 0 && b.Max.Y == root.Max.Y,
+		} {
+			if on {
+				qt.edges[i] = append(qt.edges[i], l)
+			}
+		}
+	}
+}
+
+// Neighbors returns the white leaves next to n, across the edges of the tree if it wraps around
+func (qt *Tree) Neighbors(n *Node) NodeList {
+	neighbors := n.Neighbors()
+	if qt.wrap == pixel.ZV {
+		return neighbors
+	}
+
+	// the leaves on the opposite edge, next to n along it
+	b, root := n.bounds, qt.root.bounds
+	across := func(edge NodeList, alongX bool) {
+		for _, l := range edge {
+			lb := l.bounds
+			next := alongX && b.Min.X < lb.Max.X && lb.Min.X < b.Max.X || !alongX && b.Min.Y < lb.Max.Y && lb.Min.Y < b.Max.Y
+			if l != n && next && l.Color() == colornames.White && !neighbors.contains(l) {
+				neighbors = append(neighbors, l)
+			}
+		}
+	}
+	if b.Min.X == root.Min.X {
+		across(qt.edges[1], false)
+	}
+	if b.Max.X == root.Max.X {
+		across(qt.edges[0], false)
+	}
+	if b.Min.Y == root.Min.Y {
+		across(qt.edges[3], true)
+	}
+	if b.Max.Y == root.Max.Y {
+		across(qt.edges[2], true)
+	}
+	return neighbors
+}
+
+// Delta returns the shortest way from a to b, across the edges of the tree if it wraps around
+func (qt *Tree) Delta(a, b pixel.Vec) pixel.Vec {
+	return nearest(a, b, qt.wrap).Sub(a)
+}
+
 // Locate returns the Node that contains the given point, or nil.
 func (qt *Tree) Locate(pt pixel.Vec) (*Node, error) {
 	// binary branching method assumes the point lies in the bounds
@@ -254,6 +312,48 @@ func (qt *Tree) Locate(pt pixel.Vec) (*Node, error) {
 	return node, nil
 }
 
+// ObjectsIn returns the objects in the tree whose rectangles touch r, each once
+func (qt *Tree) ObjectsIn(r pixel.Rect) []Object {
+	objects := []Object{}
+	seen := make(map[uuid.UUID]bool)
+
+	qt.forEachIn(r, func(o Object, _ pixel.Rect) {
+		if !seen[o.ID()] {
+			seen[o.ID()] = true
+			objects = append(objects, o)
+		}
+	})
+	return objects
+}
+
+// forEachIn calls fn with each object in the tree whose rectangle touches r, and that rectangle.
+// Objects in more than one leaf are passed more than once.
+func (qt *Tree) forEachIn(r pixel.Rect, fn func(o Object, rect pixel.Rect)) {
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if !touches(n.bounds, r) {
+			return
+		}
+		if n.color == colornames.Gray {
+			for _, c := range n.c {
+				walk(c)
+			}
+			return
+		}
+		for i, o := range n.objects {
+			if touches(n.rectObjects[i], r) {
+				fn(o, n.rectObjects[i])
+			}
+		}
+	}
+	walk(qt.root)
+}
+
+// touches returns true if r1 and r2 overlap or share an edge or corner
+func touches(r1, r2 pixel.Rect) bool {
+	return r1.Min.X <= r2.Max.X && r2.Min.X <= r1.Max.X && r1.Min.Y <= r2.Max.Y && r2.Min.Y <= r1.Max.Y
+}
+
 // ForEachLeaf calls the given function for each leaf node of the quadtree.
 //
 // Successive calls to the provided function are performed in no particular
@@ -273,7 +373,7 @@ func (qt *Tree) ForEachLeaf(color color.Color, fn func(*Node)) {
 // drawTree will draw the quadrants
 // drawText will label the centers of quadrants
 // drawObjects will draw the objects over the quadrants
-func (qt *Tree) Draw(win *pixelgl.Window, drawTree, colorTree, drawText, drawObjects *abool.AtomicBool) {
+func (qt *Tree) Draw(r Renderer, drawTree, colorTree, drawText, drawObjects *abool.AtomicBool) {
 
 	// Grab all the nodes
 	rectangles := NodeList{}
@@ -282,68 +382,36 @@ func (qt *Tree) Draw(win *pixelgl.Window, drawTree, colorTree, drawText, drawObj
 	}
 	qt.ForEachLeaf(colornames.Gray, perNode)
 
-	imd := imdraw.New(nil)
-
 	if colorTree.IsSet() {
 		// rectangle itself
-		for _, r := range rectangles {
-			imd.Color = r.Color()
-			imd.Push(r.Bounds().Min)
-			imd.Push(r.Bounds().Max)
-			imd.Rectangle(0)
+		for _, n := range rectangles {
+			r.Rect(n.Bounds(), n.Color(), 0)
 		}
-		imd.Draw(win)
 	}
 	if drawTree.IsSet() {
 		// lines around it
-		imd := imdraw.New(nil)
-		for _, r := range rectangles {
-			imd.Color = colornames.Red
-			for _, l := range r.Bounds().Edges() {
-				imd.Push(l.A)
-				imd.Push(l.B)
-				imd.Line(1)
-			}
+		for _, n := range rectangles {
+			r.Rect(n.Bounds(), colornames.Red, 1)
 		}
-		imd.Draw(win)
 	}
 
 	if drawText.IsSet() {
-		for _, r := range rectangles {
-			c := r.Bounds().Center().Floor()
-			txt := text.New(c, utils.Atlas())
-			txt.Color = colornames.Green
-			label := fmt.Sprintf("%v,\n%v", c.X, c.Y)
-			txt.Dot.X -= txt.BoundsOf(label).W() / 2
-			fmt.Fprintf(txt, "%v", label)
-			txt.Draw(win, pixel.IM)
+		for _, n := range rectangles {
+			c := n.Bounds().Center().Floor()
+			r.Text(c, colornames.Green, fmt.Sprintf("%v,", c.X), fmt.Sprintf("%v", c.Y))
 		}
 	}
 
 	if drawObjects.IsSet() {
 		// draw the objects
-		imd := imdraw.New(nil)
-		imd.Color = colornames.Yellow
-
-		for _, r := range qt.Root().RectObjects() {
-			imd.Push(r.Min)
-			imd.Push(r.Max)
-			imd.Rectangle(2)
+		for _, o := range qt.Root().RectObjects() {
+			r.Rect(o, colornames.Yellow, 2)
 		}
-		imd.Draw(win)
 	}
 
 }
 
 // DrawPath draws the path
-func DrawPath(win *pixelgl.Window, path []pixel.Vec, c color.Color) {
-
-	imd := imdraw.New(nil)
-	imd.Color = c
-	for _, p := range path {
-		imd.Push(p)
-	}
-	imd.Line(2)
-
-	imd.Draw(win)
+func DrawPath(r Renderer, path []pixel.Vec, c color.Color) {
+	r.Line(path, c, 2)
 }
@@ -0,0 +1,132 @@
+package world
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/go-test/deep"
+	"golang.org/x/image/colornames"
+)
+
+func TestTree_ObjectsIn(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	var objects []Object
+	for i := 0; i < 60; i++ {
+		o := NewRectObject("o", colornames.Red, 0, 1, 20+r.Float64()*30, 20+r.Float64()*30, nil)
+		o.SetPhys(NewBaseObjectPhys(o.BoundingBox(pixel.V(r.Float64()*750, r.Float64()*550)), o))
+		objects = append(objects, o)
+	}
+	qt, err := NewTree(pixel.R(0, 0, 800, 600), objects, 20, pixel.ZV)
+	if err != nil {
+		t.Fatalf("failed to create tree: %v", err)
+	}
+
+	names := func(objects []Object) []string {
+		ids := []string{}
+		for _, o := range objects {
+			ids = append(ids, o.ID().String())
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	queries := []pixel.Rect{
+		pixel.R(0, 0, 800, 600),
+		pixel.R(-100, -100, 0, 0),
+		objects[0].Phys().Bounds(),
+		pixel.R(objects[1].Phys().Bounds().Max.X, 0, 800, 600), // just touching
+	}
+	for i := 0; i < 200; i++ {
+		x, y := r.Float64()*800, r.Float64()*600
+		queries = append(queries, pixel.R(x, y, x+r.Float64()*100, y+r.Float64()*100))
+	}
+
+	for _, q := range queries {
+		want := []Object{}
+		for _, o := range objects {
+			if touches(o.Phys().Bounds(), q) {
+				want = append(want, o)
+			}
+		}
+		if diff := deep.Equal(names(qt.ObjectsIn(q)), names(want)); len(diff) != 0 {
+			t.Fatalf("objects in %v differ: %v", q, diff)
+		}
+	}
+}
+
+func TestTree_CardinalNeighbours(t *testing.T) {
+	bounds := pixel.R(0, 0, 800, 600)
+
+	tests := []struct {
+		name  string
+		width float64 // objects are spread over this much of the west of the world
+	}{
+		{name: "spread", width: 750},
+		{name: "crowded west", width: 150}, // the west splits finer than the east
+		{name: "crowded edge", width: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			var objects []Object
+			for i := 0; i < 30; i++ {
+				o := NewRectObject("o", colornames.Red, 0, 1, 10, 10, nil)
+				o.SetPhys(NewBaseObjectPhys(o.BoundingBox(pixel.V(5+r.Float64()*tt.width, 5+r.Float64()*590)), o))
+				objects = append(objects, o)
+			}
+			qt, err := NewTree(bounds, objects, 20, pixel.ZV)
+			if err != nil {
+				t.Fatalf("failed to create tree: %v", err)
+			}
+
+			// overlap returns true if the spans [a0, a1] and [b0, b1] share more than a point
+			overlap := func(a0, a1, b0, b1 float64) bool { return a0 < b1 && b0 < a1 }
+
+			finer := 0 // leaves with a shorter west neighbour, the case updateSouthWest handles
+			for _, n := range qt.Leaves {
+				b := n.Bounds()
+				border := [4]bool{
+					West:  b.Min.X == bounds.Min.X,
+					North: b.Min.Y == bounds.Min.Y,
+					East:  b.Max.X == bounds.Max.X,
+					South: b.Max.Y == bounds.Max.Y,
+				}
+				for s, cn := range n.cn {
+					side := Side(s)
+					if cn == nil {
+						if !border[side] {
+							t.Errorf("%v has no %v neighbour", b, side)
+						}
+						continue
+					}
+
+					c := cn.Bounds()
+					var next bool
+					switch side {
+					case West:
+						next = c.Max.X == b.Min.X && overlap(c.Min.Y, c.Max.Y, b.Min.Y, b.Max.Y)
+						if c.H() < b.H() {
+							finer++
+						}
+					case North:
+						next = c.Max.Y == b.Min.Y && overlap(c.Min.X, c.Max.X, b.Min.X, b.Max.X)
+					case East:
+						next = c.Min.X == b.Max.X && overlap(c.Min.Y, c.Max.Y, b.Min.Y, b.Max.Y)
+					case South:
+						next = c.Min.Y == b.Max.Y && overlap(c.Min.X, c.Max.X, b.Min.X, b.Max.X)
+					}
+					if !next || cn.Color() == colornames.Gray {
+						t.Errorf("%v neighbour of %v is %v, expected a leaf next to it", side, b, c)
+					}
+				}
+			}
+			if tt.width < 200 && finer == 0 {
+				t.Errorf("expected some leaves with a finer west neighbour")
+			}
+		})
+	}
+}
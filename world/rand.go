@@ -0,0 +1,54 @@
+package world
+
+import (
+	"math/rand"
+)
+
+// RandSource is a seeded random source that counts the numbers drawn from it.
+// The seed and the count are its full state, so it can be saved and restored (see World.Save).
+type RandSource struct {
+	seed  int64
+	drawn uint64
+	src   rand.Source64
+}
+
+// NewRandSource returns a new random source seeded with seed
+func NewRandSource(seed int64) *RandSource {
+	return &RandSource{
+		seed: seed,
+		src:  rand.NewSource(seed).(rand.Source64),
+	}
+}
+
+// RestoreRandSource returns a random source seeded with seed that already had drawn numbers drawn from it
+func RestoreRandSource(seed int64, drawn uint64) *RandSource {
+	s := NewRandSource(seed)
+	for s.drawn < drawn {
+		s.Uint64()
+	}
+	return s
+}
+
+// Int63 implements rand.Source
+func (s *RandSource) Int63() int64 {
+	s.drawn++
+	return s.src.Int63()
+}
+
+// Uint64 implements rand.Source64
+func (s *RandSource) Uint64() uint64 {
+	s.drawn++
+	return s.src.Uint64()
+}
+
+// Seed implements rand.Source, it resets the count
+func (s *RandSource) Seed(seed int64) {
+	s.seed = seed
+	s.drawn = 0
+	s.src.Seed(seed)
+}
+
+// State returns the seed of the source and how many numbers were drawn from it
+func (s *RandSource) State() (seed int64, drawn uint64) {
+	return s.seed, s.drawn
+}
@@ -0,0 +1,34 @@
+package world
+
+import (
+	"image/color"
+
+	"github.com/faiface/pixel"
+)
+
+// NullRenderer implements the Renderer interface, but doesn't draw anything
+type NullRenderer struct{}
+
+// NewNullRenderer returns a new null renderer
+func NewNullRenderer() *NullRenderer {
+	return &NullRenderer{}
+}
+
+// Circle does nothing
+func (r *NullRenderer) Circle(center pixel.Vec, radius float64, c color.Color, thickness float64) {}
+
+// Ellipse does nothing
+func (r *NullRenderer) Ellipse(center pixel.Vec, radius pixel.Vec, angle float64, c color.Color, thickness float64) {
+}
+
+// Line does nothing
+func (r *NullRenderer) Line(points []pixel.Vec, c color.Color, thickness float64) {}
+
+// Polygon does nothing
+func (r *NullRenderer) Polygon(points []pixel.Vec, c color.Color, thickness float64) {}
+
+// Rect does nothing
+func (r *NullRenderer) Rect(rect pixel.Rect, c color.Color, thickness float64) {}
+
+// Text does nothing
+func (r *NullRenderer) Text(v pixel.Vec, c color.Color, lines ...string) {}
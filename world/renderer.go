@@ -0,0 +1,19 @@
+package world
+
+import (
+	"image/color"
+
+	"github.com/faiface/pixel"
+)
+
+// Renderer draws the world and its objects. The world never talks to a window directly, so
+// it can run with any backend, including none at all (see NullRenderer).
+// A thickness of 0 means the shape is filled.
+type Renderer interface {
+	Circle(center pixel.Vec, radius float64, c color.Color, thickness float64)
+	Ellipse(center pixel.Vec, radius pixel.Vec, angle float64, c color.Color, thickness float64) // angle in radians
+	Line(points []pixel.Vec, c color.Color, thickness float64)
+	Polygon(points []pixel.Vec, c color.Color, thickness float64)
+	Rect(r pixel.Rect, c color.Color, thickness float64)
+	Text(v pixel.Vec, c color.Color, lines ...string) // each line is centered on v.X
+}
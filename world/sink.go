@@ -0,0 +1,203 @@
+package world
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/DanTulovsky/alphaville/observer"
+	"github.com/faiface/pixel"
+	"github.com/google/uuid"
+	"golang.org/x/image/colornames"
+)
+
+// SinkEvent implements the observer.Event interface to send events to other components
+type SinkEvent struct {
+	observer.BaseEvent
+}
+
+// NewSinkEvent create a new sink event
+func NewSinkEvent(d string, t time.Time, data ...observer.EventData) observer.Event {
+	e := &SinkEvent{}
+	e.SetData(data)
+	e.SetDescription(d)
+	e.SetTime(t)
+
+	return e
+}
+
+// Sink is a point in the world where objects leave it, the opposite of a Gate
+type Sink struct {
+	id       uuid.UUID
+	name     string
+	Location pixel.Vec
+	Status   GateStatus // GateClosed sinks do not take any objects
+	Radius   float64    // size, objects touching the sink leave through it
+
+	Capacity int // number of objects the sink takes before it's full, unlimited if 0
+	Absorbed int // number of objects that left through the sink
+
+	// Wait this long between exits
+	ExitCoolDown time.Duration
+	LastExit     time.Time
+	clock        Clock // set to the world clock when the sink is added to the world
+
+	// exit seekers head for this
+	target *simpleTarget
+
+	// who to notify on events
+	observers []observer.EventObserver
+
+	// filters controls what object is allowed to leave through the sink
+	// if any filter denies (returns false), it is not allowed
+	filters []GateFilter
+}
+
+// NewSink creates a new sink in the world
+func NewSink(n string, l pixel.Vec, s GateStatus, coolDown time.Duration, radius float64, capacity int, filters ...GateFilter) *Sink {
+	sink := &Sink{
+		id:           uuid.New(),
+		name:         n,
+		Location:     l,
+		Status:       s,
+		Radius:       radius,
+		Capacity:     capacity,
+		ExitCoolDown: coolDown,
+		filters:      filters,
+		clock:        NewWallClock(DefaultTickDuration),
+	}
+	sink.setTarget()
+
+	return sink
+}
+
+// setTarget creates the target exit seekers chase, it shares the sink's id
+func (s *Sink) setTarget() {
+	s.target = NewSimpleTarget(s.name, s.Location, s.Radius, "exit").(*simpleTarget)
+	s.target.id = s.id
+	s.target.color = colornames.Blue
+}
+
+// String returns the sink as string
+func (s *Sink) String() string {
+	return fmt.Sprintf("[%v] L: %v, S: %v, A: %v/%v, C: %v (%v)", s.name, s.Location, s.Status, s.Absorbed, s.Capacity, s.ExitCoolDown, s.LastExit)
+}
+
+// ID returns the id
+func (s *Sink) ID() uuid.UUID {
+	return s.id
+}
+
+// Name returns the name of the sink
+func (s *Sink) Name() string {
+	return s.name
+}
+
+// Target returns the target exit seekers chase to get to this sink
+func (s *Sink) Target() Target {
+	return s.target
+}
+
+// SetClock sets the clock used for the exit cool down
+func (s *Sink) SetClock(c Clock) {
+	s.clock = c
+}
+
+// FilterNames returns the names of the sink's filters, as registered in GateFilters
+func (s *Sink) FilterNames() ([]string, error) {
+	names, err := filterNames(s.filters)
+	if err != nil {
+		return nil, fmt.Errorf("sink [%v] %v", s.name, err)
+	}
+	return names, nil
+}
+
+// Full returns true if the sink reached its capacity
+func (s *Sink) Full() bool {
+	return s.Capacity > 0 && s.Absorbed >= s.Capacity
+}
+
+// Accepts returns true if o is allowed to leave through the sink, now or once the cool down is over
+func (s *Sink) Accepts(o Object) bool {
+	if s.Status != GateOpen || s.Full() {
+		return false
+	}
+	for _, f := range s.filters {
+		if !f(o) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanAbsorb returns true if o can leave through the sink right now
+func (s *Sink) CanAbsorb(o Object) bool {
+	if !s.Accepts(o) {
+		return false
+	}
+	return s.LastExit.IsZero() || s.clock.Since(s.LastExit) >= s.ExitCoolDown
+}
+
+// Reached returns true if o touches the sink
+func (s *Sink) Reached(o Object) bool {
+	if !o.IsSpawned() {
+		return false
+	}
+	return pixel.C(s.Location, s.Radius).IntersectRect(o.Phys().Location()) != pixel.ZV
+}
+
+// Absorb takes o out through the sink, dwell is how long o was in the world
+// The world removes o, see World.ExitObjects
+func (s *Sink) Absorb(o Object, dwell time.Duration) {
+	s.Absorbed++
+	s.LastExit = s.clock.Now()
+
+	s.Notify(NewSinkEvent(
+		fmt.Sprintf("object [%v] exited through sink [%v]", o.Name(), s.name), s.LastExit,
+		observer.EventData{Key: "exit", Value: o.Name()},
+		observer.EventData{Key: "dwell", Value: dwell.String()}))
+}
+
+// Color returns the sink color
+func (s *Sink) Color() color.Color {
+	if s.Status == GateClosed || s.Full() {
+		return colornames.Red
+	}
+	return colornames.Blue
+}
+
+// Draw draws the sink on the screen
+func (s *Sink) Draw(r Renderer, alpha float64) {
+	r.Circle(s.Location, s.Radius, s.Color(), 2)
+
+	label := fmt.Sprintf("%v", s.Absorbed)
+	if s.Capacity > 0 {
+		label = fmt.Sprintf("%v/%v", s.Absorbed, s.Capacity)
+	}
+	r.Text(s.Location, colornames.Yellow, label)
+}
+
+// Implement the observer.EventNotifier interface
+
+// Register registers a new observer for notifying on.
+func (s *Sink) Register(obs observer.EventObserver) {
+	s.observers = append(s.observers, obs)
+}
+
+// Deregister de-registers an observer for notifying on.
+func (s *Sink) Deregister(obs observer.EventObserver) {
+	observers := []observer.EventObserver{}
+	for _, other := range s.observers {
+		if obs != other {
+			observers = append(observers, other)
+		}
+	}
+	s.observers = observers
+}
+
+// Notify notifies all observers on an event.
+func (s *Sink) Notify(event observer.Event) {
+	for i := 0; i < len(s.observers); i++ {
+		s.observers[i].OnNotify(event)
+	}
+}
@@ -0,0 +1,124 @@
+package world
+
+import (
+	"testing"
+	"time"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+func TestSink_CanAbsorb(t *testing.T) {
+	seeker := NewRectObject("seeker", colornames.Red, 1, 1, 20, 20, NewTargetSeekerBehavior(&DijkstraPathFinder{}))
+	exiter := NewRectObject("exiter", colornames.Red, 1, 1, 20, 20, NewExitSeekerBehavior(&DijkstraPathFinder{}))
+
+	clock := NewSimClock(DefaultTickDuration)
+	clock.ticks = 600 // 10s
+
+	tests := []struct {
+		name     string
+		status   GateStatus
+		coolDown time.Duration
+		lastExit time.Duration // ago, never if 0
+		capacity int
+		absorbed int
+		filters  []GateFilter
+		o        Object
+		want     bool
+	}{
+		{name: "open", status: GateOpen, o: seeker, want: true},
+		{name: "closed", status: GateClosed, o: seeker, want: false},
+		{name: "filter allows", status: GateOpen, filters: []GateFilter{ExitSeekerOnlyGateFilter}, o: exiter, want: true},
+		{name: "filter denies", status: GateOpen, filters: []GateFilter{ExitSeekerOnlyGateFilter}, o: seeker, want: false},
+		{name: "below capacity", status: GateOpen, capacity: 2, absorbed: 1, o: seeker, want: true},
+		{name: "full", status: GateOpen, capacity: 2, absorbed: 2, o: seeker, want: false},
+		{name: "unlimited", status: GateOpen, absorbed: 100, o: seeker, want: true},
+		{name: "cooling down", status: GateOpen, coolDown: time.Second, lastExit: time.Second / 2, o: seeker, want: false},
+		{name: "cooled down", status: GateOpen, coolDown: time.Second, lastExit: time.Second, o: seeker, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSink(tt.name, pixel.V(100, 100), tt.status, tt.coolDown, 20, tt.capacity, tt.filters...)
+			s.SetClock(clock)
+			s.Absorbed = tt.absorbed
+			if tt.lastExit > 0 {
+				s.LastExit = clock.Now().Add(-tt.lastExit)
+			}
+
+			if got := s.CanAbsorb(tt.o); got != tt.want {
+				t.Errorf("CanAbsorb() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSink_Observers(t *testing.T) {
+	clock := NewSimClock(DefaultTickDuration)
+	clock.ticks = 600
+	s := NewSink("sink", pixel.V(100, 100), GateOpen, 0, 20, 0)
+	s.SetClock(clock)
+
+	gone, stays := &eventLog{}, &eventLog{}
+	s.Register(gone)
+	s.Register(gone)
+	s.Register(stays)
+	s.Deregister(gone)
+
+	s.Absorb(NewRectObject("o", colornames.Red, 1, 1, 20, 20, nil), time.Second)
+	if len(gone.events) != 0 {
+		t.Errorf("expected no events after deregistering, got %v", len(gone.events))
+	}
+	if len(stays.events) != 1 {
+		t.Fatalf("expected one event, got %v", len(stays.events))
+	}
+	if got := stays.events[0].Time(); !got.Equal(clock.Now()) {
+		t.Errorf("expected the event at %v, world clock time, got %v", clock.Now(), got)
+	}
+}
+
+func TestWorld_ExitObjects(t *testing.T) {
+	w := newTestWorld(1)
+	events := &eventLog{}
+	w.Monitor(events)
+
+	exiter := NewRectObject("exiter", colornames.Red, 3, 1, 20, 20, NewExitSeekerBehavior(&DijkstraPathFinder{}))
+	stayer := NewRectObject("stayer", colornames.Red, 3, 1, 20, 20, nil)
+	for _, o := range []Object{exiter, stayer} {
+		if err := w.AddObject(o); err != nil {
+			t.Fatalf("failed to add object: %v", err)
+		}
+	}
+	if err := w.AddGate(NewGate("gate", pixel.V(400, 300), GateOpen, 0, 20)); err != nil {
+		t.Fatalf("failed to add gate: %v", err)
+	}
+	sink := NewSink("exit", pixel.V(100, 100), GateOpen, 0, 20, 0, ExitSeekerOnlyGateFilter)
+	if err := w.AddSink(sink); err != nil {
+		t.Fatalf("failed to add sink: %v", err)
+	}
+	if err := w.AddSink(NewSink("again", pixel.V(100, 100), GateOpen, 0, 20, 0)); err == nil {
+		t.Errorf("expected error adding a second sink at the same location")
+	}
+
+	for i := 0; len(w.Objects) == 2; i++ {
+		if i > 2000 {
+			t.Fatalf("object never exited")
+		}
+		w.Step()
+	}
+
+	if len(w.Objects) != 1 || w.Objects[0] != stayer {
+		t.Errorf("expected only [stayer] left, got %v", w.Objects)
+	}
+	if !events.has("exit", "exiter") || !events.has("reason", "exited") {
+		t.Errorf("expected exit events")
+	}
+	if sink.Absorbed != 1 {
+		t.Errorf("expected sink to absorb 1 object, got %v", sink.Absorbed)
+	}
+	if w.Stats.ObjectsExited != 1 || w.Stats.ObjectsRemoved != 1 {
+		t.Errorf("expected 1 object exited and removed, got %v and %v", w.Stats.ObjectsExited, w.Stats.ObjectsRemoved)
+	}
+	if w.Stats.AverageDwell() <= 0 || w.Stats.Throughput() <= 0 {
+		t.Errorf("expected positive dwell time and throughput, got %v and %v", w.Stats.AverageDwell(), w.Stats.Throughput())
+	}
+}
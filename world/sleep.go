@@ -0,0 +1,155 @@
+package world
+
+import (
+	"time"
+
+	"github.com/faiface/pixel"
+
+	"github.com/DanTulovsky/alphaville/observer"
+)
+
+// Objects with nothing to do go to sleep: an object whose update leaves it as it was, not moving, not
+// turning, not speeding up and with nothing pushing it, for SleepAfter ticks in a row is no longer
+// updated, it costs (almost) nothing until it wakes up again. It wakes up when:
+//
+//   - something runs into it (any collision event it is part of)
+//   - an object or a kinematic fixture moves next to it, e.g. the object it stands on walks away
+//   - an object it touches is removed from the world
+//   - its behavior has something to do again, see Sleeper
+//   - a target is added to the world, if its behavior hunts targets
+//   - World.Wake is called
+//
+// Sleeping objects stay in the quadtree where they are, others collide with them as usual.
+
+// DefaultSleepAfter is how many ticks in a row an object has to be idle for to fall asleep
+const DefaultSleepAfter = 30
+
+// Sleeper is implemented by behaviors that let their object sleep, objects with other behaviors never do
+type Sleeper interface {
+	// Sleepy returns true if the behavior has nothing to do until something happens to o, and the world
+	// clock time it has something to do again, zero if only something happening to o wakes it up
+	Sleepy(w *World, o Object) (bool, time.Time)
+}
+
+// sleepState is how long an object has been idle, and whether it sleeps
+type sleepState struct {
+	idle   int       // ticks in a row the object was idle for
+	asleep bool      // not updated until woken up
+	wakeAt time.Time // when its behavior has something to do again, never if zero
+}
+
+// Asleep returns true if o is asleep, see sleep.go
+func (w *World) Asleep(o Object) bool {
+	s, ok := w.sleep[o.ID()]
+	return ok && s.asleep
+}
+
+// Sleeping returns the number of objects asleep
+func (w *World) Sleeping() int {
+	n := 0
+	for _, s := range w.sleep {
+		if s.asleep {
+			n++
+		}
+	}
+	return n
+}
+
+// Wake wakes o up, it is updated again from the next update on and has to be idle for SleepAfter ticks
+// to fall asleep again, even if it was awake already.
+// Not safe to call while objects are updating, see Defer.
+func (w *World) Wake(o Object) {
+	delete(w.sleep, o.ID())
+}
+
+// wakeAround wakes up the objects in r
+func (w *World) wakeAround(r pixel.Rect) {
+	w.qt.forEachIn(r, func(o Object, _ pixel.Rect) {
+		w.Wake(o)
+	})
+}
+
+// wakeAlong wakes up the objects close to something going from a to b this tick
+func (w *World) wakeAlong(a, b pixel.Rect) {
+	r := a.Union(b)
+	m := 2 * w.wakeMargin()
+	w.wakeAround(r.Resized(r.Center(), r.Size().Add(pixel.V(m, m))))
+}
+
+// wakeTargetSeekers wakes up the objects whose behavior hunts targets
+func (w *World) wakeTargetSeekers() {
+	for _, o := range w.Objects {
+		// target seekers observe the targets they chase
+		if _, ok := o.Behavior().(observer.EventObserver); ok {
+			w.Wake(o)
+		}
+	}
+}
+
+// awakeObjects returns the spawned objects that are not asleep, waking up those whose behavior has
+// something to do again
+func (w *World) awakeObjects() []Object {
+	if len(w.sleep) == 0 {
+		return w.SpawnedObjects()
+	}
+
+	var objects []Object
+	for _, o := range w.SpawnedObjects() {
+		if s, ok := w.sleep[o.ID()]; ok && s.asleep {
+			if s.wakeAt.IsZero() || w.clock.Now().Before(s.wakeAt) {
+				continue
+			}
+			w.Wake(o)
+		}
+		objects = append(objects, o)
+	}
+	return objects
+}
+
+// idle returns true if o, just updated, has nothing to do: the update left it as it was and its
+// behavior is Sleepy. It also returns when the behavior has something to do again.
+func (w *World) idle(o Object) (bool, time.Time) {
+	if w.SleepAfter <= 0 || o.ID() == w.ManualControl.ID() {
+		return false, time.Time{}
+	}
+	b, ok := o.Behavior().(Sleeper)
+	if !ok || changed(o) {
+		return false, time.Time{}
+	}
+	return b.Sleepy(w, o)
+}
+
+// changed returns true if the update of o changed it: it moves, turns or speeds up, or something is
+// pushing it
+func changed(o Object) bool {
+	p, next := o.Phys(), o.NextPhys()
+	return next.Location() != p.Location() || next.Angle() != p.Angle() ||
+		next.Vel() != p.Vel() || next.PreviousVel() != p.PreviousVel() ||
+		next.DesiredVel() != p.DesiredVel() || next.AngularVel() != p.AngularVel() ||
+		next.CurrentMass() != p.CurrentMass() || next.Force() != pixel.ZV || next.Torque() != 0
+}
+
+// rest counts another tick of o being idle (or not), and puts it to sleep once it was idle for long enough
+func (w *World) rest(o Object, idle bool, wakeAt time.Time) {
+	if !idle {
+		delete(w.sleep, o.ID())
+		return
+	}
+
+	s, ok := w.sleep[o.ID()]
+	if !ok {
+		s = &sleepState{}
+		w.sleep[o.ID()] = s
+	}
+	s.idle++
+	s.wakeAt = wakeAt
+	if s.idle >= w.SleepAfter {
+		s.asleep = true
+	}
+}
+
+// wakeMargin is how close to a moving object or fixture a sleeping object is woken up, far enough
+// that it is awake before anything can reach it
+func (w *World) wakeMargin() float64 {
+	return w.MaxObjectSpeed + contactGap
+}
@@ -0,0 +1,282 @@
+package world
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/go-test/deep"
+	"golang.org/x/image/colornames"
+)
+
+// addRestingObject adds o with its center at c to the world, as if it had spawned there
+func addRestingObject(t *testing.T, w *World, o Object, c pixel.Vec) {
+	t.Helper()
+	placeObject(t, w, o, c)
+	for _, phys := range []ObjectPhys{o.Phys(), o.NextPhys()} {
+		phys.SetCurrentMass(o.Mass())
+	}
+}
+
+func TestWorld_Sleep(t *testing.T) {
+	tests := []struct {
+		name       string
+		o          func() Object
+		center     pixel.Vec
+		sleepAfter int
+		manual     bool
+		want       bool
+	}{
+		{
+			name:       "resting",
+			o:          func() Object { return NewRectObject("o", colornames.Red, 0, 1, 20, 20, nil) },
+			center:     pixel.V(100, 50),
+			sleepAfter: DefaultSleepAfter,
+			want:       true,
+		},
+		{
+			name:       "sleep turned off",
+			o:          func() Object { return NewRectObject("o", colornames.Red, 0, 1, 20, 20, nil) },
+			center:     pixel.V(100, 50),
+			sleepAfter: 0,
+			want:       false,
+		},
+		{
+			name:       "falling",
+			o:          func() Object { return NewRectObject("o", colornames.Red, 0, 1, 20, 20, nil) },
+			center:     pixel.V(100, 500),
+			sleepAfter: DefaultSleepAfter,
+			want:       false,
+		},
+		{
+			name:       "manually controlled",
+			o:          func() Object { return NewRectObject("o", colornames.Red, 0, 1, 20, 20, nil) },
+			center:     pixel.V(100, 50),
+			sleepAfter: DefaultSleepAfter,
+			manual:     true,
+			want:       false,
+		},
+		{
+			name: "target seeker without targets",
+			o: func() Object {
+				return NewRectObject("o", colornames.Red, 2, 1, 20, 20, NewTargetSeekerBehavior(&DijkstraPathFinder{}))
+			},
+			center:     pixel.V(100, 50),
+			sleepAfter: DefaultSleepAfter,
+			want:       true,
+		},
+		{
+			name: "exit seeker without exits",
+			o: func() Object {
+				return NewRectObject("o", colornames.Red, 2, 1, 20, 20, NewExitSeekerBehavior(&DijkstraPathFinder{}))
+			},
+			center:     pixel.V(100, 50),
+			sleepAfter: DefaultSleepAfter,
+			want:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			w.SleepAfter = tt.sleepAfter
+			o := tt.o()
+			addRestingObject(t, w, o, tt.center)
+			if tt.manual {
+				w.ManualControl = o
+			}
+
+			for i := 0; i < 2*DefaultSleepAfter; i++ {
+				w.Step()
+			}
+			if got := w.Asleep(o); got != tt.want {
+				t.Errorf("expected asleep %v, got %v", tt.want, got)
+			}
+			if got, want := w.Stats.Asleep, w.Sleeping(); got != want {
+				t.Errorf("expected %v objects asleep in the stats, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestWorld_Wake(t *testing.T) {
+	tests := []struct {
+		name    string
+		sleeper bool // the object on the box, not the target seeker
+		wake    func(t *testing.T, w *World, o Object)
+		ticks   int
+		check   func(w *World, o Object) bool
+		want    string
+	}{
+		{
+			name:  "woken up",
+			wake:  func(t *testing.T, w *World, o Object) { w.Wake(o) },
+			check: func(w *World, o Object) bool { return true },
+		},
+		{
+			name: "run into",
+			wake: func(t *testing.T, w *World, o Object) {
+				other := NewRectObject("other", colornames.Blue, 0, 5, 20, 20, nil)
+				addRestingObject(t, w, other, pixel.V(276, 50))
+				for _, phys := range []ObjectPhys{other.Phys(), other.NextPhys()} {
+					phys.SetVel(pixel.V(4, 0))
+				}
+			},
+			ticks: 3,
+			check: func(w *World, o Object) bool { return o.NextPhys().Vel().X > 0 },
+			want:  "pushed",
+		},
+		{
+			name:    "support removed",
+			sleeper: true,
+			wake: func(t *testing.T, w *World, o Object) {
+				for _, other := range w.Objects {
+					if other.Name() == "box" {
+						if err := w.RemoveObject(other); err != nil {
+							t.Fatalf("failed to remove object: %v", err)
+						}
+					}
+				}
+			},
+			ticks: 10,
+			check: func(w *World, o Object) bool { return o.NextPhys().Location().Min.Y < 60 },
+			want:  "falling",
+		},
+		{
+			name: "new target",
+			wake: func(t *testing.T, w *World, o Object) {
+				if err := w.AddTarget(NewSimpleTarget("target", pixel.V(700, 300), 10, "target")); err != nil {
+					t.Fatalf("failed to add target: %v", err)
+				}
+			},
+			ticks: 1,
+			check: func(w *World, o Object) bool {
+				return o.Behavior().(*TargetSeekerBehavior).Target() != nil
+			},
+			want: "chasing it",
+		},
+		{
+			name: "platform coming",
+			wake: func(t *testing.T, w *World, o Object) {
+				addMovingFixture(t, w, "platform", pixel.V(140, 40), 100, 20, NewLinearMotion(pixel.V(300, 0), 1))
+			},
+			ticks: 100,
+			check: func(w *World, o Object) bool { return o.Phys().Location().Min.X > 300 && w.Stats.Overlaps == 0 },
+			want:  "pushed aside",
+		},
+		{
+			name:    "wind",
+			sleeper: true,
+			wake: func(t *testing.T, w *World, o Object) {
+				z := NewWindZone("wind", []pixel.Vec{pixel.V(500, 40), pixel.V(700, 40), pixel.V(700, 200), pixel.V(500, 200)}, pixel.V(5, 0))
+				if err := w.AddZone(z); err != nil {
+					t.Fatalf("failed to add zone: %v", err)
+				}
+			},
+			ticks: 10,
+			check: func(w *World, o Object) bool { return o.NextPhys().Vel().X > 0 },
+			want:  "blown along",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			addRestingObject(t, w, NewRectObject("box", colornames.Gray, 0, 1, 20, 20, nil), pixel.V(600, 50))
+			o := NewRectObject("o", colornames.Red, 2, 1, 20, 20, NewTargetSeekerBehavior(&DijkstraPathFinder{}))
+			addRestingObject(t, w, o, pixel.V(300, 50))
+			sleeper := NewRectObject("sleeper", colornames.Red, 0, 1, 20, 20, nil)
+			addRestingObject(t, w, sleeper, pixel.V(600, 70)) // on the box
+			if tt.sleeper {
+				o = sleeper
+			}
+
+			for i := 0; i < 2*DefaultSleepAfter; i++ {
+				w.Step()
+			}
+			if !w.Asleep(o) {
+				t.Fatalf("expected the object to fall asleep")
+			}
+
+			tt.wake(t, w, o)
+			for i := 0; i < tt.ticks; i++ {
+				w.Step()
+			}
+			if w.Asleep(o) {
+				t.Fatalf("expected the object awake")
+			}
+			if !tt.check(w, o) {
+				t.Errorf("expected the object %v, it is at %v", tt.want, o.NextPhys().Location())
+			}
+		})
+	}
+}
+
+func TestWondererBehavior_Sleepy(t *testing.T) {
+	w := newTestWorld(1)
+	o := NewRectObject("wonderer", colornames.Red, 2, 1, 20, 20, nil)
+	b := NewWondererBehavior(o, w)
+	o.SetBehavior(b)
+	addRestingObject(t, w, o, pixel.V(100, 50))
+
+	w.Step()
+	start := w.Clock().Now()
+	if ok, at := b.Sleepy(w, o); !ok || !at.Equal(start.Add(3*time.Second)) {
+		t.Fatalf("expected to sleep until %v, got %v, %v", start.Add(3*time.Second), ok, at)
+	}
+
+	for w.Clock().Since(start) < 2*time.Second {
+		w.Step()
+	}
+	if !w.Asleep(o) {
+		t.Fatalf("expected the wonderer asleep while it waits")
+	}
+	for w.Clock().Since(start) <= 3*time.Second {
+		w.Step()
+	}
+	if w.Asleep(o) {
+		t.Errorf("expected the wonderer awake once its wait is over")
+	}
+}
+
+func TestWorld_SleepSameResult(t *testing.T) {
+	run := func(sleepAfter int) ([][]pixel.Rect, int) {
+		w := newTestWorld(3)
+		w.SleepAfter = sleepAfter
+		for i := 0; i < 8; i++ {
+			addRestingObject(t, w, NewRectObject(fmt.Sprintf("box-%v", i), colornames.Gray, 0, 1, 20, 20, nil),
+				pixel.V(float64(100+i*80), float64(60+i*50)))
+		}
+		// walkers, running into the boxes
+		for i, c := range []pixel.Vec{pixel.V(30, 50), pixel.V(770, 300)} {
+			o := NewRectObject(fmt.Sprintf("walker-%v", i), colornames.Red, float64(2-4*i), 3, 20, 20, nil)
+			addRestingObject(t, w, o, c)
+			for _, phys := range []ObjectPhys{o.Phys(), o.NextPhys()} {
+				phys.SetPreviousVel(pixel.V(o.Speed(), 0))
+			}
+		}
+
+		var l [][]pixel.Rect
+		slept := 0
+		for i := 0; i < 600; i++ {
+			w.Step()
+			l = append(l, locations(w))
+			if n := w.Sleeping(); n > slept {
+				slept = n
+			}
+		}
+		return l, slept
+	}
+
+	want, _ := run(0)
+	got, slept := run(DefaultSleepAfter)
+	if slept == 0 {
+		t.Fatalf("expected objects to fall asleep")
+	}
+	for i := range want {
+		if diff := deep.Equal(got[i], want[i]); diff != nil {
+			t.Fatalf("tick %v: the world with sleeping objects differs: %v", i, diff)
+		}
+	}
+}
@@ -0,0 +1,1028 @@
+package world
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"math/rand"
+	goreflect "reflect" // reflect is taken by the quadtree, see qt-common.go
+	"time"
+
+	"github.com/askft/go-behave/core"
+	"github.com/faiface/pixel"
+	"github.com/google/uuid"
+)
+
+// SnapshotVersion is the version of the snapshot format written by Save and SaveBinary.
+// Version 2 has the force based physics state, version 1 worlds moved differently.
+// Version 3 adds restitution, rotation, gravity scales, zones, kinematic fixtures, sleep, joints and
+// boundaries; version 2 snapshots load with the defaults for them.
+// Version 4 adds the behavior trees of wonderers, they start over from the root in older snapshots.
+const SnapshotVersion = 4
+
+// binaryMagic starts every binary snapshot, it tells LoadWorld the format
+var binaryMagic = []byte("alphaville-world\x00")
+
+// Snapshot is the saved state of a world, see World.Save() and LoadWorld()
+type Snapshot struct {
+	Version int
+
+	Name           string
+	X, Y           float64
+	Gravity        float64
+	MaxObjectSpeed float64
+	MinObjectSide  float64
+
+	Drag            float64
+	Friction        float64
+	MaxAcceleration float64
+	AngularDrag     float64
+
+	SleepAfter int        `json:",omitempty"`
+	Boundaries Boundaries // all solid in older snapshots
+
+	Seed  int64
+	Rand  RandState
+	Clock ClockState
+	Stats StatsState
+
+	Ground        ObjectState
+	Objects       []ObjectState
+	Fixtures      []ObjectState
+	Gates         []GateState
+	Sinks         []SinkState
+	Targets       []TargetState
+	Zones         []ZoneState  `json:",omitempty"`
+	Joints        []JointState `json:",omitempty"`
+	RemoveTargets []uuid.UUID  // targets to be removed next turn
+	ManualControl uuid.UUID    // id of the manually controlled object, if any
+}
+
+// RandState is the state of a RandSource
+type RandState struct {
+	Seed  int64
+	Drawn uint64
+}
+
+// ClockState is the state of the world clock
+type ClockState struct {
+	Wall         bool // WallClock, otherwise SimClock
+	Ticks        int64
+	TickDuration time.Duration
+}
+
+// StatsState are the saved world stats
+type StatsState struct {
+	ObjectsSpawned int
+	ObjectsRemoved int
+	ObjectsExited  int
+	ObjectsLeft    int `json:",omitempty"`
+	Collisions     int `json:",omitempty"`
+	Overlaps       int `json:",omitempty"`
+	JointsBroken   int `json:",omitempty"`
+	TotalDwell     time.Duration
+	Start          time.Time // when the stats started counting, for throughput
+}
+
+// ObjectState is the state of an object or fixture
+type ObjectState struct {
+	Type         string // rect, circle, ellipse or fixture
+	ID           uuid.UUID
+	Name         string
+	Color        [4]uint32 // as returned by color.Color.RGBA()
+	Speed        float64
+	Mass         float64
+	Restitution  float64
+	GravityScale *float64 `json:",omitempty"` // 1 if nil
+
+	Width, Height float64 `json:",omitempty"` // rect and fixture
+	Radius        float64 `json:",omitempty"` // circle
+	A, B          float64 `json:",omitempty"` // ellipse, y and x radius
+
+	Lifetime  time.Duration `json:",omitempty"` // forever if 0
+	SpawnTime time.Time
+
+	Phys     *PhysState     `json:",omitempty"` // nil until spawned
+	NextPhys *PhysState     `json:",omitempty"`
+	Behavior *BehaviorState `json:",omitempty"`
+	Motion   *MotionState   `json:",omitempty"` // kinematic fixtures
+	Sleep    *SleepState    `json:",omitempty"` // nil if not idle
+}
+
+// SleepState is how long an object has been idle, and whether it sleeps, see sleep.go
+type SleepState struct {
+	Idle   int
+	Asleep bool `json:",omitempty"`
+	WakeAt time.Time
+}
+
+// MotionState is the state of a kinematic fixture
+type MotionState struct {
+	Motion
+	Origin pixel.Rect // where the fixture was placed
+	Ticks  int64      // ticks it has moved for
+}
+
+// PhysState is the state of BaseObjectPhys
+type PhysState struct {
+	Vel         pixel.Vec
+	PreviousVel pixel.Vec
+	DesiredVel  pixel.Vec
+	Force       pixel.Vec
+	CurrentMass float64
+	Rect        pixel.Rect
+	Angle       float64 `json:",omitempty"`
+	AngularVel  float64 `json:",omitempty"`
+	Torque      float64 `json:",omitempty"`
+}
+
+// BehaviorState is the state of a behavior
+type BehaviorState struct {
+	Type string // default, manual, target_seeker, exit_seeker or wonderer
+	Rand RandState
+
+	// wonderer, its behavior tree nodes depth first
+	Tree []NodeState `json:",omitempty"`
+
+	// target seeker
+	Target               uuid.UUID    `json:",omitempty"`
+	Path                 []pixel.Rect `json:",omitempty"` // bounds of the quadtree nodes on the path
+	FullPath             []pixel.Vec  `json:",omitempty"`
+	Cost                 int          `json:",omitempty"`
+	Source               pixel.Vec
+	TurnsAtLocation      int   `json:",omitempty"`
+	TargetsCaught        int64 `json:",omitempty"`
+	TargetAcquireTime    time.Time
+	TargetChaseTime      time.Duration `json:",omitempty"`
+	MaxTargetAcquireTime time.Duration `json:",omitempty"`
+}
+
+// NodeState is the state of a behavior tree node.
+// Repeaters are not saved, the ones running forever (the only ones used) have no state.
+type NodeState struct {
+	Status       core.Status
+	CurrentChild int       `json:",omitempty"` // composites, the child they run
+	Start        time.Time // delayers, when they started waiting
+}
+
+// GateState is the state of a gate
+type GateState struct {
+	ID            uuid.UUID
+	Name          string
+	Location      pixel.Vec
+	Status        GateStatus
+	Reserved      bool
+	ReservedBy    uuid.UUID
+	SpawnCoolDown time.Duration
+	LastSpawn     time.Time
+	Radius        float64
+	Filters       []string // names in GateFilters
+}
+
+// SinkState is the state of a sink
+type SinkState struct {
+	ID           uuid.UUID
+	Name         string
+	Location     pixel.Vec
+	Status       GateStatus
+	Radius       float64
+	Capacity     int
+	Absorbed     int
+	ExitCoolDown time.Duration
+	LastExit     time.Time
+	Filters      []string // names in GateFilters
+}
+
+// TargetState is the state of a target
+type TargetState struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	Location    pixel.Vec
+	Radius      float64
+	Available   bool
+}
+
+// ZoneState is the state of a force field zone
+type ZoneState struct {
+	Name     string
+	Kind     ZoneKind
+	Polygon  []pixel.Vec
+	Color    [4]uint32 // as returned by color.Color.RGBA()
+	Force    pixel.Vec `json:",omitempty"`
+	Density  float64   `json:",omitempty"`
+	Drag     float64   `json:",omitempty"`
+	Center   pixel.Vec `json:",omitempty"`
+	Radius   float64   `json:",omitempty"`
+	Strength float64   `json:",omitempty"`
+}
+
+// JointState is the state of a joint, see joint.go
+type JointState struct {
+	Name       string
+	Kind       JointKind
+	A, B       uuid.UUID // ids of the objects or fixtures it joins
+	Length     float64   `json:",omitempty"`
+	Stiffness  float64   `json:",omitempty"`
+	Damping    float64   `json:",omitempty"`
+	BreakForce float64   `json:",omitempty"`
+	Color      [4]uint32 // as returned by color.Color.RGBA()
+	Active     bool      `json:",omitempty"`
+	Offset     pixel.Vec
+	Angle      float64 `json:",omitempty"`
+	Impulse    pixel.Vec
+}
+
+// Save writes the world to out as (indented) JSON, LoadWorld() reads it back
+func (w *World) Save(out io.Writer) error {
+	s, err := w.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return fmt.Errorf("error writing snapshot: %v", err)
+	}
+	return nil
+}
+
+// SaveBinary writes the world to out in a compact binary format, LoadWorld() reads it back
+func (w *World) SaveBinary(out io.Writer) error {
+	s, err := w.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	if _, err := out.Write(binaryMagic); err != nil {
+		return fmt.Errorf("error writing snapshot: %v", err)
+	}
+	if err := gob.NewEncoder(out).Encode(s); err != nil {
+		return fmt.Errorf("error writing snapshot: %v", err)
+	}
+	return nil
+}
+
+// LoadWorld reads a world saved by Save() or SaveBinary().
+// The world has no console and all debugging turned off.
+func LoadWorld(in io.Reader) (*World, error) {
+	r := bufio.NewReader(in)
+	s := &Snapshot{}
+
+	magic, err := r.Peek(len(binaryMagic))
+	if err == nil && bytes.Equal(magic, binaryMagic) {
+		if _, err := r.Discard(len(binaryMagic)); err != nil {
+			return nil, err
+		}
+		if err := gob.NewDecoder(r).Decode(s); err != nil {
+			return nil, fmt.Errorf("error reading snapshot: %v", err)
+		}
+	} else {
+		if err := json.NewDecoder(r).Decode(s); err != nil {
+			return nil, fmt.Errorf("error reading snapshot: %v", err)
+		}
+	}
+
+	return s.World()
+}
+
+// Snapshot returns the current state of the world
+func (w *World) Snapshot() (*Snapshot, error) {
+	s := &Snapshot{
+		Version:         SnapshotVersion,
+		Name:            w.name,
+		X:               w.X,
+		Y:               w.Y,
+		Gravity:         w.gravity,
+		MaxObjectSpeed:  w.MaxObjectSpeed,
+		MinObjectSide:   w.MinObjectSide,
+		Drag:            w.Drag,
+		Friction:        w.Friction,
+		MaxAcceleration: w.MaxAcceleration,
+		AngularDrag:     w.AngularDrag,
+		SleepAfter:      w.SleepAfter,
+		Boundaries:      w.Boundaries,
+		Seed:            w.seed,
+		Rand:            randState(w.src),
+		Clock: ClockState{
+			Ticks:        w.clock.Ticks(),
+			TickDuration: w.clock.TickDuration(),
+		},
+		Stats: StatsState{
+			ObjectsSpawned: w.Stats.ObjectsSpawned,
+			ObjectsRemoved: w.Stats.ObjectsRemoved,
+			ObjectsExited:  w.Stats.ObjectsExited,
+			ObjectsLeft:    w.Stats.ObjectsLeft,
+			Collisions:     w.Stats.Collisions,
+			Overlaps:       w.Stats.Overlaps,
+			JointsBroken:   w.Stats.JointsBroken,
+			TotalDwell:     w.Stats.TotalDwell,
+			Start:          w.Stats.start,
+		},
+		Objects:       []ObjectState{},
+		Fixtures:      []ObjectState{},
+		Gates:         []GateState{},
+		Sinks:         []SinkState{},
+		Targets:       []TargetState{},
+		RemoveTargets: []uuid.UUID{},
+	}
+
+	switch w.clock.(type) {
+	case *SimClock:
+	case *WallClock:
+		s.Clock.Wall = true
+	default:
+		return nil, fmt.Errorf("cannot save clock of type %T", w.clock)
+	}
+
+	var err error
+	if s.Ground, err = objectState(w.Ground); err != nil {
+		return nil, err
+	}
+	for _, o := range w.Objects {
+		state, err := objectState(o)
+		if err != nil {
+			return nil, err
+		}
+		if sl, ok := w.sleep[o.ID()]; ok {
+			state.Sleep = &SleepState{Idle: sl.idle, Asleep: sl.asleep, WakeAt: sl.wakeAt}
+		}
+		s.Objects = append(s.Objects, state)
+	}
+	for _, f := range w.fixtures {
+		state, err := objectState(f)
+		if err != nil {
+			return nil, err
+		}
+		s.Fixtures = append(s.Fixtures, state)
+	}
+
+	for _, g := range w.Gates {
+		filters, err := g.FilterNames()
+		if err != nil {
+			return nil, err
+		}
+		s.Gates = append(s.Gates, GateState{
+			ID:            g.id,
+			Name:          g.name,
+			Location:      g.Location,
+			Status:        g.Status,
+			Reserved:      g.Reserved,
+			ReservedBy:    g.ReservedBy,
+			SpawnCoolDown: g.SpawnCoolDown,
+			LastSpawn:     g.LastSpawn,
+			Radius:        g.Radius,
+			Filters:       filters,
+		})
+	}
+
+	for _, sink := range w.Sinks {
+		filters, err := sink.FilterNames()
+		if err != nil {
+			return nil, err
+		}
+		s.Sinks = append(s.Sinks, SinkState{
+			ID:           sink.id,
+			Name:         sink.name,
+			Location:     sink.Location,
+			Status:       sink.Status,
+			Radius:       sink.Radius,
+			Capacity:     sink.Capacity,
+			Absorbed:     sink.Absorbed,
+			ExitCoolDown: sink.ExitCoolDown,
+			LastExit:     sink.LastExit,
+			Filters:      filters,
+		})
+	}
+
+	for _, t := range w.targets {
+		st, ok := t.(*simpleTarget)
+		if !ok {
+			return nil, fmt.Errorf("cannot save target of type %T", t)
+		}
+		s.Targets = append(s.Targets, TargetState{
+			ID:          st.id,
+			Name:        st.name,
+			Description: st.description,
+			Location:    st.Location(),
+			Radius:      st.bounds.W() / 2,
+			Available:   st.available,
+		})
+	}
+	for _, z := range w.zones {
+		r, g, b, a := z.Color.RGBA()
+		s.Zones = append(s.Zones, ZoneState{
+			Name:     z.Name,
+			Kind:     z.Kind,
+			Polygon:  z.Polygon,
+			Color:    [4]uint32{r, g, b, a},
+			Force:    z.Force,
+			Density:  z.Density,
+			Drag:     z.Drag,
+			Center:   z.Center,
+			Radius:   z.Radius,
+			Strength: z.Strength,
+		})
+	}
+
+	for _, j := range w.joints {
+		r, g, b, a := j.Color.RGBA()
+		s.Joints = append(s.Joints, JointState{
+			Name:       j.Name,
+			Kind:       j.Kind,
+			A:          j.A.ID(),
+			B:          j.B.ID(),
+			Length:     j.Length,
+			Stiffness:  j.Stiffness,
+			Damping:    j.Damping,
+			BreakForce: j.BreakForce,
+			Color:      [4]uint32{r, g, b, a},
+			Active:     j.active,
+			Offset:     j.offset,
+			Angle:      j.angle,
+			Impulse:    j.impulse,
+		})
+	}
+
+	for _, t := range w.removeTargets {
+		s.RemoveTargets = append(s.RemoveTargets, t.ID())
+	}
+
+	if _, null := w.ManualControl.(*NullObject); !null {
+		s.ManualControl = w.ManualControl.ID()
+	}
+
+	return s, nil
+}
+
+// upgrade brings an older snapshot up to the current version, with the defaults for what it doesn't have
+func (s *Snapshot) upgrade() {
+	if s.Version == 2 {
+		s.AngularDrag = DefaultAngularDrag
+		s.SleepAfter = DefaultSleepAfter
+		s.Ground.Restitution = DefaultRestitution
+		for _, states := range [][]ObjectState{s.Objects, s.Fixtures} {
+			for i := range states {
+				states[i].Restitution = DefaultRestitution
+			}
+		}
+	}
+	s.Version = SnapshotVersion
+}
+
+// World returns a new world in the state of the snapshot
+func (s *Snapshot) World() (*World, error) {
+	if s.Version < 2 || s.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %v, want 2 to %v", s.Version, SnapshotVersion)
+	}
+	s.upgrade()
+
+	ground, err := s.Ground.object(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w := NewWorld(s.X, s.Y, ground, s.Gravity, s.MaxObjectSpeed, s.Seed, NewDebugConfig(), nil)
+	w.name = s.Name
+	w.MinObjectSide = s.MinObjectSide
+	w.Drag = s.Drag
+	w.Friction = s.Friction
+	w.MaxAcceleration = s.MaxAcceleration
+	w.AngularDrag = s.AngularDrag
+	w.SleepAfter = s.SleepAfter
+	w.Boundaries = s.Boundaries
+	w.src = RestoreRandSource(s.Rand.Seed, s.Rand.Drawn)
+	w.rng = rand.New(w.src)
+	w.Stats.ObjectsSpawned = s.Stats.ObjectsSpawned
+	w.Stats.ObjectsRemoved = s.Stats.ObjectsRemoved
+	w.Stats.ObjectsExited = s.Stats.ObjectsExited
+	w.Stats.ObjectsLeft = s.Stats.ObjectsLeft
+	w.Stats.Collisions = s.Stats.Collisions
+	w.Stats.Overlaps = s.Stats.Overlaps
+	w.Stats.JointsBroken = s.Stats.JointsBroken
+	w.Stats.TotalDwell = s.Stats.TotalDwell
+
+	if s.Clock.Wall {
+		w.clock = &WallClock{ticks: s.Clock.Ticks, tickDuration: s.Clock.TickDuration}
+	} else {
+		c := NewSimClock(s.Clock.TickDuration)
+		c.ticks = s.Clock.Ticks
+		w.clock = c
+	}
+	w.Stats.SetClock(w.clock)
+	w.Stats.start = s.Stats.Start
+
+	// targets first, target seekers point at them
+	targets := make(map[uuid.UUID]Target)
+	for _, ts := range s.Targets {
+		t := NewSimpleTarget(ts.Name, ts.Location, ts.Radius, ts.Description).(*simpleTarget)
+		t.id = ts.ID
+		t.available = ts.Available
+		t.Register(w.Stats)
+		t.Register(w)
+
+		w.targets = append(w.targets, t)
+		targets[t.id] = t
+	}
+	for _, id := range s.RemoveTargets {
+		t, ok := targets[id]
+		if !ok {
+			return nil, fmt.Errorf("target [%v] to be removed is not in the world", id)
+		}
+		w.removeTargets = append(w.removeTargets, t)
+	}
+
+	// sinks too, exit seekers point at their targets
+	for _, ss := range s.Sinks {
+		filters := []GateFilter{}
+		for _, name := range ss.Filters {
+			f, ok := GateFilters[name]
+			if !ok {
+				return nil, fmt.Errorf("sink [%v] has unknown filter %q", ss.Name, name)
+			}
+			filters = append(filters, f)
+		}
+
+		sink := NewSink(ss.Name, ss.Location, ss.Status, ss.ExitCoolDown, ss.Radius, ss.Capacity, filters...)
+		sink.id = ss.ID
+		sink.setTarget()
+		sink.Absorbed = ss.Absorbed
+		sink.LastExit = ss.LastExit
+		sink.SetClock(w.clock)
+		sink.Register(w.Stats)
+		sink.Register(w)
+
+		w.Sinks = append(w.Sinks, sink)
+		targets[sink.id] = sink.Target()
+	}
+
+	for _, state := range s.Objects {
+		o, err := state.object(w)
+		if err != nil {
+			return nil, err
+		}
+		if err := state.restoreBehavior(o, targets); err != nil {
+			return nil, err
+		}
+		o.Register(w)
+		w.Objects = append(w.Objects, o)
+		if state.Sleep != nil {
+			w.sleep[o.ID()] = &sleepState{idle: state.Sleep.Idle, asleep: state.Sleep.Asleep, wakeAt: state.Sleep.WakeAt}
+		}
+
+		if state.ID == s.ManualControl {
+			w.ManualControl = o
+		}
+	}
+	w.Stats.Asleep = w.Sleeping()
+
+	for _, state := range s.Fixtures {
+		f, err := state.object(w)
+		if err != nil {
+			return nil, err
+		}
+		if err := state.restoreBehavior(f, targets); err != nil {
+			return nil, err
+		}
+		w.fixtures = append(w.fixtures, f)
+	}
+	w.moving = w.movingFixtures() // they moved in the update before the save
+	w.fastest = w.fastestSpeed()
+
+	for _, gs := range s.Gates {
+		filters := []GateFilter{}
+		for _, name := range gs.Filters {
+			f, ok := GateFilters[name]
+			if !ok {
+				return nil, fmt.Errorf("gate [%v] has unknown filter %q", gs.Name, name)
+			}
+			filters = append(filters, f)
+		}
+
+		g := NewGate(gs.Name, gs.Location, gs.Status, gs.SpawnCoolDown, gs.Radius, filters...)
+		g.id = gs.ID
+		g.Reserved = gs.Reserved
+		g.ReservedBy = gs.ReservedBy
+		g.LastSpawn = gs.LastSpawn
+		g.SetClock(w.clock)
+		g.Register(w.Stats)
+		g.Register(w)
+
+		w.Gates = append(w.Gates, g)
+	}
+
+	for _, zs := range s.Zones {
+		z := &Zone{
+			Name:     zs.Name,
+			Kind:     zs.Kind,
+			Polygon:  zs.Polygon,
+			Color:    color.RGBA64{R: uint16(zs.Color[0]), G: uint16(zs.Color[1]), B: uint16(zs.Color[2]), A: uint16(zs.Color[3])},
+			Force:    zs.Force,
+			Density:  zs.Density,
+			Drag:     zs.Drag,
+			Center:   zs.Center,
+			Radius:   zs.Radius,
+			Strength: zs.Strength,
+		}
+		if err := w.AddZone(z); err != nil {
+			return nil, err
+		}
+	}
+
+	joined := make(map[uuid.UUID]Object)
+	for _, o := range w.Objects {
+		joined[o.ID()] = o
+	}
+	for _, f := range w.fixtures {
+		joined[f.ID()] = f
+	}
+	for _, js := range s.Joints {
+		a, ok := joined[js.A]
+		if !ok {
+			return nil, fmt.Errorf("joint [%v]: object [%v] is not in the world", js.Name, js.A)
+		}
+		b, ok := joined[js.B]
+		if !ok {
+			return nil, fmt.Errorf("joint [%v]: object [%v] is not in the world", js.Name, js.B)
+		}
+		j := &Joint{
+			Name:       js.Name,
+			Kind:       js.Kind,
+			A:          a,
+			B:          b,
+			Length:     js.Length,
+			Stiffness:  js.Stiffness,
+			Damping:    js.Damping,
+			BreakForce: js.BreakForce,
+			Color:      color.RGBA64{R: uint16(js.Color[0]), G: uint16(js.Color[1]), B: uint16(js.Color[2]), A: uint16(js.Color[3])},
+			active:     js.Active,
+			offset:     js.Offset,
+			angle:      js.Angle,
+			impulse:    js.Impulse,
+		}
+		if err := w.AddJoint(j); err != nil {
+			return nil, err
+		}
+	}
+
+	// the quadtree is normally kept up to date as objects spawn and move
+	cobjects, _ := w.CollisionObjects()
+	if w.qt, err = NewTree(pixel.R(0, 0, w.X, w.Y), cobjects, w.MinObjectSide, pixel.ZV); err != nil {
+		return nil, fmt.Errorf("error creating world qt: %v", err)
+	}
+
+	return w, nil
+}
+
+func randState(s *RandSource) RandState {
+	seed, drawn := s.State()
+	return RandState{Seed: seed, Drawn: drawn}
+}
+
+func physState(p ObjectPhys) *PhysState {
+	if p == nil {
+		return nil
+	}
+	return &PhysState{
+		Vel:         p.Vel(),
+		PreviousVel: p.PreviousVel(),
+		DesiredVel:  p.DesiredVel(),
+		Force:       p.Force(),
+		CurrentMass: p.CurrentMass(),
+		Rect:        p.Location(),
+		Angle:       p.Angle(),
+		AngularVel:  p.AngularVel(),
+		Torque:      p.Torque(),
+	}
+}
+
+// phys returns the phys of o in state ps
+func (ps *PhysState) phys(o Object) ObjectPhys {
+	if ps == nil {
+		return nil
+	}
+	p := NewBaseObjectPhys(ps.Rect, o)
+	p.SetVel(ps.Vel)
+	p.SetPreviousVel(ps.PreviousVel)
+	p.SetDesiredVel(ps.DesiredVel)
+	p.ApplyForce(ps.Force)
+	p.SetCurrentMass(ps.CurrentMass)
+	p.SetAngle(ps.Angle)
+	p.SetAngularVel(ps.AngularVel)
+	p.ApplyTorque(ps.Torque)
+	return p
+}
+
+// undecoratedName returns the name of o without the decorations its behavior tree adds while running
+func undecoratedName(o Object) string {
+	name := o.Name()
+	if o.Behavior() == nil || o.Behavior().Tree() == nil {
+		return name
+	}
+
+	var walk func(n core.Node)
+	walk = func(n core.Node) {
+		if d, ok := n.(*delayer); ok && d.GetStatus() == core.StatusRunning {
+			name = d.savedName
+		}
+		for _, c := range n.GetChildren() {
+			walk(c)
+		}
+	}
+	walk(o.Behavior().Tree().Root)
+
+	return name
+}
+
+func objectState(o Object) (ObjectState, error) {
+	r, g, b, a := o.Color().RGBA()
+	s := ObjectState{
+		ID:          o.ID(),
+		Name:        undecoratedName(o),
+		Color:       [4]uint32{r, g, b, a},
+		Speed:       o.Speed(),
+		Mass:        o.Mass(),
+		Restitution: o.Restitution(),
+		Phys:        physState(o.Phys()),
+		NextPhys:    physState(o.NextPhys()),
+
+		Lifetime:  o.Lifetime(),
+		SpawnTime: o.SpawnTime(),
+	}
+	if g := o.GravityScale(); g != 1 {
+		s.GravityScale = &g
+	}
+
+	switch o := o.(type) {
+	case *Fixture:
+		s.Type = "fixture"
+		s.Width, s.Height = o.width, o.height
+		if o.motion != nil {
+			s.Motion = &MotionState{Motion: *o.motion, Origin: o.origin, Ticks: o.ticks}
+		}
+	case *RectObject:
+		s.Type = "rect"
+		s.Width, s.Height = o.width, o.height
+	case *CircleObject:
+		s.Type = "circle"
+		s.Radius = o.radius
+	case *EllipseObject:
+		s.Type = "ellipse"
+		s.A, s.B = o.a, o.b
+	default:
+		return s, fmt.Errorf("cannot save object [%v] of type %T", o.Name(), o)
+	}
+
+	if o.Behavior() == nil {
+		return s, nil
+	}
+	bs := &BehaviorState{Rand: randState(o.Behavior().RandSource())}
+
+	switch b := o.Behavior().(type) {
+	case *TargetSeekerBehavior:
+		bs.Type = "target_seeker"
+		if b.exits {
+			bs.Type = "exit_seeker"
+		}
+		if b.target != nil {
+			bs.Target = b.target.ID()
+		}
+		for _, n := range b.path {
+			bs.Path = append(bs.Path, n.Bounds())
+		}
+		bs.FullPath = b.fullpath
+		bs.Cost = b.cost
+		bs.Source = b.source
+		bs.TurnsAtLocation = b.turnsAtLocation
+		bs.TargetsCaught = b.targetsCaught
+		bs.TargetAcquireTime = b.targetAcquireTime
+		bs.TargetChaseTime = b.targetChaseTime
+		bs.MaxTargetAcquireTime = b.maxTargetAcquireTime
+	case *WondererBehavior:
+		bs.Type = "wonderer"
+		bs.Tree = treeState(b.t.Root)
+	case *ManualBehavior:
+		bs.Type = "manual"
+	case *DefaultBehavior:
+		bs.Type = "default"
+	default:
+		return s, fmt.Errorf("cannot save behavior of [%v], type %T", o.Name(), b)
+	}
+	s.Behavior = bs
+
+	return s, nil
+}
+
+// object returns a new object in the state of s, without its behavior state (see restoreBehavior)
+// w is passed to behaviors that need it, it can be nil for the ground.
+func (s *ObjectState) object(w *World) (Object, error) {
+	c := color.RGBA64{R: uint16(s.Color[0]), G: uint16(s.Color[1]), B: uint16(s.Color[2]), A: uint16(s.Color[3])}
+
+	var behavior Behavior
+	if s.Behavior != nil {
+		switch s.Behavior.Type {
+		case "target_seeker":
+			behavior = NewTargetSeekerBehavior(&DijkstraPathFinder{})
+		case "exit_seeker":
+			behavior = NewExitSeekerBehavior(&DijkstraPathFinder{})
+		case "manual":
+			behavior = NewManualBehavior()
+		case "default", "wonderer":
+			behavior = NewDefaultBehavior() // wonderers need their parent, see below
+		default:
+			return nil, fmt.Errorf("object [%v] has unknown behavior %q", s.Name, s.Behavior.Type)
+		}
+	}
+
+	var o Object
+	switch s.Type {
+	case "fixture":
+		f := NewFixture(s.Name, c, s.Width, s.Height)
+		if s.Motion != nil {
+			m := s.Motion.Motion
+			if err := f.SetMotion(&m); err != nil {
+				return nil, fmt.Errorf("fixture [%v]: %v", s.Name, err)
+			}
+			f.origin, f.ticks = s.Motion.Origin, s.Motion.Ticks
+		}
+		o = f
+	case "rect":
+		if behavior == nil {
+			o = NewGroundObject(s.Name, c, s.Speed, s.Mass, s.Width, s.Height)
+		} else {
+			o = NewRectObject(s.Name, c, s.Speed, s.Mass, s.Width, s.Height, behavior)
+		}
+	case "circle":
+		o = NewCircleObject(s.Name, c, s.Speed, s.Mass, s.Radius, behavior)
+	case "ellipse":
+		o = NewEllipseObject(s.Name, c, s.Speed, s.Mass, s.A, s.B, behavior)
+	default:
+		return nil, fmt.Errorf("object [%v] has unknown type %q", s.Name, s.Type)
+	}
+
+	base, err := baseObject(o)
+	if err != nil {
+		return nil, err
+	}
+	base.id = s.ID
+	base.phys = s.Phys.phys(o)
+	base.nextPhys = s.NextPhys.phys(o)
+	base.restitution = s.Restitution
+	if s.GravityScale != nil {
+		base.gravityScale = *s.GravityScale
+	}
+	base.lifetime = s.Lifetime
+	base.spawnTime = s.SpawnTime
+
+	if s.Behavior != nil && s.Behavior.Type == "wonderer" {
+		if w == nil {
+			return nil, fmt.Errorf("wonderer [%v] needs a world", s.Name)
+		}
+		base.SetBehavior(NewWondererBehavior(o, w))
+	}
+
+	return o, nil
+}
+
+// baseObject returns the BaseObject embedded in o
+func baseObject(o Object) (*BaseObject, error) {
+	switch o := o.(type) {
+	case *Fixture:
+		return &o.BaseObject, nil
+	case *RectObject:
+		return &o.BaseObject, nil
+	case *CircleObject:
+		return &o.BaseObject, nil
+	case *EllipseObject:
+		return &o.BaseObject, nil
+	}
+	return nil, fmt.Errorf("no BaseObject in %T", o)
+}
+
+// restoreBehavior restores the state of o's behavior
+func (s *ObjectState) restoreBehavior(o Object, targets map[uuid.UUID]Target) error {
+	if s.Behavior == nil {
+		return nil
+	}
+	bs := s.Behavior
+	src := RestoreRandSource(bs.Rand.Seed, bs.Rand.Drawn)
+
+	switch b := o.Behavior().(type) {
+	case *TargetSeekerBehavior:
+		// not SetRand(), that draws a new max target acquire time
+		b.DefaultBehavior.SetRand(src)
+
+		if bs.Target != uuid.Nil {
+			t, ok := targets[bs.Target]
+			if !ok {
+				return fmt.Errorf("target [%v] of [%v] is not in the world", bs.Target, s.Name)
+			}
+			if !b.exits {
+				t.Register(b)
+			}
+			b.target = t
+		}
+		for _, r := range bs.Path {
+			b.path = append(b.path, &Node{bounds: r})
+		}
+		b.fullpath = bs.FullPath
+		b.cost = bs.Cost
+		b.source = bs.Source
+		b.turnsAtLocation = bs.TurnsAtLocation
+		b.targetsCaught = bs.TargetsCaught
+		b.targetAcquireTime = bs.TargetAcquireTime
+		b.targetChaseTime = bs.TargetChaseTime
+		b.maxTargetAcquireTime = bs.MaxTargetAcquireTime
+	case *WondererBehavior:
+		b.SetRand(src)
+		if bs.Tree != nil {
+			if err := restoreTree(o, b, bs.Tree); err != nil {
+				return fmt.Errorf("behavior tree of [%v]: %v", s.Name, err)
+			}
+		}
+	default:
+		b.SetRand(src)
+	}
+	return nil
+}
+
+// treeState returns the state of the behavior tree nodes under n, depth first
+func treeState(n core.Node) []NodeState {
+	s := NodeState{Status: n.GetStatus()}
+	if c := compositeOf(n); c != nil {
+		s.CurrentChild = c.CurrentChild
+	}
+	if d, ok := n.(*delayer); ok {
+		s.Start = d.start
+	}
+
+	states := []NodeState{s}
+	for _, c := range n.GetChildren() {
+		states = append(states, treeState(c)...)
+	}
+	return states
+}
+
+// restoreTree restores the behavior tree of b, the behavior of o, to states (see treeState).
+// Running nodes carry on where they were, without entering them again.
+func restoreTree(o Object, b *WondererBehavior, states []NodeState) error {
+	w := b.t.Context.Data.(*World)
+
+	i := 0
+	var walk func(n core.Node) error
+	walk = func(n core.Node) error {
+		if i == len(states) {
+			return fmt.Errorf("more nodes than the %v saved", len(states))
+		}
+		s := states[i]
+		i++
+
+		n.SetStatus(s.Status)
+		if c := compositeOf(n); c != nil {
+			c.CurrentChild = s.CurrentChild
+		}
+		switch n := n.(type) {
+		case *delayer:
+			n.start = s.Start
+			if s.Status == core.StatusRunning {
+				n.wait(o, s.Start)
+			}
+		case *wonder:
+			n.o, n.b, n.w = o, b, w
+		}
+
+		for _, c := range n.GetChildren() {
+			if err := walk(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(b.t.Root); err != nil {
+		return err
+	}
+	if i != len(states) {
+		return fmt.Errorf("%v nodes, %v saved", i, len(states))
+	}
+	return nil
+}
+
+// compositeOf returns the core.Composite a go-behave composite node embeds, nil for other nodes.
+// Its CurrentChild is the only way to tell where a running sequence is.
+func compositeOf(n core.Node) *core.Composite {
+	v := goreflect.ValueOf(n)
+	if n.GetCategory() != core.CategoryComposite || v.Kind() != goreflect.Ptr || v.Elem().Kind() != goreflect.Struct {
+		return nil
+	}
+	f := v.Elem().FieldByName("Composite")
+	if !f.IsValid() {
+		return nil
+	}
+	c, _ := f.Interface().(*core.Composite)
+	return c
+}
@@ -0,0 +1,294 @@
+package world
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/go-test/deep"
+	"golang.org/x/image/colornames"
+)
+
+// newSnapshotTestWorld returns a world with a bit of everything, run for a while
+func newSnapshotTestWorld(t *testing.T) *World {
+	w := newTestWorld(7)
+	w.Boundaries = wrapX
+	w.Boundaries.Top = Boundary{Mode: BoundaryBounce, Restitution: 0.5}
+
+	for i := 0; i < 4; i++ {
+		o := NewRectObject(fmt.Sprintf("%v", i), colornames.Blue, 2, 1, 20, 30, nil)
+		if i == 0 {
+			o.SetLifetime(5 * time.Second) // outlives the save, but not the rest of the test
+		}
+		if err := w.AddObject(o); err != nil {
+			t.Fatalf("failed to add object: %v", err)
+		}
+	}
+	c := NewCircleObject("c", colornames.Green, 3, 1, 15, nil)
+	c.SetGravityScale(0.5)
+	if err := w.AddObject(c); err != nil {
+		t.Fatalf("failed to add object: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		o := NewRectObject(fmt.Sprintf("ts-%v", i), colornames.Red, 3, 1, 40, 40, NewTargetSeekerBehavior(&DijkstraPathFinder{}))
+		if err := w.AddObject(o); err != nil {
+			t.Fatalf("failed to add object: %v", err)
+		}
+	}
+
+	if err := w.AddObject(NewRectObject("exit", colornames.Red, 1, 1, 30, 30, NewExitSeekerBehavior(&DijkstraPathFinder{}))); err != nil {
+		t.Fatalf("failed to add object: %v", err)
+	}
+	// done thinking by the time the world is saved
+	wonderer := NewRectObject("wonderer", colornames.Orange, 2, 1, 20, 20, nil)
+	wonderer.SetBehavior(NewWondererBehavior(wonderer, w))
+	addRestingObject(t, w, wonderer, pixel.V(150, 50))
+
+	// sits still, and falls asleep
+	rock := NewRectObject("rock", colornames.Gray, 0, 1, 20, 20, nil)
+	placeObject(t, w, rock, pixel.V(450, 50))
+	for _, phys := range []ObjectPhys{rock.Phys(), rock.NextPhys()} {
+		phys.SetCurrentMass(rock.Mass())
+	}
+
+	f := NewFixture("block", colornames.Gray, 40, 100)
+	f.Place(pixel.V(500, 200))
+	if err := w.AddFixture(f); err != nil {
+		t.Fatalf("failed to add fixture: %v", err)
+	}
+	lift := NewFixture("lift", colornames.Gray, 60, 20)
+	lift.Place(pixel.V(20, 100))
+	if err := lift.SetMotion(NewLinearMotion(pixel.V(0, 150), 1)); err != nil {
+		t.Fatalf("failed to set motion: %v", err)
+	}
+	if err := w.AddFixture(lift); err != nil {
+		t.Fatalf("failed to add fixture: %v", err)
+	}
+
+	// hangs from the block, and two objects on a spring once they spawn
+	bob := NewRectObject("bob", colornames.Gray, 0, 1, 20, 20, nil)
+	placeObject(t, w, bob, pixel.V(580, 180))
+	joints := []*Joint{
+		NewDistanceJoint("rope", f, bob, 0),
+		NewSpringJoint("spring", w.Objects[1], w.Objects[2], 60, 0.05, 0.2),
+	}
+	for _, j := range joints {
+		if err := w.AddJoint(j); err != nil {
+			t.Fatalf("failed to add joint: %v", err)
+		}
+	}
+
+	gates := []*Gate{
+		NewGate("one", pixel.V(100, 300), GateOpen, 0, 20),
+		NewGate("two", pixel.V(300, 300), GateOpen, time.Second, 20, DefaultGateFilter),
+		NewGate("manual", pixel.V(700, 300), GateOpen, 0, 20, ManualOnlyGateFilter),
+	}
+	for _, g := range gates {
+		if err := w.AddGate(g); err != nil {
+			t.Fatalf("failed to add gate: %v", err)
+		}
+	}
+
+	if err := w.AddSink(NewSink("exit", pixel.V(760, 80), GateOpen, time.Second, 20, 3, ExitSeekerOnlyGateFilter)); err != nil {
+		t.Fatalf("failed to add sink: %v", err)
+	}
+
+	for _, z := range []*Zone{
+		NewWindZone("wind", RectPolygon(pixel.R(0, 400, 800, 600)), pixel.V(1.5, 0)),
+		NewWaterZone("water", RectPolygon(pixel.R(200, 40, 400, 120)), 0.01, 0.1),
+		NewWellZone("well", pixel.V(600, 300), 100, 0.5),
+	} {
+		if err := w.AddZone(z); err != nil {
+			t.Fatalf("failed to add zone: %v", err)
+		}
+	}
+
+	for i, l := range []pixel.Vec{pixel.V(150, 500), pixel.V(650, 450), pixel.V(400, 120), pixel.V(750, 550)} {
+		if err := w.AddTarget(NewSimpleTarget(fmt.Sprintf("%v", i), l, 10, "target")); err != nil {
+			t.Fatalf("failed to add target: %v", err)
+		}
+	}
+
+	for i := 0; i < 150; i++ {
+		w.Step()
+	}
+	// still thinking when the world is saved
+	thinker := NewRectObject("thinker", colornames.Orange, 2, 1, 20, 20, nil)
+	thinker.SetBehavior(NewWondererBehavior(thinker, w))
+	addRestingObject(t, w, thinker, pixel.V(250, 200))
+	for i := 0; i < 50; i++ {
+		w.Step()
+	}
+	return w
+}
+
+// locations returns the location of all spawned objects and fixtures
+func locations(w *World) []pixel.Rect {
+	l := []pixel.Rect{}
+	for _, o := range append(w.SpawnedObjects(), w.Fixtures()...) {
+		l = append(l, o.Phys().Location())
+	}
+	return l
+}
+
+func TestWorld_SaveLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		save func(*World, io.Writer) error
+	}{
+		{name: "json", save: (*World).Save},
+		{name: "binary", save: (*World).SaveBinary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newSnapshotTestWorld(t)
+
+			if w.Sleeping() == 0 {
+				t.Fatalf("expected an object asleep when saving")
+			}
+			for _, j := range w.Joints() {
+				if !j.Active() {
+					t.Fatalf("expected joint [%v] to hold on when saving", j.Name)
+				}
+			}
+			for _, o := range w.Objects {
+				if _, ok := o.Behavior().(*WondererBehavior); !ok {
+					continue
+				}
+				if thinking := !waitOver(o.Behavior().Tree().Root).IsZero(); thinking != (o.Name() == "[?] thinker") {
+					t.Fatalf("expected only the thinker to think when saving, [%v] thinking: %v", o.Name(), thinking)
+				}
+			}
+
+			buf := &bytes.Buffer{}
+			if err := tt.save(w, buf); err != nil {
+				t.Fatalf("save failed: %v", err)
+			}
+			loaded, err := LoadWorld(buf)
+			if err != nil {
+				t.Fatalf("load failed: %v", err)
+			}
+
+			want, err := w.Snapshot()
+			if err != nil {
+				t.Fatalf("snapshot failed: %v", err)
+			}
+			got, err := loaded.Snapshot()
+			if err != nil {
+				t.Fatalf("snapshot failed: %v", err)
+			}
+			if diff := deep.Equal(got, want); len(diff) != 0 {
+				t.Errorf("loaded world differs from the saved one: %v", diff)
+			}
+
+			// both worlds carry on the same way
+			for i := 0; i < 200; i++ {
+				w.Step()
+				loaded.Step()
+			}
+			if diff := deep.Equal(locations(loaded), locations(w)); len(diff) != 0 {
+				t.Errorf("loaded world diverged from the saved one: %v", diff)
+			}
+			if len(loaded.Objects) != len(w.Objects) {
+				t.Errorf("loaded world has %v objects, saved one %v", len(loaded.Objects), len(w.Objects))
+			}
+		})
+	}
+}
+
+func TestWorld_SaveUnknownGateFilter(t *testing.T) {
+	w := newTestWorld(1)
+	if err := w.AddGate(NewGate("one", pixel.V(100, 100), GateOpen, 0, 10, func(Object) bool { return true })); err != nil {
+		t.Fatalf("failed to add gate: %v", err)
+	}
+
+	if err := w.Save(io.Discard); err == nil {
+		t.Errorf("expected an error saving a gate with an unregistered filter")
+	}
+}
+
+func TestLoadWorld_Version(t *testing.T) {
+	for _, v := range []int{1, 1000} {
+		if _, err := LoadWorld(bytes.NewBufferString(fmt.Sprintf(`{"Version": %v}`, v))); err == nil {
+			t.Errorf("expected an error loading version %v", v)
+		}
+	}
+}
+
+func TestLoadWorld_Version2(t *testing.T) {
+	s, err := newSnapshotTestWorld(t).Snapshot()
+	if err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	// version 2 had none of these
+	s.Version = 2
+	s.AngularDrag, s.SleepAfter = 0, 0
+	for i := range s.Objects {
+		s.Objects[i].Restitution = 0
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(s); err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	w, err := LoadWorld(&buf)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if w.AngularDrag != DefaultAngularDrag || w.SleepAfter != DefaultSleepAfter {
+		t.Errorf("expected the default angular drag and sleep, got %v and %v", w.AngularDrag, w.SleepAfter)
+	}
+	for _, o := range w.Objects {
+		if o.Restitution() != DefaultRestitution {
+			t.Errorf("expected [%v] with the default restitution, got %v", o.Name(), o.Restitution())
+		}
+	}
+}
+
+func TestLoadWorld_Malformed(t *testing.T) {
+	// trees changes the behavior trees of the wonderers in s
+	trees := func(change func([]NodeState) []NodeState) func(s *Snapshot) {
+		return func(s *Snapshot) {
+			for i := range s.Objects {
+				if bs := s.Objects[i].Behavior; bs != nil && bs.Type == "wonderer" {
+					bs.Tree = change(bs.Tree)
+				}
+			}
+		}
+	}
+
+	tests := []struct {
+		name   string
+		change func(s *Snapshot)
+		err    string
+	}{
+		{name: "wonderer ground", change: func(s *Snapshot) { s.Ground.Behavior = &BehaviorState{Type: "wonderer"} }, err: "needs a world"},
+		{name: "unknown type", change: func(s *Snapshot) { s.Objects[0].Type = "blob" }, err: "unknown type"},
+		{name: "short tree", change: trees(func(ns []NodeState) []NodeState { return ns[:1] }), err: "more nodes than the 1 saved"},
+		{name: "long tree", change: trees(func(ns []NodeState) []NodeState { return append(ns, NodeState{}) }), err: "behavior tree of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newSnapshotTestWorld(t).Snapshot()
+			if err != nil {
+				t.Fatalf("failed to save: %v", err)
+			}
+			tt.change(s)
+
+			var buf bytes.Buffer
+			if err := json.NewEncoder(&buf).Encode(s); err != nil {
+				t.Fatalf("failed to encode: %v", err)
+			}
+			if _, err := LoadWorld(&buf); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected an error containing %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
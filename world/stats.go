@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"time"
 
 	"github.com/DanTulovsky/alphaville/observer"
 	"github.com/DanTulovsky/alphaville/utils"
@@ -17,8 +18,20 @@ import (
 type Stats struct {
 	Fps            int // frames per second
 	ObjectsSpawned int // number of spawned objects
+	ObjectsRemoved int // number of objects removed from the world
+	ObjectsExited  int // number of objects that left through a sink
+	ObjectsLeft    int // number of objects that left through an open edge of the world
+	Collisions     int // number of times objects bounced off each other
+	Overlaps       int // number of times objects ended up overlapping
+	Asleep         int // number of objects asleep, as of the last tick
+	JointsBroken   int // number of joints that broke
 	Ups            int // updates (ticks) per second
 
+	TotalDwell time.Duration // total time exited objects spent in the world
+
+	clock Clock     // world clock, for throughput
+	start time.Time // when the stats started counting
+
 	console io.ReadWriter
 }
 
@@ -33,6 +46,32 @@ func NewStats(console io.ReadWriter) *Stats {
 	return s
 }
 
+// SetClock sets the clock throughput is measured with, and starts counting from now
+func (s *Stats) SetClock(c Clock) {
+	s.clock = c
+	s.start = c.Now()
+}
+
+// AverageDwell returns the average time exited objects spent in the world
+func (s *Stats) AverageDwell() time.Duration {
+	if s.ObjectsExited == 0 {
+		return 0
+	}
+	return s.TotalDwell / time.Duration(s.ObjectsExited)
+}
+
+// Throughput returns the number of objects that exited per second
+func (s *Stats) Throughput() float64 {
+	if s.clock == nil {
+		return 0
+	}
+	elapsed := s.clock.Since(s.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.ObjectsExited) / elapsed
+}
+
 // String returns stats in a nice format
 func (s *Stats) String() string {
 	buf := bytes.NewBufferString("")
@@ -44,6 +83,15 @@ func (s *Stats) String() string {
   > Frames Per Second: {{.Fps}}
   > Updates Per Second: {{.Ups}}
   > Total Objects Spawned: {{.ObjectsSpawned}}
+  > Total Objects Removed: {{.ObjectsRemoved}}
+  > Total Objects Exited: {{.ObjectsExited}}
+  > Total Objects Left: {{.ObjectsLeft}}
+  > Average Dwell Time: {{.AverageDwell}}
+  > Throughput: {{printf "%.2f" .Throughput}} objects/s
+  > Collisions: {{.Collisions}}
+  > Overlaps: {{.Overlaps}}
+  > Objects Asleep: {{.Asleep}}
+  > Joints Broken: {{.JointsBroken}}
 `)
 
 	if err != nil {
@@ -63,6 +111,12 @@ func (s *Stats) processWorldEvent(e *worldEvent) {
 			s.Fps = utils.Atoi(data.Value)
 		case "ups":
 			s.Ups = utils.Atoi(data.Value)
+		case "removed":
+			s.ObjectsRemoved++
+		case "reason":
+			if data.Value == "left" {
+				s.ObjectsLeft++
+			}
 		}
 	}
 }
@@ -79,6 +133,22 @@ func (s *Stats) processGateEvent(e *GateEvent) {
 	}
 }
 
+func (s *Stats) processSinkEvent(e *SinkEvent) {
+	for _, data := range e.Data() {
+		switch data.Key {
+		case "exit":
+			s.ObjectsExited++
+		case "dwell":
+			d, err := time.ParseDuration(data.Value)
+			if err != nil {
+				log.Printf("invalid dwell time %q: %v", data.Value, err)
+				continue
+			}
+			s.TotalDwell += d
+		}
+	}
+}
+
 func (s *Stats) processTargetEvent(e *TargetEvent) {
 	for _, data := range e.Data() {
 		switch data.Key {
@@ -101,6 +171,29 @@ func (s *Stats) processObjectEvent(e *ObjectEvent) {
 	}
 }
 
+func (s *Stats) processCollisionEvent(e *CollisionEvent) {
+	for _, data := range e.Data() {
+		switch data.Key {
+		case "kind":
+			switch data.Value {
+			case CollisionContact:
+				s.Collisions++
+			case CollisionOverlap:
+				s.Overlaps++
+			}
+		}
+	}
+}
+
+func (s *Stats) processJointEvent(e *JointEvent) {
+	for _, data := range e.Data() {
+		switch data.Key {
+		case "broken":
+			s.JointsBroken++
+		}
+	}
+}
+
 // OnNotify runs when a notification is received
 func (s *Stats) OnNotify(e observer.Event) {
 	switch event := e.(type) {
@@ -110,10 +203,16 @@ func (s *Stats) OnNotify(e observer.Event) {
 		s.processWorldEvent(event)
 	case *GateEvent:
 		s.processGateEvent(event)
+	case *SinkEvent:
+		s.processSinkEvent(event)
 	case *TargetEvent:
 		s.processTargetEvent(event)
 	case *ObjectEvent:
 		s.processObjectEvent(event)
+	case *CollisionEvent:
+		s.processCollisionEvent(event)
+	case *JointEvent:
+		s.processJointEvent(event)
 	}
 }
 
@@ -0,0 +1,53 @@
+package world
+
+import (
+	"time"
+)
+
+// DefaultMaxStepsPerFrame is the most simulation steps FixedStep allows for a single frame
+const DefaultMaxStepsPerFrame = 10
+
+// FixedStep turns elapsed (real) frame time into a number of fixed length world steps.
+// Time that doesn't add up to a full step is carried over to the next frame; the fraction
+// of a step it represents is used to interpolate rendering (see World.Draw).
+type FixedStep struct {
+	step time.Duration // time between world updates
+	lag  time.Duration // how far the world is behind real time
+
+	// MaxSteps limits the steps per frame, so a slow frame doesn't make the next one even slower
+	MaxSteps int
+}
+
+// NewFixedStep returns a FixedStep running ups updates per second
+func NewFixedStep(ups int) *FixedStep {
+	return &FixedStep{
+		step:     time.Second / time.Duration(ups),
+		MaxSteps: DefaultMaxStepsPerFrame,
+	}
+}
+
+// Step returns the length of one step
+func (f *FixedStep) Step() time.Duration {
+	return f.step
+}
+
+// Advance adds elapsed to the lag and returns how many world steps to run now
+func (f *FixedStep) Advance(elapsed time.Duration) int {
+	f.lag += elapsed
+
+	steps := int(f.lag / f.step)
+	if steps > f.MaxSteps {
+		// can't keep up, drop the time we are not going to simulate
+		steps = f.MaxSteps
+		f.lag = f.lag % f.step
+	} else {
+		f.lag -= time.Duration(steps) * f.step
+	}
+
+	return steps
+}
+
+// Alpha returns how far (in [0, 1)) real time is between the last step and the next one
+func (f *FixedStep) Alpha() float64 {
+	return float64(f.lag) / float64(f.step)
+}
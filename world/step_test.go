@@ -0,0 +1,65 @@
+package world
+
+import (
+	"testing"
+	"time"
+
+	"github.com/faiface/pixel"
+)
+
+func TestFixedStep_Advance(t *testing.T) {
+	tests := []struct {
+		name      string
+		elapsed   []time.Duration
+		wantSteps int // of the last Advance
+		wantAlpha float64
+	}{
+		{
+			name:      "less than a step",
+			elapsed:   []time.Duration{time.Millisecond * 5},
+			wantSteps: 0,
+			wantAlpha: 0.5,
+		},
+		{
+			name:      "carry over lag",
+			elapsed:   []time.Duration{time.Millisecond * 5, time.Millisecond * 17},
+			wantSteps: 2,
+			wantAlpha: 0.2,
+		},
+		{
+			name:      "capped",
+			elapsed:   []time.Duration{time.Second},
+			wantSteps: DefaultMaxStepsPerFrame,
+			wantAlpha: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFixedStep(100)
+			var steps int
+			for _, e := range tt.elapsed {
+				steps = f.Advance(e)
+			}
+			if steps != tt.wantSteps {
+				t.Errorf("Advance() = %v, want %v", steps, tt.wantSteps)
+			}
+			if alpha := f.Alpha(); alpha < tt.wantAlpha-1e-9 || alpha > tt.wantAlpha+1e-9 {
+				t.Errorf("Alpha() = %v, want %v", alpha, tt.wantAlpha)
+			}
+		})
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	w := newTestWorld(1)
+	o := NewRectObject("o", nil, 1, 1, 20, 20, nil)
+	o.SetPhys(NewBaseObjectPhys(o.BoundingBox(w.Ground.Phys().Location().Center()), o))
+	o.SetNextPhys(o.Phys().Copy())
+	o.NextPhys().SetLocation(o.Phys().Location().Moved(pixel.V(10, -4)))
+
+	got := Interpolate(o, 0.25)
+	want := o.Phys().Location().Moved(pixel.V(2.5, -1))
+	if got != want {
+		t.Errorf("Interpolate() = %v, want %v", got, want)
+	}
+}
@@ -7,11 +7,9 @@ import (
 	"log"
 	"time"
 
+	"github.com/DanTulovsky/alphaville/observer"
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/imdraw"
-	"github.com/faiface/pixel/pixelgl"
 	"github.com/google/uuid"
-	"github.com/DanTulovsky/alphaville/observer"
 	"golang.org/x/image/colornames"
 )
 
@@ -39,7 +37,7 @@ type Target interface {
 	SetAvailable(bool)
 	Bounds() pixel.Rect
 	Circle() pixel.Circle
-	Destroy()
+	Destroy(at time.Time)
 	Location() pixel.Vec
 }
 
@@ -132,26 +130,24 @@ func (t *simpleTarget) Register(obs observer.EventObserver) {
 }
 
 // Deregister de-registers an observer for notifying on.
-// TODO: This causes problems if called because it modifies the t.observers list
-// that something is iterating over.
+// The list is copied, not modified, so it is safe to call while notifying.
 func (t *simpleTarget) Deregister(obs observer.EventObserver) {
-	for i := 0; i < len(t.observers); i++ {
-		if obs == t.observers[i] {
-			t.observers = append(t.observers[:i], t.observers[i+1:]...)
+	observers := []observer.EventObserver{}
+	for _, other := range t.observers {
+		if obs != other {
+			observers = append(observers, other)
 		}
 	}
+	t.observers = observers
 }
 
 // Notify notifies all observers on an event.
 func (t *simpleTarget) Notify(event observer.Event) {
-	// t.observers gets modified by objects unregistering on destruction
+	// t.observers gets replaced by objects unregistering on destruction
 	observers := t.observers
-	// log.Printf("observers before: %v", len(observers))
 	for i := 0; i < len(observers); i++ {
-		// log.Printf("processing observer %v", observers[i].Name())
-		t.observers[i].OnNotify(event)
+		observers[i].OnNotify(event)
 	}
-	// log.Printf("observers after: %v", len(observers))
 }
 
 // Location returns the target's location
@@ -163,19 +159,15 @@ func (t *simpleTarget) Name() string {
 	return t.name
 }
 
-// Destroy destroys this target
+// Destroy destroys this target at the given (world clock) time
 // A notification is issued and the world is updated via it
-func (t *simpleTarget) Destroy() {
+func (t *simpleTarget) Destroy(at time.Time) {
 	t.Notify(NewTargetEvent(
-		"target destroyed", time.Now(), observer.EventData{Key: "destroyed", Value: t.id.String()}))
+		"target destroyed", at, observer.EventData{Key: "destroyed", Value: t.id.String()}))
 	// t = nil
 }
 
 // Draw draws the target
-func (t *simpleTarget) Draw(win *pixelgl.Window) {
-	imd := imdraw.New(nil)
-	imd.Color = t.color
-	imd.Push(t.Location())
-	imd.Circle(t.bounds.W()/2, 0)
-	imd.Draw(win)
+func (t *simpleTarget) Draw(r Renderer, alpha float64) {
+	r.Circle(t.Location(), t.bounds.W()/2, t.color, 0)
 }
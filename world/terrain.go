@@ -0,0 +1,96 @@
+package world
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Objects stand on whatever is under them: the ground, the top of a fixture or the top of another
+// object. Turned fixtures support objects by their outline, turned objects by their bounding box: they
+// may turn some more, into what is on them. There is no separate terrain (no heightmap), platforms and
+// uneven ground are built out of fixtures.
+
+// supportGap is how far above a surface an object still rests on it; objects stop contactSkin short
+// of what they run into
+const supportGap = 1e-3
+
+// groundTop returns the height of the ground, the lowest any object can go
+func (w *World) groundTop() float64 {
+	if w.Ground == nil || w.Ground.Phys() == nil {
+		return 0
+	}
+	return w.Ground.Phys().Location().Max.Y
+}
+
+// SupportUnder returns the height of the surface r rests on, or falls onto: the highest top of the
+// ground, a fixture or an object (other than o) under r, at most supportGap above the bottom of r.
+func (w *World) SupportUnder(o Object, r pixel.Rect) float64 {
+	return w.surfaceUnder(o, r, w.groundTop(), r.Min.Y+supportGap)
+}
+
+// restsOn returns true if r is no more than gap above the ground, a fixture or an object (other than o)
+func (w *World) restsOn(o Object, r pixel.Rect, gap float64) bool {
+	return r.Min.Y-w.surfaceUnder(o, r, r.Min.Y-gap, r.Min.Y+supportGap) <= gap
+}
+
+// landingUnder returns the surface r lands on falling by dy (< 0) this tick, see surfaceUnder
+func (w *World) landingUnder(o Object, r pixel.Rect, dy float64) float64 {
+	return w.surfaceUnder(o, r, r.Min.Y+dy, r.Min.Y+supportGap)
+}
+
+// surfaceUnder returns the highest top of a fixture or an object (other than o) under r, between
+// minTop and maxTop. If there is none, it returns the height of the ground, or -Inf if objects fall
+// through it (see World.floor).
+// Only that band of the quadtree is searched, keep it thin.
+func (w *World) surfaceUnder(o Object, r pixel.Rect, minTop, maxTop float64) float64 {
+	top := w.floor()
+	minTop = math.Max(minTop, top)
+	if maxTop <= minTop {
+		return top
+	}
+
+	w.qt.forEachIn(pixel.R(r.Min.X, minTop, r.Max.X, maxTop), func(other Object, b pixel.Rect) {
+		if o != nil && other.ID() == o.ID() {
+			return
+		}
+		if b.Max.X <= r.Min.X || b.Min.X >= r.Max.X {
+			return // just touching a side
+		}
+		if t := topOver(other, b, r.Min.X, r.Max.X); t <= maxTop && t >= minTop {
+			top = math.Max(top, t)
+		} // otherwise above, or not under r after all
+	})
+	return top
+}
+
+// topOver returns the height of the top of other, in the quadtree at b, between x0 and x1: the top of b,
+// unless other is a turned fixture, then the top of its outline there
+func topOver(other Object, b pixel.Rect, x0, x1 float64) float64 {
+	if _, ok := other.(*Fixture); !ok {
+		return b.Max.Y
+	}
+	body := other.Phys().Body()
+	if body.Angle == 0 {
+		return b.Max.Y
+	}
+
+	// the sides of the outline are straight, the highest point over the span is at an end of one
+	top := math.Inf(-1)
+	p := body.Polygon()
+	for i, a := range p {
+		c := p[(i+1)%len(p)]
+		lo, hi := math.Max(math.Min(a.X, c.X), x0), math.Min(math.Max(a.X, c.X), x1)
+		if lo > hi {
+			continue
+		}
+		if a.X == c.X {
+			top = math.Max(top, math.Max(a.Y, c.Y))
+			continue
+		}
+		for _, x := range []float64{lo, hi} {
+			top = math.Max(top, a.Y+(c.Y-a.Y)*(x-a.X)/(c.X-a.X))
+		}
+	}
+	return top
+}
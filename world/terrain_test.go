@@ -0,0 +1,196 @@
+package world
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+// addTestFixture adds a fixture with its bottom left corner at l to the world
+func addTestFixture(t *testing.T, w *World, name string, l pixel.Vec, width, height float64) *Fixture {
+	t.Helper()
+	f := NewFixture(name, colornames.Green, width, height)
+	f.Place(l)
+	if err := w.AddFixture(f); err != nil {
+		t.Fatalf("failed to add fixture: %v", err)
+	}
+	return f
+}
+
+func TestWorld_SupportUnder(t *testing.T) {
+	tests := []struct {
+		name string
+		r    pixel.Rect
+		want float64
+	}{
+		{name: "ground", r: pixel.R(10, 40, 30, 60), want: 40},
+		{name: "in the air", r: pixel.R(10, 300, 30, 320), want: 40},
+		{name: "fixture top", r: pixel.R(110, 100, 130, 120), want: 100},
+		{name: "above a fixture", r: pixel.R(110, 250, 130, 270), want: 100},
+		{name: "half over a fixture", r: pixel.R(190, 100, 210, 120), want: 100},
+		{name: "next to a fixture", r: pixel.R(200, 40, 220, 60), want: 40},
+		{name: "object top", r: pixel.R(305, 70, 325, 90), want: 70},
+		{name: "higher fixture ignored", r: pixel.R(410, 40, 430, 60), want: 40},
+		{name: "highest of two", r: pixel.R(190, 200, 310, 220), want: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			addTestFixture(t, w, "platform", pixel.V(100, 80), 100, 20)
+			addTestFixture(t, w, "shelf", pixel.V(400, 60), 100, 20) // under it is empty
+			placeObject(t, w, NewRectObject("box", colornames.Red, 0, 1, 30, 30, nil), pixel.V(315, 55))
+
+			if got := w.SupportUnder(nil, tt.r); got != tt.want {
+				t.Errorf("expected support at %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWorld_SupportUnderTurned(t *testing.T) {
+	// a ramp going up to the right, its top side from left to right
+	const angle = 0.3
+	c := pixel.V(400, 110)
+	left, right := c.Add(pixel.V(-100, 10).Rotated(angle)), c.Add(pixel.V(100, 10).Rotated(angle))
+	at := func(x float64) float64 { return left.Y + (right.Y-left.Y)*(x-left.X)/(right.X-left.X) }
+
+	tests := []struct {
+		name string
+		r    pixel.Rect
+		want float64
+	}{
+		{name: "low end", r: pixel.R(320, 200, 340, 220), want: at(340)},
+		{name: "high end", r: pixel.R(460, 200, 480, 220), want: at(480)},
+		{name: "over the top corner", r: pixel.R(right.X-10, 200, right.X+10, 220), want: right.Y},
+		{name: "past it", r: pixel.R(600, 200, 620, 220), want: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			f := NewFixture("ramp", colornames.Green, 200, 20)
+			f.Place(pixel.V(300, 100))
+			for _, phys := range []ObjectPhys{f.Phys(), f.NextPhys()} {
+				phys.SetAngle(angle)
+			}
+			if err := w.AddFixture(f); err != nil {
+				t.Fatalf("failed to add fixture: %v", err)
+			}
+
+			if got := w.SupportUnder(nil, tt.r); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("expected support at %v, got %v (the ramp is up to %v)", tt.want, got, f.Phys().Bounds().Max.Y)
+			}
+		})
+	}
+}
+
+func TestBaseObjectPhys_OnGround(t *testing.T) {
+	tests := []struct {
+		name   string
+		center pixel.Vec
+		want   bool
+	}{
+		{name: "ground", center: pixel.V(20, 50), want: true},
+		{name: "fixture", center: pixel.V(150, 110), want: true},
+		{name: "above a fixture", center: pixel.V(150, 200), want: false},
+		{name: "other object", center: pixel.V(315, 80), want: true},
+		{name: "under a fixture", center: pixel.V(450, 50), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			addTestFixture(t, w, "platform", pixel.V(100, 80), 100, 20)
+			addTestFixture(t, w, "shelf", pixel.V(400, 70), 100, 20)
+			placeObject(t, w, NewRectObject("box", colornames.Red, 0, 1, 30, 30, nil), pixel.V(315, 55))
+
+			o := NewRectObject("o", colornames.Red, 0, 1, 20, 20, nil)
+			placeObject(t, w, o, tt.center)
+
+			if got := o.Phys().OnGround(w); got != tt.want {
+				t.Errorf("expected OnGround %v, got %v", tt.want, got)
+			}
+			if got := o.Phys().IsAboveGround(w); got == tt.want {
+				t.Errorf("expected IsAboveGround %v, got %v", !tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWorld_ObjectsLandOnPlatforms(t *testing.T) {
+	tests := []struct {
+		name   string
+		speed  float64
+		center pixel.Vec
+		check  func(r pixel.Rect) bool
+		want   string
+	}{
+		{
+			name:   "lands on a fixture",
+			center: pixel.V(300, 300),
+			check:  func(r pixel.Rect) bool { return r.Min.Y == 200 },
+			want:   "on the platform",
+		},
+		{
+			name:   "lands on an object",
+			center: pixel.V(600, 300),
+			check:  func(r pixel.Rect) bool { return r.Min.Y > 59 && r.Min.Y < 61 },
+			want:   "on the box",
+		},
+		{
+			name:   "walks off a platform",
+			speed:  2,
+			center: pixel.V(380, 300),
+			check:  func(r pixel.Rect) bool { return r.Min.Y == 40 && r.Min.X > 400 },
+			want:   "on the ground past the platform",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			addTestFixture(t, w, "platform", pixel.V(200, 180), 200, 20)
+			box := NewRectObject("box", colornames.Red, 0, 100, 40, 20, nil)
+			placeObject(t, w, box, pixel.V(600, 50))
+			o := NewRectObject("o", colornames.Red, tt.speed, 1, 20, 20, nil)
+			placeObject(t, w, o, tt.center)
+
+			// as if they had spawned, falling and then moving at their speed
+			for _, o := range []Object{box, o} {
+				for _, phys := range []ObjectPhys{o.Phys(), o.NextPhys()} {
+					phys.SetCurrentMass(o.Mass())
+					phys.SetPreviousVel(pixel.V(o.Speed(), 0))
+				}
+			}
+
+			for i := 0; i < 200; i++ {
+				w.Step()
+			}
+			if r := o.Phys().Location(); !tt.check(r) {
+				t.Errorf("expected the object %v, it is at %v", tt.want, r)
+			}
+		})
+	}
+}
+
+func TestWorld_SpawnOnPlatform(t *testing.T) {
+	w := newTestWorld(1)
+	addTestFixture(t, w, "platform", pixel.V(100, 80), 100, 20)
+	if err := w.AddGate(NewGate("gate", pixel.V(150, 105), GateOpen, 0, 10)); err != nil {
+		t.Fatalf("failed to add gate: %v", err)
+	}
+
+	o := NewRectObject("o", colornames.Red, 0, 1, 20, 20, nil)
+	if err := w.AddObject(o); err != nil {
+		t.Fatalf("failed to add object: %v", err)
+	}
+	if _, err := w.SpawnObject(o); err != nil {
+		t.Fatalf("failed to spawn: %v", err)
+	}
+	if got := o.Phys().Location().Min.Y; got != 100 {
+		t.Errorf("expected the object to spawn on the platform at 100, got %v", got)
+	}
+}
@@ -28,6 +28,8 @@ func (w *World) processGateEvent(e *GateEvent) {
 func (w *World) processTargetEvent(e *TargetEvent) {
 	for _, data := range e.Data() {
 		switch data.Key {
+		case "created":
+			w.wakeTargetSeekers()
 		case "destroyed":
 			w.RegisterTargetRemoval(data.Value)
 		}
@@ -46,6 +48,9 @@ func (w *World) OnNotify(e observer.Event) {
 	case *TargetEvent:
 		w.processTargetEvent(event)
 	}
+
+	// the world observes all gates, sinks, targets and objects, pass their events on to the monitors
+	w.notifyMonitors(e)
 }
 
 // Name returns the name of the world
@@ -88,4 +93,18 @@ func (w *World) Notify(event observer.Event) {
 	for i := 0; i < len(w.observers); i++ {
 		w.observers[i].OnNotify(event)
 	}
+	w.notifyMonitors(event)
+}
+
+// Monitor registers obs to receive every event in the world: world events as well as
+// gate, target, object and input events.
+// Monitors should be registered before the world is populated.
+func (w *World) Monitor(obs observer.EventObserver) {
+	w.monitors = append(w.monitors, obs)
+}
+
+func (w *World) notifyMonitors(event observer.Event) {
+	for i := 0; i < len(w.monitors); i++ {
+		w.monitors[i].OnNotify(event)
+	}
 }
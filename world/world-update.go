@@ -0,0 +1,95 @@
+package world
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Objects are updated in parallel, by a pool of workers (see SetWorkers). While updating, an object
+// (its Update and its behavior's Update) may:
+//
+//   - read and write its own NextPhys(), its behavior and the rest of its own state
+//   - read the Phys() of any object, fixture or the ground, and the NextPhys() of fixtures (they are
+//     updated before objects)
+//   - read the world: size, gravity, clock, quadtree, targets and their availability
+//
+// Anything else, e.g. notifying observers, claiming or registering with targets or changing other
+// objects, must be passed to World.Defer(). Deferred functions run after all objects are updated,
+// one object at a time in w.Objects order, so the result does not depend on the number of workers
+// or on how the objects are scheduled.
+
+// SetWorkers sets the number of workers updating objects in parallel, 1 updates them sequentially.
+// The result of a tick is the same for any number of workers.
+func (w *World) SetWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	w.workers = n
+}
+
+// Workers returns the number of workers updating objects in parallel
+func (w *World) Workers() int {
+	return w.workers
+}
+
+// defaultWorkers returns the default number of workers
+func defaultWorkers() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// Defer runs f once all objects are updated this tick, in the order of the objects in the world.
+// It is how an updating object touches state outside of its own (see the contract above).
+// Outside of an update f runs right away.
+func (w *World) Defer(o Object, f func()) {
+	if !w.updating {
+		f()
+		return
+	}
+
+	w.deferMu.Lock()
+	defer w.deferMu.Unlock()
+	w.deferred[o.ID().String()] = append(w.deferred[o.ID().String()], f)
+}
+
+// updateObjects updates objects, in parallel, and then runs what they deferred
+func (w *World) updateObjects(objects []Object) {
+	w.deferred = make(map[string][]func())
+	w.updating = true
+
+	workers := w.workers
+	if workers > len(objects) {
+		workers = len(objects)
+	}
+
+	if workers <= 1 {
+		for _, o := range objects {
+			o.Update(w)
+		}
+	} else {
+		jobs := make(chan Object)
+		var wg sync.WaitGroup
+
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for o := range jobs {
+					o.Update(w)
+				}
+			}()
+		}
+		for _, o := range objects {
+			jobs <- o
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	w.updating = false
+	for _, o := range objects {
+		for _, f := range w.deferred[o.ID().String()] {
+			f()
+		}
+	}
+	w.deferred = nil
+}
@@ -0,0 +1,77 @@
+package world
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/go-test/deep"
+	"golang.org/x/image/colornames"
+)
+
+func TestWorld_ParallelUpdateMatchesSequential(t *testing.T) {
+	type result struct {
+		Locations []pixel.Rect
+		Caught    []int64
+		Targets   int
+	}
+
+	run := func(workers int) result {
+		w := newTestWorld(3)
+		w.SetWorkers(workers)
+
+		for i := 0; i < 8; i++ {
+			o := NewRectObject(fmt.Sprintf("%v", i), colornames.Blue, 2, 1, 20, 25, nil)
+			o.SetBehavior(NewWondererBehavior(o, w))
+			if err := w.AddObject(o); err != nil {
+				t.Fatalf("failed to add object: %v", err)
+			}
+		}
+		for i := 0; i < 6; i++ {
+			o := NewRectObject(fmt.Sprintf("ts-%v", i), colornames.Red, 3, 1, 30, 30, NewTargetSeekerBehavior(&DijkstraPathFinder{}))
+			if err := w.AddObject(o); err != nil {
+				t.Fatalf("failed to add object: %v", err)
+			}
+		}
+		for i, x := range []float64{100, 300, 500, 700} {
+			if err := w.AddGate(NewGate(fmt.Sprintf("%v", i), pixel.V(x, 300), GateOpen, 0, 20)); err != nil {
+				t.Fatalf("failed to add gate: %v", err)
+			}
+		}
+
+		targets := []pixel.Vec{pixel.V(150, 500), pixel.V(650, 450), pixel.V(400, 120), pixel.V(750, 550), pixel.V(50, 80)}
+		for i := 0; i < 400; i++ {
+			// keep a few targets around, same place every time
+			if len(w.Targets()) < 3 {
+				l := targets[i%len(targets)].Add(pixel.V(float64(i%7), 0))
+				if err := w.AddTarget(NewSimpleTarget("t", l, 10, "target")); err != nil {
+					t.Fatalf("failed to add target: %v", err)
+				}
+			}
+			w.Step()
+		}
+
+		r := result{Locations: locations(w), Targets: len(w.Targets())}
+		for _, o := range w.Objects {
+			if b, ok := o.Behavior().(*TargetSeekerBehavior); ok {
+				r.Caught = append(r.Caught, b.TargetsCaught())
+			}
+		}
+		return r
+	}
+
+	sequential := run(1)
+	caught := int64(0)
+	for _, c := range sequential.Caught {
+		caught += c
+	}
+	if caught == 0 {
+		t.Fatalf("no targets caught, test world is not exercising target claiming")
+	}
+
+	for _, workers := range []int{2, 8} {
+		if diff := deep.Equal(run(workers), sequential); len(diff) != 0 {
+			t.Errorf("%v workers differ from the sequential update: %v", workers, diff)
+		}
+	}
+}
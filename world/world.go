@@ -9,13 +9,13 @@ import (
 	"math/rand"
 	"os"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/DanTulovsky/alphaville/observer"
 	"github.com/DanTulovsky/alphaville/utils"
 
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
+	"github.com/google/uuid"
 	"github.com/jroimartin/gocui"
 )
 
@@ -24,30 +24,65 @@ type World struct {
 	name    string
 	X, Y    float64  // size of the world
 	Gates   []*Gate  // entrances into the world
+	Sinks   []*Sink  // exits out of the world
 	Objects []Object // objects in the world
 
 	// qt keeps track of all the collidable objects in the world
 	qt *Tree
 
-	targets        []Target // targets in the world that TargetSeekers hunt
-	removeTargets  []Target // targets to be removed next turn
-	ManualControl  Object   // this object is human controlled
-	Ground         Object   // special, for now
-	fixtures       []Object // walls, floors, rocks, etc...
+	targets        []Target   // targets in the world that TargetSeekers hunt
+	removeTargets  []Target   // targets to be removed next turn
+	ManualControl  Object     // this object is human controlled
+	Ground         Object     // special, for now
+	fixtures       []Object   // walls, floors, rocks, etc...
+	moving         []*Fixture // kinematic fixtures, as of the last update, see fixture.go
+	zones          []*Zone    // force fields: wind, water, gravity wells, see zone.go
+	joints         []*Joint   // links between objects, see joint.go
+	Boundaries     Boundaries // what happens at the edges of the world, see boundary.go
 	gravity        float64
 	Stats          *Stats // world stats, an observer of events happening in the world
 	MaxObjectSpeed float64
 
+	// force based physics, see phys-forces.go
+	Drag            float64 // fraction of its velocity an object loses every tick
+	Friction        float64 // speed an object sliding along the ground or a fixture loses every tick
+	MaxAcceleration float64 // most an object can change its own velocity by in a tick
+	AngularDrag     float64 // fraction of its angular velocity an object loses every tick, see phys-rotation.go
+
 	MinObjectSide float64 // minimum side of any object in the world
 
+	// idle objects go to sleep, see sleep.go
+	SleepAfter int                       // ticks an object has to be idle for to fall asleep, never if 0
+	sleep      map[uuid.UUID]*sleepState // objects idle as of the last tick
+
 	observers []observer.EventObserver
+	monitors  []observer.EventObserver // see every event in the world
+
+	// all randomness in the world comes from here, directly or via streams derived with NewRand()
+	seed int64
+	src  *RandSource
+	rng  *rand.Rand
+
+	// simulation time, advanced once per tick
+	clock Clock
+
+	// objects are updated in parallel, see world-update.go
+	workers  int
+	updating bool
+	fastest  float64 // speed of the fastest collision object, as of the last tick, see CollisionObjectsIn
+	deferMu  sync.Mutex
+	deferred map[string][]func() // by object id
 
 	debug   *DebugConfig
 	console *gocui.Gui
+
+	commandMu sync.Mutex
+	commands  []command // console commands waiting for the main loop, see input.go
 }
 
 // NewWorld returns a new world of size x, y
-func NewWorld(x, y float64, ground Object, gravity float64, maxSpeed float64, debug *DebugConfig, console *gocui.Gui) *World {
+// Two worlds created with the same seed and populated the same way evolve identically.
+func NewWorld(x, y float64, ground Object, gravity float64, maxSpeed float64, seed int64, debug *DebugConfig, console *gocui.Gui) *World {
 
 	w := &World{
 		Objects: []Object{},
@@ -58,12 +93,23 @@ func NewWorld(x, y float64, ground Object, gravity float64, maxSpeed float64, de
 		Ground:  ground,
 		gravity: gravity,
 		// EventNotifier: observer.NewEventNotifier(),
-		ManualControl:  NewNullObject(),
-		MaxObjectSpeed: maxSpeed,
-		MinObjectSide:  20,
-		console:        console,
-		debug:          debug,
-	}
+		ManualControl:   NewNullObject(),
+		MaxObjectSpeed:  maxSpeed,
+		Drag:            DefaultDrag,
+		Friction:        DefaultFriction,
+		MaxAcceleration: DefaultMaxAcceleration,
+		AngularDrag:     DefaultAngularDrag,
+		MinObjectSide:   20,
+		SleepAfter:      DefaultSleepAfter,
+		sleep:           make(map[uuid.UUID]*sleepState),
+		seed:            seed,
+		src:             NewRandSource(seed),
+		clock:           NewSimClock(DefaultTickDuration),
+		workers:         defaultWorkers(),
+		console:         console,
+		debug:           debug,
+	}
+	w.rng = rand.New(w.src)
 	qt, err := NewTree(pixel.R(0, 0, x, y), []Object{}, w.MinObjectSide, pixel.ZV)
 	if err != nil {
 		log.Fatalf("cannot create world: %v", err)
@@ -79,8 +125,9 @@ func NewWorld(x, y float64, ground Object, gravity float64, maxSpeed float64, de
 	w.qt = qt
 	w.Stats = NewStats(output)
 
+	w.Stats.SetClock(w.clock)
 	w.Register(w.Stats)
-	w.Notify(w.NewWorldEvent(fmt.Sprintf("The world is created..."), time.Now()))
+	w.Notify(w.NewWorldEvent(fmt.Sprintf("The world is created..."), w.clock.Now()))
 	return w
 }
 
@@ -115,7 +162,7 @@ func (w *World) ConsoleO() *gocui.View {
 	return v
 }
 
-func (w *World) processConsoleInput(in string, out *gocui.View) {
+func (w *World) processConsoleInput(in string, out io.Writer) {
 	tokens := strings.Split(strings.ToLower(in), " ")
 
 	switch strings.TrimSpace(tokens[0]) {
@@ -158,10 +205,55 @@ func (w *World) HandleConsoleInput(g *gocui.Gui, v *gocui.View) error {
 		log.Print(err)
 	}
 
-	w.processConsoleInput(input, ov)
+	// the command runs on the main loop, its output comes back here
+	w.QueueCommand(input, func(out string) {
+		g.Update(func(*gocui.Gui) error {
+			_, err := fmt.Fprint(ov, out)
+			return err
+		})
+	})
 	return nil
 }
 
+// Seed returns the seed the world was created with
+func (w *World) Seed() int64 {
+	return w.seed
+}
+
+// Rand returns the world's random source
+func (w *World) Rand() *rand.Rand {
+	return w.rng
+}
+
+// NewRand returns a new random source derived from the world's one.
+// Each object (and populate helper) gets its own stream, so the order in which objects
+// consume random numbers does not affect the others.
+func (w *World) NewRand() *rand.Rand {
+	return rand.New(w.NewRandSource())
+}
+
+// NewRandSource returns the source for a new random stream, see NewRand()
+func (w *World) NewRandSource() *RandSource {
+	return NewRandSource(w.rng.Int63())
+}
+
+// Clock returns the world clock
+func (w *World) Clock() Clock {
+	return w.clock
+}
+
+// SetClock replaces the world clock (e.g. with a WallClock), gates are switched over as well
+func (w *World) SetClock(c Clock) {
+	w.clock = c
+	for _, g := range w.Gates {
+		g.SetClock(c)
+	}
+	for _, s := range w.Sinks {
+		s.SetClock(c)
+	}
+	w.Stats.SetClock(c)
+}
+
 // QuadTree returns the world quadtree
 func (w *World) QuadTree() *Tree {
 	return w.qt
@@ -183,59 +275,109 @@ func (w *World) SpawnAllNew() {
 }
 
 // Draw draws the world by calling each object's Draw()
-func (w *World) Draw(win *pixelgl.Window) {
-	w.Ground.Draw(win)
+// alpha is how far (in [0, 1]) the current frame is between this tick and the next one, objects
+// are drawn interpolated between their Phys() and NextPhys() locations.
+func (w *World) Draw(r Renderer, alpha float64) {
+	w.Ground.Draw(r, alpha)
 
 	for _, g := range w.Gates {
-		g.Draw(win)
+		g.Draw(r, alpha)
+	}
+
+	for _, s := range w.Sinks {
+		s.Draw(r, alpha)
 	}
 
 	for _, f := range w.Fixtures() {
-		f.Draw(win)
+		f.Draw(r, alpha)
+	}
+
+	if w.debug.Zones != nil && w.debug.Zones.IsSet() {
+		for _, z := range w.zones {
+			z.Draw(r)
+		}
 	}
 
 	for _, o := range w.Objects {
-		o.Behavior().Draw(win)
-		o.Draw(win)
+		o.Behavior().Draw(r, alpha)
+		o.Draw(r, alpha)
+	}
+
+	if w.debug.Joints != nil && w.debug.Joints.IsSet() {
+		for _, j := range w.joints {
+			j.Draw(r, alpha)
+		}
 	}
 
 	for _, t := range w.Targets() {
-		t.Draw(win)
+		t.Draw(r, alpha)
 	}
 
-	w.qt.Draw(win, w.debug.QT.DrawTree, w.debug.QT.ColorTree, w.debug.QT.DrawText, w.debug.QT.DrawObjects)
+	w.qt.Draw(r, w.debug.QT.DrawTree, w.debug.QT.ColorTree, w.debug.QT.DrawText, w.debug.QT.DrawObjects)
 
 }
 
+// Step advances the world by one tick.
+// A step ends with Update(), so between steps Phys() holds each object's current state and
+// NextPhys() its already computed next state; Draw() interpolates between the two.
+func (w *World) Step() {
+	w.NextTick()
+	w.SpawnAllNew()
+	w.Update()
+}
+
 // Update updates all the objects in the world to their next state
 func (w *World) Update() {
 	w.Cleanup()
 
-	// TODO: Replace with updating an existing tree when possible
-	var err error
-	cobjects, _ := w.CollisionObjects()
-	w.qt, err = NewTree(pixel.R(0, 0, w.X, w.Y), cobjects, w.MinObjectSide, pixel.ZV)
-	if err != nil {
-		log.Fatalf("error creating world qt: %v", err)
-	}
-
-	// update movable objects
-	for _, o := range w.SpawnedObjects() {
-		o.Update(w)
-	}
-
-	// update fixtures
+	// update fixtures first, kinematic ones carry and push objects
+	w.moving = w.movingFixtures()
 	for _, o := range w.Fixtures() {
 		o.Update(w)
 	}
+	if len(w.sleep) > 0 {
+		for _, f := range w.moving {
+			w.wakeAlong(f.Phys().Bounds(), f.NextPhys().Bounds())
+		}
+	}
+
+	// update movable objects that are awake, in parallel
+	w.updateObjects(w.awakeObjects())
+	w.solveJoints()
 }
 
 // NextTick moves the world to the next state
 func (w *World) NextTick() {
-	// After update, swap the state of all objects at once
+	// After update, swap the state of all objects at once, objects asleep stay as they are
+	var moves [][2]pixel.Rect // where changing objects were and are, they wake up objects asleep next to them
 	for _, o := range w.SpawnedObjects() {
+		if w.Asleep(o) {
+			continue
+		}
+		idle, wakeAt := w.idle(o)
+		if w.SleepAfter > 0 && changed(o) {
+			moves = append(moves, [2]pixel.Rect{o.Phys().Bounds(), o.NextPhys().Bounds()})
+		}
 		o.SwapNextState()
+		if err := w.qt.Move(o); err != nil {
+			log.Fatalf("error moving object in world qt: %v", err)
+		}
+		w.rest(o, idle, wakeAt)
+	}
+	if len(w.sleep) > 0 {
+		for _, m := range moves {
+			w.wakeAlong(m[0], m[1])
+		}
+	}
+	for _, f := range w.moving {
+		f.SwapNextState()
+		if err := w.qt.Move(f); err != nil {
+			log.Fatalf("error moving fixture in world qt: %v", err)
+		}
 	}
+	w.fastest = w.fastestSpeed()
+	w.Stats.Asleep = w.Sleeping()
+	w.clock.Tick()
 }
 
 // Targets returns all the targets in the world
@@ -307,26 +449,33 @@ func (w *World) CollisionObjectsExclude(o Object) ([]Object, error) {
 	return objects, nil
 }
 
-// CollisionObjectsWith returns all objects for which to check collisions for the given object
-func (w *World) CollisionObjectsWith(o Object) ([]Object, error) {
-	// Find the quadrant in w.qt that includes center of o
-	node, err := w.qt.Locate(o.Phys().Location().Center())
-	if err != nil {
-		return nil, err
+// CollisionObjectsIn returns the objects that may run into r this tick: those whose bounds, swept
+// by their velocity, touch r. It asks the quadtree, so it only looks at objects nearby.
+func (w *World) CollisionObjectsIn(r pixel.Rect) []Object {
+	reach := w.fastest
+	near := w.qt.ObjectsIn(pixel.R(r.Min.X-reach, r.Min.Y-reach, r.Max.X+reach, r.Max.Y+reach))
+	objects := near[:0]
+	for _, o := range near {
+		if touches(sweep(o.Phys().Bounds(), o.Phys().Vel()), r) {
+			objects = append(objects, o)
+		}
 	}
-	// walk up the parent objects until node fully encloses o, with no intersections
-	isect := true
+	return objects
+}
 
-	// TODO: But also need to account for objects that might go into the node?
-	for isect {
-		if utils.RectContains(node.Bounds(), o.Phys().Location()) {
-			isect = false
-		} else {
-			node = node.Parent()
-		}
+// fastestSpeed returns the speed of the fastest collision object, none moves further in a tick
+func (w *World) fastestSpeed() float64 {
+	fastest := 0.0
+	cobjects, _ := w.CollisionObjects()
+	for _, o := range cobjects {
+		fastest = math.Max(fastest, o.Phys().Vel().Len())
 	}
+	return fastest
+}
 
-	return node.Objects(), nil
+// sweep returns the rectangle r covers moving by v
+func sweep(r pixel.Rect, v pixel.Vec) pixel.Rect {
+	return r.Union(r.Moved(v))
 }
 
 // checkObjectValid checks if the object is valid to be added to the world
@@ -343,15 +492,115 @@ func (w *World) AddObject(o Object) error {
 	if err := w.checkObjectValid(o); err != nil {
 		return err
 	}
+	if o.Behavior() != nil {
+		o.Behavior().SetRand(w.NewRandSource())
+	}
+	o.Register(w)
 	w.Objects = append(w.Objects, o)
 	return nil
 }
 
+// RemoveObject despawns o, if spawned, and removes it from the world.
+// Gate reservations, target registrations and manual control held by o are released.
+// Objects must not remove anything while updating, use Defer.
+func (w *World) RemoveObject(o Object) error {
+	return w.removeObject(o, "removed")
+}
+
+// removeObject removes o from the world, reason is sent with the events
+func (w *World) removeObject(o Object, reason string) error {
+	i := -1
+	for j, other := range w.Objects {
+		if other.ID() == o.ID() {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return fmt.Errorf("object [%v] is not in the world", o.Name())
+	}
+
+	delete(w.sleep, o.ID())
+	w.removeJoints(o)
+	if o.IsSpawned() {
+		// whatever rested on or leaned against o has to fall
+		w.wakeAlong(o.Phys().Bounds(), o.Phys().Bounds())
+		if err := w.qt.Remove(o); err != nil {
+			log.Fatalf("error removing object from world qt: %v", err)
+		}
+		o.SetPhys(nil)
+		o.SetNextPhys(nil)
+		o.Notify(NewObjectEvent(
+			fmt.Sprintf("object [%v] despawned", o.Name()), w.clock.Now(),
+			observer.EventData{Key: "despawned", Value: o.Name()},
+			observer.EventData{Key: "reason", Value: reason}))
+	}
+
+	for _, g := range w.Gates {
+		g.CancelReservation(o)
+	}
+
+	// target seekers observe the targets they chased
+	if b, ok := o.Behavior().(observer.EventObserver); ok {
+		for _, t := range w.targets {
+			t.Deregister(b)
+		}
+	}
+
+	if w.ManualControl.ID() == o.ID() {
+		w.ManualControl = NewNullObject()
+	}
+
+	w.Objects = append(w.Objects[:i], w.Objects[i+1:]...)
+	o.Deregister(w)
+
+	w.Notify(w.NewWorldEvent(
+		fmt.Sprintf("object [%v] removed", o.Name()), w.clock.Now(),
+		observer.EventData{Key: "removed", Value: o.Name()},
+		observer.EventData{Key: "reason", Value: reason}))
+	return nil
+}
+
+// RemoveExpiredObjects removes all objects that outlived their lifetime
+func (w *World) RemoveExpiredObjects() {
+	for _, o := range w.SpawnedObjects() {
+		if o.Lifetime() > 0 && w.clock.Since(o.SpawnTime()) >= o.Lifetime() {
+			if err := w.removeObject(o, "expired"); err != nil {
+				log.Fatalf("error removing expired object: %v", err)
+			}
+		}
+	}
+}
+
+// ExitObjects removes all objects that reached a sink that takes them
+func (w *World) ExitObjects() {
+	for _, o := range w.SpawnedObjects() {
+		for _, s := range w.Sinks {
+			if s.Reached(o) && s.CanAbsorb(o) {
+				s.Absorb(o, w.clock.Since(o.SpawnTime()))
+				if err := w.removeObject(o, "exited"); err != nil {
+					log.Fatalf("error removing exited object: %v", err)
+				}
+				break
+			}
+		}
+	}
+}
+
 // AddFixture adds a new fixture to the world
 func (w *World) AddFixture(o Object) error {
 	if err := w.checkObjectValid(o); err != nil {
 		return err
 	}
+	if !o.IsSpawned() {
+		return fmt.Errorf("fixture %v must be placed before it is added", o.Name())
+	}
+	if o.Behavior() != nil {
+		o.Behavior().SetRand(w.NewRandSource())
+	}
+	if err := w.qt.Insert(o); err != nil {
+		return err
+	}
 	w.fixtures = append(w.fixtures, o)
 	return nil
 }
@@ -373,7 +622,7 @@ func (w *World) AddTarget(t Target) error {
 	t.Register(w.Stats)
 	t.Register(w)
 
-	t.Notify(NewTargetEvent(fmt.Sprintf("target [%v] created", t), time.Now(),
+	t.Notify(NewTargetEvent(fmt.Sprintf("target [%v] created", t), w.clock.Now(),
 		observer.EventData{Key: "created", Value: t.ID().String()}))
 	return nil
 }
@@ -405,6 +654,9 @@ func (w *World) RemoveOldTargets() {
 // Cleanup runs every tick and does general cleanup
 func (w *World) Cleanup() {
 	w.RemoveOldTargets()
+	w.RemoveExpiredObjects()
+	w.ExitObjects()
+	w.RemoveDepartedObjects()
 }
 
 // RegisterTargetRemoval marks this target for removal next turn
@@ -417,8 +669,8 @@ func (w *World) RegisterTargetRemoval(uuid string) {
 	}
 }
 
-// GetTarget returns an available target
-func (w *World) GetTarget() (Target, error) {
+// GetTarget returns an available target, picked using r
+func (w *World) GetTarget(r *rand.Rand) (Target, error) {
 	if len(w.targets) == 0 {
 		return nil, fmt.Errorf("no available targets")
 	}
@@ -435,7 +687,7 @@ func (w *World) GetTarget() (Target, error) {
 		return nil, fmt.Errorf("no available targets")
 	}
 
-	return targets[utils.RandomInt(0, len(targets))], nil
+	return targets[utils.RandomInt(r, 0, len(targets))], nil
 }
 
 // AddGate adds a new gate to the world
@@ -450,15 +702,58 @@ func (w *World) AddGate(g *Gate) error {
 		}
 	}
 	w.Gates = append(w.Gates, g)
+	g.SetClock(w.clock)
 
 	g.Register(w.Stats)
 	g.Register(w)
 
-	g.Notify(NewGateEvent(fmt.Sprintf("gate [%v] created", g), time.Now(),
+	g.Notify(NewGateEvent(fmt.Sprintf("gate [%v] created", g), w.clock.Now(),
 		observer.EventData{Key: "created", Value: g.Name()}))
 	return nil
 }
 
+// AddSink adds a new sink to the world
+func (w *World) AddSink(s *Sink) error {
+	if s.Location.X > w.X || s.Location.Y > w.Y || s.Location.X < 0 || s.Location.Y < 0 {
+		return fmt.Errorf("Location %#v is outside the world bounds (%#v)", s.Location, pixel.V(w.X, w.Y))
+	}
+
+	for _, other := range w.Sinks {
+		if s.Location == other.Location {
+			return fmt.Errorf("sink at %v already exists", s.Location)
+		}
+	}
+	w.Sinks = append(w.Sinks, s)
+	s.SetClock(w.clock)
+
+	s.Register(w.Stats)
+	s.Register(w)
+
+	s.Notify(NewSinkEvent(fmt.Sprintf("sink [%v] created", s), w.clock.Now(),
+		observer.EventData{Key: "created", Value: s.Name()}))
+	return nil
+}
+
+// GetExit returns the target of the closest sink that accepts o
+func (w *World) GetExit(o Object) (Target, error) {
+	var closest *Sink
+	c := o.Phys().Location().Center()
+
+	for _, s := range w.Sinks {
+		if !s.Accepts(o) {
+			continue
+		}
+		if closest == nil || c.To(s.Location).Len() < c.To(closest.Location).Len() {
+			closest = s
+		}
+	}
+
+	if closest == nil {
+		return nil, fmt.Errorf("no available exits")
+	}
+	return closest.Target(), nil
+}
+
 // SpawnObject tries to grab a gate and spawn, if area around is available
 // We also create the Phys() of the object here, it returns the reserved gate
 func (w *World) SpawnObject(o Object) (*Gate, error) {
@@ -470,9 +765,10 @@ func (w *World) SpawnObject(o Object) (*Gate, error) {
 
 	phys := NewBaseObjectPhys(o.BoundingBox(g.Location), o)
 
-	// If below ground, move up
-	if phys.Location().Min.Y < w.Ground.Phys().Location().Max.Y {
-		phys.SetLocation(phys.Location().Moved(pixel.V(0, w.Ground.Phys().Location().Max.Y-phys.Location().Min.Y)))
+	// If it sinks into the ground, or whatever is under it, move up on top of it
+	r := phys.Location()
+	if top := w.surfaceUnder(o, r, r.Min.Y, r.Center().Y); r.Min.Y < top {
+		phys.SetLocation(r.Moved(pixel.V(0, top-r.Min.Y)))
 	}
 
 	// Spawn happens after everything already moved, so simply check for intersections here
@@ -482,21 +778,25 @@ func (w *World) SpawnObject(o Object) (*Gate, error) {
 		}
 	}
 
-	// Don't set velocity for manual objects
+	// Objects start at rest and speed up, manual objects wait for input
 	switch o.Behavior().(type) {
 	case *ManualBehavior:
 	default:
-		phys.SetVel(pixel.V(o.Speed(), 0))
+		phys.SetDesiredVel(pixel.V(o.Speed(), 0))
 	}
 	phys.SetCurrentMass(o.Mass())
 
 	o.SetPhys(phys)
 	o.SetNextPhys(o.Phys().Copy())
+	o.SetSpawnTime(w.clock.Now())
+	if err := w.qt.Insert(o); err != nil {
+		log.Fatalf("error adding object to world qt: %v", err)
+	}
 
 	g.Release()
 	g.Notify(NewGateEvent(
 		fmt.Sprintf(
-			"object [%v] spawned", o.Name()), time.Now(),
+			"object [%v] spawned", o.Name()), w.clock.Now(),
 		observer.EventData{Key: "spawn", Value: fmt.Sprintf("%T", o)}))
 
 	return g, nil
@@ -512,9 +812,7 @@ func (w *World) ReserveGate(o Object) (*Gate, error) {
 		}
 	}
 
-	r := rand.New(rand.NewSource(time.Now().Unix()))
-
-	for _, i := range r.Perm(len(w.Gates)) {
+	for _, i := range w.rng.Perm(len(w.Gates)) {
 		g := w.Gates[i]
 		if g.Reserve(o) == nil {
 			return g, nil
@@ -541,7 +839,7 @@ func (w *World) CheckIntersect() {
 
 // End destroys the world
 func (w *World) End() {
-	w.Notify(w.NewWorldEvent(fmt.Sprint("The world dies..."), time.Now()))
+	w.Notify(w.NewWorldEvent(fmt.Sprint("The world dies..."), w.clock.Now()))
 	w = nil
 }
 
@@ -552,26 +850,26 @@ func (w *World) ShowStats() {
 
 // ObjectClicked returns the object at coordinates v
 func (w *World) ObjectClicked(v pixel.Vec) (Object, error) {
-	for _, o := range append(w.SpawnedObjects()) {
+	for _, o := range w.SpawnedObjects() {
 		if o.Phys().Location().Contains(v) {
 			return o, nil
 		}
 	}
 
-	for _, o := range append(w.Fixtures()) {
+	for _, o := range w.Fixtures() {
 		if o.Phys().Location().Contains(v) {
 			return o, nil
 		}
 	}
 
-	for _, g := range append(w.Gates) {
+	for _, g := range w.Gates {
 		c := pixel.C(g.Location, g.Radius)
 		if c.Contains(v) {
 			return g, nil
 		}
 	}
 
-	for _, t := range append(w.Targets()) {
+	for _, t := range w.Targets() {
 		if t.Circle().Contains(v) {
 			return t, nil
 		}
@@ -1,9 +1,19 @@
 package world
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/faiface/pixel"
 	"github.com/go-test/deep"
+	"github.com/tevino/abool"
+	"golang.org/x/image/colornames"
+
+	"github.com/DanTulovsky/alphaville/observer"
+	"github.com/DanTulovsky/alphaville/utils"
 )
 
 func TestNewWorld(t *testing.T) {
@@ -29,23 +39,28 @@ func TestNewWorld(t *testing.T) {
 				maxSpeed: 2,
 			},
 			want: &World{
-				X:              100,
-				Y:              200,
-				Objects:        []Object{},
-				Gates:          []*Gate{},
-				Ground:         nil,
-				gravity:        2,
-				MaxObjectSpeed: 2,
-				Stats:          NewStats(nil),
-				ManualControl:  NewNullObject(),
-				console:        nil,
-				MinObjectSide:  20,
+				X:               100,
+				Y:               200,
+				Objects:         []Object{},
+				Gates:           []*Gate{},
+				Ground:          nil,
+				gravity:         2,
+				MaxObjectSpeed:  2,
+				Drag:            DefaultDrag,
+				Friction:        DefaultFriction,
+				MaxAcceleration: DefaultMaxAcceleration,
+				AngularDrag:     DefaultAngularDrag,
+				SleepAfter:      DefaultSleepAfter,
+				Stats:           NewStats(nil),
+				ManualControl:   NewNullObject(),
+				console:         nil,
+				MinObjectSide:   20,
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewWorld(tt.args.x, tt.args.y, tt.args.ground, tt.args.gravity, tt.args.maxSpeed, &DebugConfig{}, nil)
+			got := NewWorld(tt.args.x, tt.args.y, tt.args.ground, tt.args.gravity, tt.args.maxSpeed, 1, &DebugConfig{}, nil)
 			diff := deep.Equal(got, tt.want)
 			if len(diff) != 0 {
 				t.Errorf("NewWorld() = %v, want %v\nDiff: %v", got, tt.want, diff)
@@ -53,3 +68,363 @@ func TestNewWorld(t *testing.T) {
 		})
 	}
 }
+
+func TestWorld_DrawNullRenderer(t *testing.T) {
+	w := NewWorld(768, 1024, NewNullObject(), 2, 2, 1, &DebugConfig{
+		QT: QuadTreeDebug{
+			DrawTree:    abool.NewBool(true),
+			ColorTree:   abool.NewBool(true),
+			DrawText:    abool.NewBool(true),
+			DrawObjects: abool.NewBool(true),
+		},
+		Zones:  abool.NewBool(true),
+		Joints: abool.NewBool(true),
+	}, nil)
+
+	if err := w.AddGate(NewGate("one", pixel.V(100, 100), GateOpen, 0, 10)); err != nil {
+		t.Fatalf("failed to add gate: %v", err)
+	}
+	if err := w.AddZone(NewWellZone("well", pixel.V(300, 300), 50, 1)); err != nil {
+		t.Fatalf("failed to add zone: %v", err)
+	}
+	a := NewRectObject("a", colornames.Red, 0, 1, 20, 20, nil)
+	b := NewRectObject("b", colornames.Blue, 0, 1, 20, 20, nil)
+	placeObject(t, w, a, pixel.V(400, 400))
+	placeObject(t, w, b, pixel.V(500, 400))
+	for _, j := range []*Joint{NewSpringJoint("spring", a, b, 50, 0.1, 0), NewWeldJoint("weld", a, b)} {
+		if err := w.AddJoint(j); err != nil {
+			t.Fatalf("failed to add joint: %v", err)
+		}
+		j.activate()
+	}
+
+	// must not need a window (or fonts) to draw
+	w.Draw(NewNullRenderer(), 0.5)
+}
+
+// newTestWorld returns a world with a ground and no console
+func newTestWorld(seed int64) *World {
+	ground := NewGroundObject("ground", colornames.White, 0, 0, 800, 40)
+	ground.SetPhys(NewBaseObjectPhys(pixel.R(0, 0, 800, 40), ground))
+	ground.SetNextPhys(ground.Phys().Copy())
+
+	return NewWorld(800, 600, ground, -2, 4, seed, &DebugConfig{}, nil)
+}
+
+func TestWorld_SameSeedSameTrajectories(t *testing.T) {
+	run := func(seed int64) []pixel.Rect {
+		w := newTestWorld(seed)
+		r := w.NewRand()
+
+		for i := 0; i < 6; i++ {
+			o := NewRectObject(fmt.Sprintf("%v", i), colornames.Red, utils.RandomFloat64(r, 1, 4), 1, 20, 20, nil)
+			if err := w.AddObject(o); err != nil {
+				t.Fatalf("failed to add object: %v", err)
+			}
+		}
+		for i, x := range []float64{100, 400, 700} {
+			if err := w.AddGate(NewGate(fmt.Sprintf("%v", i), pixel.V(x, 100), GateOpen, 0, 10)); err != nil {
+				t.Fatalf("failed to add gate: %v", err)
+			}
+		}
+
+		for i := 0; i < 300; i++ {
+			w.Update()
+			w.NextTick()
+			w.SpawnAllNew()
+		}
+
+		locations := []pixel.Rect{}
+		for _, o := range w.Objects {
+			if o.IsSpawned() {
+				locations = append(locations, o.Phys().Location())
+			}
+		}
+		return locations
+	}
+
+	first := run(42)
+	if len(first) == 0 {
+		t.Fatalf("no objects spawned")
+	}
+	if diff := deep.Equal(first, run(42)); len(diff) != 0 {
+		t.Errorf("same seed produced different trajectories: %v", diff)
+	}
+}
+
+// eventLog is an observer that keeps all events it sees, and their data
+type eventLog struct {
+	events []observer.Event
+	data   []observer.EventData
+}
+
+func (l *eventLog) OnNotify(e observer.Event) {
+	l.events = append(l.events, e)
+	l.data = append(l.data, e.Data()...)
+}
+
+func (l *eventLog) Name() string {
+	return "event_log"
+}
+
+// has returns true if the log has an event with data key=value
+func (l *eventLog) has(key, value string) bool {
+	for _, d := range l.data {
+		if d.Key == key && d.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWorld_RemoveObject(t *testing.T) {
+	w := newTestWorld(1)
+	events := &eventLog{}
+	w.Monitor(events)
+
+	seeker := NewRectObject("seeker", colornames.Red, 3, 1, 30, 30, NewTargetSeekerBehavior(&DijkstraPathFinder{}))
+	manual := NewRectObject("manual", colornames.Red, 3, 1, 30, 30, NewManualBehavior())
+	waiting := NewRectObject("waiting", colornames.Red, 3, 1, 30, 30, nil)
+	for _, o := range []Object{seeker, manual, waiting} {
+		if err := w.AddObject(o); err != nil {
+			t.Fatalf("failed to add object: %v", err)
+		}
+	}
+	w.ManualControl = manual
+
+	for i, x := range []float64{100, 400} {
+		if err := w.AddGate(NewGate(fmt.Sprintf("%v", i), pixel.V(x, 300), GateOpen, time.Hour, 20)); err != nil {
+			t.Fatalf("failed to add gate: %v", err)
+		}
+	}
+	if err := w.AddTarget(NewSimpleTarget("t", pixel.V(700, 500), 10, "target")); err != nil {
+		t.Fatalf("failed to add target: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		w.Step()
+	}
+	if !seeker.IsSpawned() || !manual.IsSpawned() || waiting.IsSpawned() {
+		t.Fatalf("expected seeker and manual spawned, waiting not; got %v, %v, %v", seeker.IsSpawned(), manual.IsSpawned(), waiting.IsSpawned())
+	}
+	target := w.Targets()[0].(*simpleTarget)
+	if len(target.observers) != 3 {
+		t.Fatalf("expected the seeker to observe the target, observers: %v", target.observers)
+	}
+
+	// waiting holds a gate reservation for its next spawn attempt (gates are cooling down)
+	g := w.Gates[0]
+	g.Reserved = true
+	g.ReservedBy = waiting.ID()
+
+	for _, o := range []Object{seeker, manual, waiting} {
+		if err := w.RemoveObject(o); err != nil {
+			t.Fatalf("failed to remove %v: %v", o.Name(), err)
+		}
+	}
+
+	if len(w.Objects) != 0 {
+		t.Errorf("expected no objects, got %v", w.Objects)
+	}
+	if _, null := w.ManualControl.(*NullObject); !null {
+		t.Errorf("expected manual control to be released, got %v", w.ManualControl.Name())
+	}
+	if g.Reserved || g.ReservedBy == waiting.ID() {
+		t.Errorf("expected gate reservation to be cancelled: %v", g)
+	}
+	if len(target.observers) != 2 {
+		t.Errorf("expected the seeker to stop observing the target, observers: %v", target.observers)
+	}
+	if seeker.IsSpawned() {
+		t.Errorf("expected seeker to be despawned")
+	}
+	if len(w.QuadTree().rects) != 0 {
+		t.Errorf("expected no objects in the quadtree, got %v", len(w.QuadTree().rects))
+	}
+
+	for _, name := range []string{"seeker", "manual"} {
+		if !events.has("despawned", name) {
+			t.Errorf("no despawned event for %v", name)
+		}
+	}
+	if events.has("despawned", "waiting") {
+		t.Errorf("despawned event for an object that never spawned")
+	}
+	for _, name := range []string{"seeker", "manual", "waiting"} {
+		if !events.has("removed", name) {
+			t.Errorf("no removed event for %v", name)
+		}
+	}
+
+	if err := w.RemoveObject(seeker); err == nil {
+		t.Errorf("expected error removing an object twice")
+	}
+
+	// the world carries on without them
+	for i := 0; i < 5; i++ {
+		w.Step()
+	}
+}
+
+func TestWorld_ObjectLifetime(t *testing.T) {
+	w := newTestWorld(1)
+	events := &eventLog{}
+	w.Monitor(events)
+
+	short := NewRectObject("short", colornames.Red, 2, 1, 20, 20, nil)
+	short.SetLifetime(time.Second)
+	forever := NewRectObject("forever", colornames.Red, 2, 1, 20, 20, nil)
+	for _, o := range []Object{short, forever} {
+		if err := w.AddObject(o); err != nil {
+			t.Fatalf("failed to add object: %v", err)
+		}
+	}
+	for i, x := range []float64{100, 400} {
+		if err := w.AddGate(NewGate(fmt.Sprintf("%v", i), pixel.V(x, 300), GateOpen, 0, 20)); err != nil {
+			t.Fatalf("failed to add gate: %v", err)
+		}
+	}
+
+	w.Step()
+	spawned := w.Clock().Now()
+	if short.SpawnTime() != spawned {
+		t.Fatalf("expected spawn time %v, got %v", spawned, short.SpawnTime())
+	}
+
+	for i := 0; len(w.Objects) == 2; i++ {
+		if i > 1000 {
+			t.Fatalf("object never removed")
+		}
+		w.Step()
+	}
+	if lived := w.Clock().Since(spawned); lived < time.Second || lived > time.Second+DefaultTickDuration {
+		t.Errorf("expected object removed once its lifetime is up, after %v", lived)
+	}
+
+	if len(w.Objects) != 1 || w.Objects[0] != forever {
+		t.Errorf("expected only [forever] left, got %v", w.Objects)
+	}
+	if !events.has("reason", "expired") {
+		t.Errorf("expected an expired event")
+	}
+	if w.Stats.ObjectsRemoved != 1 {
+		t.Errorf("expected 1 object removed, got %v", w.Stats.ObjectsRemoved)
+	}
+}
+
+func TestWorld_CollisionObjectsIn(t *testing.T) {
+	w := newTestWorld(1)
+
+	near := NewRectObject("near", colornames.Red, 0, 1, 20, 20, nil)
+	placeObject(t, w, near, pixel.V(320, 300)) // just touching
+	far := NewRectObject("far", colornames.Red, 0, 1, 20, 20, nil)
+	placeObject(t, w, far, pixel.V(600, 300))
+	incoming := NewRectObject("incoming", colornames.Red, 0, 1, 20, 20, nil)
+	placeObject(t, w, incoming, pixel.V(300, 400))
+	incoming.NextPhys().SetVel(pixel.V(0, -85)) // faster than MaxObjectSpeed, found all the same
+	leaving := NewRectObject("moving away", colornames.Red, 0, 1, 20, 20, nil)
+	placeObject(t, w, leaving, pixel.V(250, 250))
+	leaving.NextPhys().SetVel(pixel.V(0, -4))
+	w.NextTick() // the speeds count from the tick they are reached
+
+	wall := NewFixture("wall", colornames.Gray, 20, 100)
+	wall.Place(pixel.V(300, 190))
+	if err := w.AddFixture(wall); err != nil {
+		t.Fatalf("failed to add fixture: %v", err)
+	}
+
+	got := []string{}
+	for _, o := range w.CollisionObjectsIn(pixel.R(290, 290, 310, 310)) {
+		got = append(got, o.Name())
+	}
+	sort.Strings(got)
+	if diff := deep.Equal(got, []string{"incoming", "near", "wall"}); len(diff) != 0 {
+		t.Errorf("expected near, incoming and wall: %v", diff)
+	}
+}
+
+func TestTargetSeekerBehavior_CatchUsesClock(t *testing.T) {
+	w := newTestWorld(1)
+	for i := 0; i < 42; i++ {
+		w.clock.Tick()
+	}
+
+	target := NewSimpleTarget("t", pixel.V(700, 500), 10, "target")
+	if err := w.AddTarget(target); err != nil {
+		t.Fatalf("failed to add target: %v", err)
+	}
+	b := NewTargetSeekerBehavior(&DijkstraPathFinder{})
+	o := NewRectObject("ts", colornames.Red, 2, 1, 20, 20, b)
+	placeObject(t, w, o, pixel.V(100, 300))
+
+	events := &eventLog{}
+	o.Register(events)
+	target.Register(events)
+	b.catchTarget(w, o, target)
+
+	for _, key := range []string{"target_found", "destroyed"} {
+		found := false
+		for _, e := range events.events {
+			for _, d := range e.Data() {
+				if d.Key != key {
+					continue
+				}
+				found = true
+				if !e.Time().Equal(w.Clock().Now()) {
+					t.Errorf("expected %q at %v, world clock time, got %v", e.Description(), w.Clock().Now(), e.Time())
+				}
+			}
+		}
+		if !found {
+			t.Errorf("expected a %v event", key)
+		}
+	}
+}
+
+func TestWorld_QueueCommand(t *testing.T) {
+	w := newTestWorld(1)
+	events := &eventLog{}
+	w.Register(events)
+
+	// the console queues commands on its own goroutine while the world steps
+	replies := make(chan string, 3)
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 3; i++ {
+			w.QueueCommand("help", func(out string) { replies <- out })
+		}
+		close(done)
+	}()
+	for i := 0; i < 5; i++ {
+		w.Step()
+	}
+	<-done
+	if events.has("command", "help") {
+		t.Fatalf("expected no command run before ProcessCommands")
+	}
+
+	w.ProcessCommands()
+	for i := 0; i < 3; i++ {
+		if out := <-replies; !strings.Contains(out, "debug world") {
+			t.Errorf("expected help in the reply, got %q", out)
+		}
+	}
+	n := 0
+	for _, e := range events.events {
+		if _, ok := e.(*InputEvent); !ok {
+			continue
+		}
+		n++
+		if !e.Time().Equal(w.Clock().Now()) {
+			t.Errorf("expected %q at %v, world clock time, got %v", e.Description(), w.Clock().Now(), e.Time())
+		}
+	}
+	if n != 3 {
+		t.Errorf("expected 3 commands run, got %v", n)
+	}
+
+	w.ProcessCommands()
+	if len(replies) != 0 {
+		t.Errorf("expected commands to run once")
+	}
+}
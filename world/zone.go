@@ -0,0 +1,248 @@
+package world
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+// ZoneKind is what a zone does to the objects inside it
+type ZoneKind string
+
+// Kinds of zones
+const (
+	ZoneWind  ZoneKind = "wind"  // pushes objects with a constant force
+	ZoneWater ZoneKind = "water" // objects float (buoyancy) and slow down (drag)
+	ZoneWell  ZoneKind = "well"  // pulls objects towards its center, or pushes them away
+)
+
+// wellSides is the number of sides of the polygon drawn around a well
+const wellSides = 32
+
+// Zone is an area of the world that applies forces to the objects inside it.
+// Wind and water scale their forces by how much of the object's bounding box is inside the zone.
+type Zone struct {
+	Name    string
+	Kind    ZoneKind
+	Polygon []pixel.Vec // corners of the zone, in order (either way around)
+	Color   color.Color
+
+	Force pixel.Vec // wind
+
+	Density float64 // water, buoyancy per square pixel under water and unit of gravity: light objects float, heavy ones sink
+	Drag    float64 // water, fraction of its velocity an object fully in the water loses every tick
+
+	Center   pixel.Vec // well
+	Radius   float64   // well, no force outside of it
+	Strength float64   // well, the pull per unit of mass at the center, negative pushes objects away
+}
+
+// NewWindZone returns a zone pushing objects with force
+func NewWindZone(name string, polygon []pixel.Vec, force pixel.Vec) *Zone {
+	return &Zone{
+		Name:    name,
+		Kind:    ZoneWind,
+		Polygon: polygon,
+		Color:   colornames.Lightskyblue,
+		Force:   force,
+	}
+}
+
+// NewWaterZone returns a zone objects float in (see Zone.Density) and are slowed down by
+func NewWaterZone(name string, polygon []pixel.Vec, density, drag float64) *Zone {
+	return &Zone{
+		Name:    name,
+		Kind:    ZoneWater,
+		Polygon: polygon,
+		Color:   colornames.Royalblue,
+		Density: density,
+		Drag:    drag,
+	}
+}
+
+// NewWellZone returns a zone pulling objects towards center, with a force fading to 0 at radius.
+// A negative strength pushes them away instead.
+func NewWellZone(name string, center pixel.Vec, radius, strength float64) *Zone {
+	z := &Zone{
+		Name:     name,
+		Kind:     ZoneWell,
+		Color:    colornames.Mediumpurple,
+		Center:   center,
+		Radius:   radius,
+		Strength: strength,
+	}
+	for i := 0; i < wellSides; i++ {
+		z.Polygon = append(z.Polygon, center.Add(pixel.V(radius, 0).Rotated(2*math.Pi*float64(i)/wellSides)))
+	}
+	return z
+}
+
+// RectPolygon returns the corners of r, counterclockwise
+func RectPolygon(r pixel.Rect) []pixel.Vec {
+	r = r.Norm()
+	return []pixel.Vec{r.Min, pixel.V(r.Max.X, r.Min.Y), r.Max, pixel.V(r.Min.X, r.Max.Y)}
+}
+
+// Validate returns an error if the zone makes no sense
+func (z *Zone) Validate() error {
+	switch z.Kind {
+	case ZoneWind:
+	case ZoneWater:
+		if z.Density < 0 {
+			return fmt.Errorf("zone [%v]: negative density %v", z.Name, z.Density)
+		}
+		if z.Drag < 0 || z.Drag >= 1 {
+			return fmt.Errorf("zone [%v]: invalid drag %v, want [0, 1)", z.Name, z.Drag)
+		}
+	case ZoneWell:
+		if z.Radius <= 0 {
+			return fmt.Errorf("zone [%v]: invalid radius %v", z.Name, z.Radius)
+		}
+	default:
+		return fmt.Errorf("zone [%v]: unknown kind %q, want wind, water or well", z.Name, z.Kind)
+	}
+
+	if len(z.Polygon) < 3 || polygonArea(z.Polygon) == 0 {
+		return fmt.Errorf("zone [%v]: needs at least 3 corners around a non empty area, got %v", z.Name, z.Polygon)
+	}
+	return nil
+}
+
+// Bounds returns the bounding box of the zone
+func (z *Zone) Bounds() pixel.Rect {
+	r := pixel.Rect{Min: z.Polygon[0], Max: z.Polygon[0]}
+	for _, p := range z.Polygon[1:] {
+		r.Min = pixel.V(math.Min(r.Min.X, p.X), math.Min(r.Min.Y, p.Y))
+		r.Max = pixel.V(math.Max(r.Max.X, p.X), math.Max(r.Max.Y, p.Y))
+	}
+	return r
+}
+
+// Covered returns the area of r inside the zone
+func (z *Zone) Covered(r pixel.Rect) float64 {
+	if z.Bounds().Intersect(r) == pixel.R(0, 0, 0, 0) {
+		return 0
+	}
+	return polygonArea(clipPolygon(z.Polygon, r))
+}
+
+// ForceOn returns the force the zone applies to the object moving as p, in world w
+func (z *Zone) ForceOn(w *World, p ObjectPhys) pixel.Vec {
+	o := p.ParentObject()
+	r := p.Bounds()
+
+	switch z.Kind {
+	case ZoneWind:
+		return z.Force.Scaled(z.Covered(r) / r.Area())
+	case ZoneWater:
+		covered := z.Covered(r)
+		if covered == 0 {
+			return pixel.ZV
+		}
+		// pushed up by the weight of the water the object displaces, and dragged
+		buoyancy := pixel.V(0, -w.gravity*o.GravityScale()*z.Density*covered)
+		drag := p.Vel().Scaled(-z.Drag * o.Mass() * covered / r.Area())
+		return buoyancy.Add(drag)
+	case ZoneWell:
+		to := z.Center.Sub(r.Center())
+		d := to.Len()
+		if d == 0 || d >= z.Radius {
+			return pixel.ZV
+		}
+		return to.Unit().Scaled(z.Strength * o.Mass() * o.GravityScale() * (1 - d/z.Radius))
+	}
+	return pixel.ZV
+}
+
+// Draw draws the outline of the zone
+func (z *Zone) Draw(r Renderer) {
+	r.Polygon(z.Polygon, z.Color, 1)
+	if z.Kind == ZoneWell {
+		r.Circle(z.Center, 2, z.Color, 0)
+	}
+}
+
+// polygonArea returns the area of the polygon (shoelace formula)
+func polygonArea(points []pixel.Vec) float64 {
+	a := 0.0
+	for i, p := range points {
+		a += p.Cross(points[(i+1)%len(points)])
+	}
+	return math.Abs(a) / 2
+}
+
+// clipPolygon returns the part of the polygon inside r (Sutherland–Hodgman).
+// The polygon does not have to be convex, r is.
+func clipPolygon(points []pixel.Vec, r pixel.Rect) []pixel.Vec {
+	for _, edge := range []struct {
+		inside func(pixel.Vec) bool
+		cross  func(a, b pixel.Vec) pixel.Vec
+	}{
+		{func(p pixel.Vec) bool { return p.X >= r.Min.X }, func(a, b pixel.Vec) pixel.Vec { return atX(a, b, r.Min.X) }},
+		{func(p pixel.Vec) bool { return p.X <= r.Max.X }, func(a, b pixel.Vec) pixel.Vec { return atX(a, b, r.Max.X) }},
+		{func(p pixel.Vec) bool { return p.Y >= r.Min.Y }, func(a, b pixel.Vec) pixel.Vec { return atY(a, b, r.Min.Y) }},
+		{func(p pixel.Vec) bool { return p.Y <= r.Max.Y }, func(a, b pixel.Vec) pixel.Vec { return atY(a, b, r.Max.Y) }},
+	} {
+		in := points
+		points = nil
+		for i, b := range in {
+			a := in[(i+len(in)-1)%len(in)]
+			switch {
+			case edge.inside(b):
+				if !edge.inside(a) {
+					points = append(points, edge.cross(a, b))
+				}
+				points = append(points, b)
+			case edge.inside(a):
+				points = append(points, edge.cross(a, b))
+			}
+		}
+		if len(points) == 0 {
+			return nil
+		}
+	}
+	return points
+}
+
+// atX returns where the segment ab crosses the vertical line at x
+func atX(a, b pixel.Vec, x float64) pixel.Vec {
+	return pixel.Lerp(a, b, (x-a.X)/(b.X-a.X))
+}
+
+// atY returns where the segment ab crosses the horizontal line at y
+func atY(a, b pixel.Vec, y float64) pixel.Vec {
+	return pixel.Lerp(a, b, (y-a.Y)/(b.Y-a.Y))
+}
+
+// Zones returns the force field zones in the world
+func (w *World) Zones() []*Zone {
+	return w.zones
+}
+
+// AddZone adds a force field zone to the world, waking up the objects in it
+func (w *World) AddZone(z *Zone) error {
+	if err := z.Validate(); err != nil {
+		return err
+	}
+	for _, other := range w.zones {
+		if z.Name == other.Name {
+			return fmt.Errorf("zone [%v] already exists", z.Name)
+		}
+	}
+	w.zones = append(w.zones, z)
+	w.wakeAround(z.Bounds())
+	return nil
+}
+
+// applyZones applies the forces of all zones to the object moving as p.
+// Safe to call while objects are updating, zones don't change.
+func (w *World) applyZones(p ObjectPhys) {
+	for _, z := range w.zones {
+		if f := z.ForceOn(w, p); f != pixel.ZV {
+			p.ApplyForce(f)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package world
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+func TestZone_Covered(t *testing.T) {
+	// an L: the 20x20 square at the top right is cut out
+	l := []pixel.Vec{pixel.V(0, 0), pixel.V(40, 0), pixel.V(40, 20), pixel.V(20, 20), pixel.V(20, 40), pixel.V(0, 40)}
+
+	tests := []struct {
+		name    string
+		polygon []pixel.Vec
+		r       pixel.Rect
+		want    float64
+	}{
+		{name: "inside", polygon: RectPolygon(pixel.R(0, 0, 100, 100)), r: pixel.R(10, 10, 30, 30), want: 400},
+		{name: "half in", polygon: RectPolygon(pixel.R(0, 0, 100, 100)), r: pixel.R(90, 10, 110, 30), want: 200},
+		{name: "outside", polygon: RectPolygon(pixel.R(0, 0, 100, 100)), r: pixel.R(200, 10, 220, 30), want: 0},
+		{name: "clockwise", polygon: []pixel.Vec{pixel.V(0, 0), pixel.V(0, 100), pixel.V(100, 100), pixel.V(100, 0)}, r: pixel.R(90, 90, 110, 110), want: 100},
+		{name: "triangle", polygon: []pixel.Vec{pixel.V(0, 0), pixel.V(100, 0), pixel.V(0, 100)}, r: pixel.R(40, 40, 60, 60), want: 20*20 - 20*20/2.0},
+		{name: "covers the zone", polygon: []pixel.Vec{pixel.V(0, 0), pixel.V(10, 0), pixel.V(0, 10)}, r: pixel.R(-10, -10, 20, 20), want: 50},
+		{name: "concave", polygon: l, r: pixel.R(10, 10, 30, 30), want: 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := NewWindZone("z", tt.polygon, pixel.ZV)
+			if got := z.Covered(tt.r); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("expected %v covered, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestZone_ForceOn(t *testing.T) {
+	water := RectPolygon(pixel.R(0, 40, 800, 140))
+
+	tests := []struct {
+		name         string
+		zone         *Zone
+		rect         pixel.Rect
+		vel          pixel.Vec
+		mass         float64 // 1 if 0
+		gravityScale float64 // 1 if 0
+		noGravity    bool
+		want         pixel.Vec
+	}{
+		{
+			name: "wind",
+			zone: NewWindZone("wind", RectPolygon(pixel.R(0, 300, 800, 600)), pixel.V(0.5, 0)),
+			rect: pixel.R(100, 400, 120, 420),
+			want: pixel.V(0.5, 0),
+		},
+		{
+			name: "half in the wind",
+			zone: NewWindZone("wind", RectPolygon(pixel.R(0, 300, 800, 600)), pixel.V(0.5, 0)),
+			rect: pixel.R(100, 290, 120, 310),
+			want: pixel.V(0.25, 0),
+		},
+		{
+			name: "out of the wind",
+			zone: NewWindZone("wind", RectPolygon(pixel.R(0, 300, 800, 600)), pixel.V(0.5, 0)),
+			rect: pixel.R(100, 200, 120, 220),
+			want: pixel.ZV,
+		},
+		{
+			name: "under water",
+			zone: NewWaterZone("water", water, 0.01, 0),
+			rect: pixel.R(100, 60, 120, 80),
+			want: pixel.V(0, 0.01*400*2),
+		},
+		{
+			name: "floating",
+			zone: NewWaterZone("water", water, 0.01, 0),
+			rect: pixel.R(100, 130, 120, 150),
+			want: pixel.V(0, 0.01*200*2),
+		},
+		{
+			name: "water drag",
+			zone: NewWaterZone("water", water, 0, 0.5),
+			rect: pixel.R(100, 60, 120, 80),
+			vel:  pixel.V(2, -1),
+			mass: 2,
+			want: pixel.V(-2, 1),
+		},
+		{
+			name:      "no gravity, no buoyancy",
+			zone:      NewWaterZone("water", water, 0.01, 0),
+			rect:      pixel.R(100, 60, 120, 80),
+			noGravity: true,
+			want:      pixel.ZV,
+		},
+		{
+			name: "pulled into a well",
+			zone: NewWellZone("well", pixel.V(400, 300), 100, 2),
+			rect: pixel.R(440, 290, 460, 310),
+			mass: 3,
+			want: pixel.V(-3, 0), // halfway to the edge
+		},
+		{
+			name: "pushed out of a well",
+			zone: NewWellZone("well", pixel.V(400, 300), 100, -2),
+			rect: pixel.R(390, 215, 410, 235),
+			want: pixel.V(0, -0.5),
+		},
+		{
+			name: "outside a well",
+			zone: NewWellZone("well", pixel.V(400, 300), 100, 2),
+			rect: pixel.R(490, 290, 510, 310),
+			want: pixel.ZV,
+		},
+		{
+			name:         "well and gravity scale",
+			zone:         NewWellZone("well", pixel.V(400, 300), 100, 2),
+			rect:         pixel.R(440, 290, 460, 310),
+			gravityScale: 0.5,
+			want:         pixel.V(-0.5, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+
+			mass := tt.mass
+			if mass == 0 {
+				mass = 1
+			}
+			o := NewRectObject("o", colornames.Red, 0, mass, 20, 20, nil)
+			if tt.gravityScale != 0 {
+				o.SetGravityScale(tt.gravityScale)
+			}
+			if tt.noGravity {
+				o.SetGravityScale(0)
+			}
+			phys := NewBaseObjectPhys(tt.rect, o)
+			phys.SetVel(tt.vel)
+
+			if got := tt.zone.ForceOn(w, phys); got.Sub(tt.want).Len() > 1e-9 {
+				t.Errorf("expected force %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWorld_AddZone(t *testing.T) {
+	square := RectPolygon(pixel.R(100, 100, 200, 200))
+
+	tests := []struct {
+		name string
+		zone *Zone
+		err  string
+	}{
+		{name: "wind", zone: NewWindZone("wind", square, pixel.V(1, 0))},
+		{name: "same name", zone: NewWindZone("existing", square, pixel.V(1, 0)), err: "already exists"},
+		{name: "unknown kind", zone: &Zone{Name: "z", Kind: "lava", Polygon: square}, err: "unknown kind"},
+		{name: "too few corners", zone: NewWindZone("wind", square[:2], pixel.V(1, 0)), err: "at least 3 corners"},
+		{name: "no area", zone: NewWindZone("wind", []pixel.Vec{pixel.V(0, 0), pixel.V(1, 1), pixel.V(2, 2)}, pixel.V(1, 0)), err: "non empty area"},
+		{name: "water drag", zone: NewWaterZone("water", square, 0.01, 1), err: "invalid drag"},
+		{name: "water density", zone: NewWaterZone("water", square, -1, 0), err: "negative density"},
+		{name: "well radius", zone: NewWellZone("well", pixel.V(100, 100), 0, 1), err: "invalid radius"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			if err := w.AddZone(NewWindZone("existing", square, pixel.ZV)); err != nil {
+				t.Fatalf("failed to add zone: %v", err)
+			}
+
+			err := w.AddZone(tt.zone)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("failed to add zone: %v", err)
+				}
+				if len(w.Zones()) != 2 {
+					t.Errorf("expected 2 zones, got %v", w.Zones())
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestWorld_ZonesMoveObjects(t *testing.T) {
+	tests := []struct {
+		name      string
+		zone      *Zone
+		mass      float64
+		noGravity bool
+		check     func(r pixel.Rect) bool
+		want      string
+	}{
+		{
+			name:  "light objects float",
+			zone:  NewWaterZone("water", RectPolygon(pixel.R(0, 40, 800, 200)), 0.01, 0.1),
+			mass:  1,
+			check: func(r pixel.Rect) bool { return r.Min.Y > 150 && r.Min.Y < 200 },
+			want:  "at the surface",
+		},
+		{
+			name:  "heavy objects sink",
+			zone:  NewWaterZone("water", RectPolygon(pixel.R(0, 40, 800, 200)), 0.01, 0.1),
+			mass:  10,
+			check: func(r pixel.Rect) bool { return r.Min.Y == 40 },
+			want:  "on the ground",
+		},
+		{
+			name:  "wind stronger than the object",
+			zone:  NewWindZone("wind", RectPolygon(pixel.R(0, 40, 800, 600)), pixel.V(2, 0)),
+			mass:  1,
+			check: func(r pixel.Rect) bool { return r.Min.X > 500 },
+			want:  "blown to the right",
+		},
+		{
+			name:  "objects stand up to a breeze",
+			zone:  NewWindZone("wind", RectPolygon(pixel.R(0, 40, 800, 600)), pixel.V(0.5, 0)),
+			mass:  1,
+			check: func(r pixel.Rect) bool { return r.Min.X < 295 },
+			want:  "about where it landed",
+		},
+		{
+			name:      "no gravity",
+			zone:      NewWindZone("calm", RectPolygon(pixel.R(0, 40, 800, 600)), pixel.ZV),
+			mass:      1,
+			noGravity: true,
+			check:     func(r pixel.Rect) bool { return r.Min.Y == 290 },
+			want:      "where it started",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(1)
+			if err := w.AddZone(tt.zone); err != nil {
+				t.Fatalf("failed to add zone: %v", err)
+			}
+
+			o := NewRectObject("o", colornames.Red, 0, tt.mass, 20, 20, nil)
+			if tt.noGravity {
+				o.SetGravityScale(0)
+			}
+			placeObject(t, w, o, pixel.V(300, 300))
+
+			for i := 0; i < 500; i++ {
+				w.Step()
+			}
+			if r := o.Phys().Location(); !tt.check(r) {
+				t.Errorf("expected the object %v, it is at %v", tt.want, r)
+			}
+		})
+	}
+}